@@ -33,6 +33,11 @@ const (
 	KwokLabelValue        = "fake"
 	NodeViewerLabelKey    = "eks-node-viewer/instance-price"
 	KwokPartitionLabelKey = "kwok-partition"
+
+	// Annotations that inject an interruption of the node's instance, e.g. "SpotInterruption". The deadline is an
+	// optional RFC3339 timestamp of when the instance will be interrupted.
+	InterruptionAnnotationKey         = apis.Group + "/interruption"
+	InterruptionDeadlineAnnotationKey = apis.Group + "/interruption-deadline"
 )
 
 func init() {
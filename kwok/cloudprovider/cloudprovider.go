@@ -139,6 +139,36 @@ func (c CloudProvider) List(ctx context.Context) ([]*v1.NodeClaim, error) {
 	return nodeClaims, nil
 }
 
+// Interruptions returns the interruptions that have been injected by annotating KwoK nodes with
+// v1alpha1.InterruptionAnnotationKey and, optionally, v1alpha1.InterruptionDeadlineAnnotationKey.
+func (c CloudProvider) Interruptions(ctx context.Context) ([]cloudprovider.Interruption, error) {
+	nodeList := &corev1.NodeList{}
+	if err := c.kubeClient.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("listing nodes, %w", err)
+	}
+	var interruptions []cloudprovider.Interruption
+	for _, node := range nodeList.Items {
+		reason, ok := node.Annotations[v1alpha1.InterruptionAnnotationKey]
+		if !ok || !strings.HasPrefix(node.Spec.ProviderID, kwokProviderPrefix) {
+			continue
+		}
+		interruption := cloudprovider.Interruption{
+			ProviderID: node.Spec.ProviderID,
+			Reason:     reason,
+			Message:    "Injected interruption",
+		}
+		if deadline, ok := node.Annotations[v1alpha1.InterruptionDeadlineAnnotationKey]; ok {
+			t, err := time.Parse(time.RFC3339, deadline)
+			if err != nil {
+				log.FromContext(ctx).WithValues("Node", node.Name).Error(err, "ignoring invalid interruption deadline")
+			}
+			interruption.Deadline = t
+		}
+		interruptions = append(interruptions, interruption)
+	}
+	return interruptions, nil
+}
+
 // Return the hard-coded instance types.
 func (c CloudProvider) GetInstanceTypes(ctx context.Context, nodePool *v1.NodePool) ([]*cloudprovider.InstanceType, error) {
 	return c.instanceTypes, nil
@@ -68,9 +68,6 @@ var defaultRawInstanceTypes []byte
 
 // ConstructInstanceTypes create many instance types based on the embedded instance type data
 func ConstructInstanceTypes(ctx context.Context) ([]*cloudprovider.InstanceType, error) {
-	var instanceTypes []*cloudprovider.InstanceType
-	var instanceTypeOptions []InstanceTypeOptions
-
 	rawInstanceTypes := defaultRawInstanceTypes
 	if customInstanceTypes := options.FromContext(ctx).InstanceTypesFilePath; customInstanceTypes != "" {
 		customRawInstanceTypes, err := os.ReadFile(customInstanceTypes)
@@ -79,6 +76,13 @@ func ConstructInstanceTypes(ctx context.Context) ([]*cloudprovider.InstanceType,
 		}
 		rawInstanceTypes = customRawInstanceTypes
 	}
+	return ParseInstanceTypes(rawInstanceTypes)
+}
+
+// ParseInstanceTypes creates instance types from instance type data in the same JSON format as the embedded data
+func ParseInstanceTypes(rawInstanceTypes []byte) ([]*cloudprovider.InstanceType, error) {
+	var instanceTypes []*cloudprovider.InstanceType
+	var instanceTypeOptions []InstanceTypeOptions
 
 	if err := json.Unmarshal(rawInstanceTypes, &instanceTypeOptions); err != nil {
 		return nil, fmt.Errorf("could not parse JSON data: %w", err)
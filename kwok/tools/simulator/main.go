@@ -0,0 +1,153 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// simulator prints the NodeClaims that Karpenter would create and the disruption commands that it would issue for a
+// snapshot of a cluster, using the instance types from a KWOK instance types file. For example:
+//
+//	kubectl get nodepools,nodeclaims,nodes,pods,daemonsets,pdb -A -o yaml > snapshot.yaml
+//	go run ./kwok/tools/simulator --snapshot snapshot.yaml --instance-types-file-path instance_types.json
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/samber/lo"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	kwok "sigs.k8s.io/karpenter/kwok/cloudprovider"
+	_ "sigs.k8s.io/karpenter/kwok/options"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/logging"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/simulator"
+	"sigs.k8s.io/karpenter/pkg/utils/env"
+)
+
+type optionsKey struct{}
+
+// Options contains the CLI flags / env vars for the simulator
+type Options struct {
+	SnapshotPaths  string
+	SimulationTime string
+}
+
+func (o *Options) AddFlags(fs *options.FlagSet) {
+	fs.StringVar(&o.SnapshotPaths, "snapshot", env.WithDefaultString("SNAPSHOT", "-"), "Comma separated paths to the YAML or JSON cluster snapshot files, or - to read the snapshot from stdin")
+	fs.StringVar(&o.SimulationTime, "simulation-time", env.WithDefaultString("SIMULATION_TIME", ""), "The RFC3339 time that the snapshot was taken, defaults to the current time")
+}
+
+func (o *Options) Parse(fs *options.FlagSet, args ...string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		return fmt.Errorf("parsing flags, %w", err)
+	}
+	if o.SimulationTime != "" {
+		if _, err := time.Parse(time.RFC3339, o.SimulationTime); err != nil {
+			return fmt.Errorf("validating cli flags / env vars, invalid SIMULATION_TIME %q", o.SimulationTime)
+		}
+	}
+	return nil
+}
+
+func (o *Options) ToContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, optionsKey{}, o)
+}
+
+func FromContext(ctx context.Context) *Options {
+	return ctx.Value(optionsKey{}).(*Options)
+}
+
+func main() {
+	// Log to stderr by default so that logs don't interleave with the results on stdout
+	if _, ok := os.LookupEnv("LOG_OUTPUT_PATHS"); !ok {
+		lo.Must0(os.Setenv("LOG_OUTPUT_PATHS", "stderr"))
+	}
+	ctx := injection.WithOptionsOrDie(context.Background(), append(options.Injectables, &Options{})...)
+	logger := zapr.NewLogger(logging.NewLogger(ctx, "simulator"))
+	log.SetLogger(logger)
+	ctx = log.IntoContext(ctx, logger)
+
+	if err := run(ctx, os.Stdout); err != nil {
+		logger.Error(err, "failed running simulation")
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, w io.Writer) error {
+	simulationTime := time.Now()
+	if t := FromContext(ctx).SimulationTime; t != "" {
+		simulationTime = lo.Must(time.Parse(time.RFC3339, t))
+	}
+	objects, err := readSnapshots(ctx, strings.Split(FromContext(ctx).SnapshotPaths, ","))
+	if err != nil {
+		return err
+	}
+	instanceTypes, err := kwok.ConstructInstanceTypes(ctx)
+	if err != nil {
+		return fmt.Errorf("constructing instance types, %w", err)
+	}
+	sim, err := simulator.New(ctx, clock.NewFakeClock(simulationTime), func(kubeClient client.Client) cloudprovider.CloudProvider {
+		return kwok.NewCloudProvider(ctx, kubeClient, instanceTypes)
+	}, objects...)
+	if err != nil {
+		return fmt.Errorf("creating simulator, %w", err)
+	}
+	result, err := sim.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("running simulation, %w", err)
+	}
+	out, err := yaml.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshaling result, %w", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func readSnapshots(ctx context.Context, paths []string) ([]client.Object, error) {
+	var objects []client.Object
+	for _, path := range paths {
+		r := io.Reader(os.Stdin)
+		if path != "-" {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("reading snapshot, %w", err)
+			}
+			r = bytes.NewReader(raw)
+		}
+		objs, err := simulator.Decode(ctx, r)
+		if err != nil {
+			return nil, fmt.Errorf("reading snapshot %q, %w", path, err)
+		}
+		objects = append(objects, objs...)
+	}
+	return objects, nil
+}
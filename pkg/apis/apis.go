@@ -36,12 +36,18 @@ var (
 	NodeClaimCRD []byte
 	//go:embed crds/karpenter.sh_nodeoverlays.yaml
 	NodeOverlayCRD []byte
+	//go:embed crds/karpenter.sh_disruptionplans.yaml
+	DisruptionPlanCRD []byte
+	//go:embed crds/karpenter.sh_resourcepredictions.yaml
+	ResourcePredictionCRD []byte
 	//go:embed crds/autoscaling.x-k8s.io_capacitybuffers.yaml
 	CapacityBufferCRD []byte
 	CRDs              = []*apiextensionsv1.CustomResourceDefinition{
 		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](NodePoolCRD),
 		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](NodeClaimCRD),
 		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](NodeOverlayCRD),
+		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](DisruptionPlanCRD),
+		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](ResourcePredictionCRD),
 		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](CapacityBufferCRD),
 	}
 )
@@ -43,6 +43,11 @@ const (
 	NodeRegisteredLabelKey      = apis.Group + "/registered"
 	NodeDoNotSyncTaintsLabelKey = apis.Group + "/do-not-sync-taints"
 	CapacityTypeLabelKey        = apis.Group + "/capacity-type"
+	// ResourcePredictionLabelKey marks ConfigMaps that hold resource predictions for a workload.
+	ResourcePredictionLabelKey = apis.Group + "/resource-prediction"
+	// PodGroupLabelKey assigns a pod to a group of pods in its namespace that must schedule together, e.g. the workers
+	// of a distributed training job. Capacity is only launched for a group when every pending member can schedule.
+	PodGroupLabelKey = apis.Group + "/pod-group"
 )
 
 // Karpenter specific annotations
@@ -53,10 +58,36 @@ const (
 	NodePoolHashVersionAnnotationKey           = apis.Group + "/nodepool-hash-version"
 	NodeClaimTerminationTimestampAnnotationKey = apis.Group + "/nodeclaim-termination-timestamp"
 	NodeClaimMinValuesRelaxedAnnotationKey     = apis.Group + "/nodeclaim-min-values-relaxed"
+	// DisruptionDryRunAnnotationKey puts a NodePool in disruption dry-run mode when set to "true". Disruption
+	// decisions for its nodes are reported through events and logs but never executed.
+	DisruptionDryRunAnnotationKey = apis.Group + "/disruption-dry-run"
+	// DisruptionCostAnnotationKey sets the cost of evicting a pod for disruption decisions, relative to a default pod
+	// with a cost of 1, e.g. "5" for a pod that's expensive to restart. When set on a namespace, it's the default cost
+	// of the pods in the namespace. It overrides the cost that's derived from the pod's priority and deletion cost.
+	DisruptionCostAnnotationKey = apis.Group + "/disruption-cost"
 	// DRADriversAnnotationKey records the comma-separated set of DRA driver names whose devices were allocated to pods
 	// scheduled to this NodeClaim. The initialization controller can gate on these drivers having published their
 	// ResourceSlices before marking the node initialized.
 	DRADriversAnnotationKey = apis.Group + "/requested-dra-drivers"
+	// PodGroupSizeAnnotationKey is the number of members in a pod group. Capacity isn't launched for the group until
+	// that many members are pending or bound to nodes. Groups identified by the coscheduling plugin's label use their
+	// PodGroup's minMember when the annotation isn't set.
+	PodGroupSizeAnnotationKey = apis.Group + "/pod-group-size"
+	// DrainStageAnnotationKey and DrainStageStartTimestampAnnotationKey record the drain stage that a terminating node
+	// is waiting on and when it started waiting, so that the stage's wait survives controller restarts.
+	DrainStageAnnotationKey               = apis.Group + "/drain-stage"
+	DrainStageStartTimestampAnnotationKey = apis.Group + "/drain-stage-start-timestamp"
+	// SurgeDrainAnnotationKey opts a pod into surge drain when set to "true". Before the pod is disrupted, its owning
+	// Deployment is scaled up and the original pod is only removed once the replacement pod is Ready.
+	SurgeDrainAnnotationKey = apis.Group + "/surge-drain"
+	// SurgeDrainStateAnnotationKey records the replicas that a surged Deployment had before it was scaled up, along with
+	// the surged pods. It's removed once the Deployment is scaled back down, and is used to revert the surge if the
+	// disruption command that started it is lost, e.g. when Karpenter restarts.
+	SurgeDrainStateAnnotationKey = apis.Group + "/surge-drain-state"
+	// InterruptionReplacementsAnnotationKey records the comma-separated names of the NodeClaims that were launched to
+	// replace an interrupted NodeClaim. Replacements are only launched once, and the interrupted NodeClaim is deleted
+	// once they're initialized or the interruption's deadline is reached.
+	InterruptionReplacementsAnnotationKey = apis.Group + "/interruption-replacements"
 )
 
 // Karpenter specific finalizers
@@ -35,6 +35,12 @@ const (
 	ConditionTypeDisruptionReason     = "DisruptionReason"
 )
 
+// TerminationHookConditionType returns the type of the NodeClaim condition that surfaces the status of the
+// termination hook with the given name
+func TerminationHookConditionType(hookName string) string {
+	return "TerminationHook" + hookName
+}
+
 // NodeClaimStatus defines the observed state of NodeClaim
 type NodeClaimStatus struct {
 	//nolint:kubeapilinter
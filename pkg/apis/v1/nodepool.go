@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"time"
 
 	"github.com/awslabs/operatorpkg/serrors"
 	"github.com/mitchellh/hashstructure/v2"
@@ -27,6 +28,7 @@ import (
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/clock"
@@ -78,8 +80,73 @@ type NodePoolSpec struct {
 	// +kubebuilder:validation:Minimum:=0
 	// +optional
 	Replicas *int64 `json:"replicas,omitempty"`
+	//nolint:kubeapilinter
+	// InstanceSelection ranks the instance types that are compatible with a NodeClaim by a weighted combination of
+	// objectives. The ranking decides which instance types are kept when a NodeClaim's instance types are truncated
+	// and which instance types a NodeClaim is launched with.
+	// If omitted, instance types are ranked by price.
+	// +optional
+	InstanceSelection *InstanceSelection `json:"instanceSelection,omitempty"`
+}
+
+// InstanceSelection ranks instance types by the weighted sum of their objective scores. Each objective scores the
+// compatible instance types from 0 (best) to 1 (worst) relative to each other, and instance types with equal
+// scores are ranked by price.
+type InstanceSelection struct {
+	//nolint:kubeapilinter
+	// Objectives is the list of objectives that instance types are ranked by. Each type of objective can only be
+	// listed once.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=4
+	// +listType=map
+	// +listMapKey=type
+	// +required
+	Objectives []InstanceSelectionObjective `json:"objectives"`
+}
+
+// +kubebuilder:validation:XValidation:message="'key' and 'values' must be set if and only if 'type' is 'FamilyPreference'",rule="(self.type == 'FamilyPreference') == (has(self.key) && has(self.values))"
+type InstanceSelectionObjective struct {
+	//nolint:kubeapilinter
+	// Type is the objective that instance types are scored by:
+	//   - Price prefers instance types with cheaper offerings
+	//   - NodeCount prefers larger instance types, so that fewer nodes are launched
+	//   - FamilyPreference prefers instance types by the order of their value for key in values
+	//   - Interruption prefers instance types whose offerings are less likely to be interrupted
+	// +kubebuilder:validation:Enum:=Price;NodeCount;FamilyPreference;Interruption
+	// +required
+	Type InstanceSelectionObjectiveType `json:"type"`
+	//nolint:kubeapilinter
+	// Weight is the weight of the objective relative to the other objectives.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=100
+	// +required
+	Weight int32 `json:"weight"`
+	//nolint:kubeapilinter
+	// Key is the instance type label that the FamilyPreference objective compares, e.g. an instance family or
+	// generation label.
+	// +kubebuilder:validation:MaxLength=316
+	// +optional
+	Key *string `json:"key,omitempty"`
+	//nolint:kubeapilinter
+	// Values are the values of key in order of preference, most preferred first. Instance types without a listed value
+	// are least preferred.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=50
+	// +listType=atomic
+	// +optional
+	Values []string `json:"values,omitempty"`
 }
 
+type InstanceSelectionObjectiveType string
+
+const (
+	InstanceSelectionObjectivePrice            InstanceSelectionObjectiveType = "Price"
+	InstanceSelectionObjectiveNodeCount        InstanceSelectionObjectiveType = "NodeCount"
+	InstanceSelectionObjectiveFamilyPreference InstanceSelectionObjectiveType = "FamilyPreference"
+	InstanceSelectionObjectiveInterruption     InstanceSelectionObjectiveType = "Interruption"
+)
+
+// +kubebuilder:validation:XValidation:message="'balancedK' can only be set when 'consolidationPolicy' is 'Balanced'",rule="!has(self.balancedK) || self.consolidationPolicy == 'Balanced'"
 type Disruption struct {
 	//nolint:kubeapilinter
 	// ConsolidateAfter is the duration the controller will wait
@@ -101,6 +168,14 @@ type Disruption struct {
 	// +kubebuilder:validation:Enum:=WhenEmpty;WhenEmptyOrUnderutilized;Balanced
 	// +optional
 	ConsolidationPolicy ConsolidationPolicy `json:"consolidationPolicy,omitempty"`
+	// BalancedK tunes how much disruption the Balanced consolidation policy accepts per unit of savings.
+	// A move is approved when its score is at least 1/balancedK, so lower values are more conservative
+	// and higher values are more aggressive. Defaults to 2 if not specified.
+	// This field can only be set when consolidationPolicy is "Balanced".
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=3
+	// +optional
+	BalancedK *int32 `json:"balancedK,omitempty"`
 	//nolint:kubeapilinter
 	// Budgets is a list of Budgets.
 	// If there are multiple active budgets, Karpenter uses
@@ -113,6 +188,131 @@ type Disruption struct {
 	// +listType=atomic
 	//nolint:kubeapilinter
 	Budgets []Budget `json:"budgets,omitempty" hash:"ignore"`
+	//nolint:kubeapilinter
+	// DrainStages customizes the order in which pods are evicted when this NodePool's nodes are drained.
+	// Each pod belongs to the first stage that selects it and stages are drained in order, so a stage's pods
+	// are only evicted once the pods of all previous stages are gone. Pods that aren't selected by any stage
+	// are drained in the default order, with non-critical pods drained before the first stage and critical pods
+	// drained after the last stage.
+	// If omitted, pods are drained in the default order: non-critical pods before critical pods, and
+	// non-daemon pods before daemon pods.
+	// +kubebuilder:validation:MaxItems=10
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	DrainStages []DrainStage `json:"drainStages,omitempty" hash:"ignore"`
+	//nolint:kubeapilinter
+	// ForcefulBudgetMaxWait is the maximum amount of time that an expired or unhealthy node waits for an
+	// Expired or Repaired budget before it's disrupted anyway. If left undefined, nodes wait until the
+	// budget allows them to be disrupted.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	ForcefulBudgetMaxWait *metav1.Duration `json:"forcefulBudgetMaxWait,omitempty" hash:"ignore"`
+	//nolint:kubeapilinter
+	// MaintenanceWindows restricts voluntary disruption of this NodePool's nodes to the given windows.
+	// Consolidation, drift and expiration only start disrupting nodes while a window is open, and only
+	// when the window stays open long enough for the disruption to finish.
+	// If omitted, nodes can be disrupted at any time.
+	// +optional
+	MaintenanceWindows *MaintenanceWindows `json:"maintenanceWindows,omitempty" hash:"ignore"`
+	//nolint:kubeapilinter
+	// SurgeDrainPolicy describes which workloads are scaled up before their pods are disrupted, so that a replacement
+	// pod is Ready before the original pod is removed from a node that's being consolidated or drifted.
+	// Only pods owned by Deployments are surged. Pods can also opt in with the karpenter.sh/surge-drain annotation.
+	// Valid values: "Never", "WhenSingleReplica". Defaults to "Never" if not specified.
+	// +kubebuilder:validation:Enum:=Never;WhenSingleReplica
+	// +optional
+	SurgeDrainPolicy SurgeDrainPolicy `json:"surgeDrainPolicy,omitempty" hash:"ignore"`
+}
+
+type SurgeDrainPolicy string
+
+const (
+	SurgeDrainPolicyNever             SurgeDrainPolicy = "Never"
+	SurgeDrainPolicyWhenSingleReplica SurgeDrainPolicy = "WhenSingleReplica"
+)
+
+// MaintenanceWindows defines when Karpenter is allowed to voluntarily disrupt nodes.
+type MaintenanceWindows struct {
+	//nolint:kubeapilinter
+	// TimeZone is the IANA time zone that the windows and exclusions are evaluated in, e.g. "Europe/Berlin".
+	// If omitted, UTC is used.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty" hash:"ignore"`
+	//nolint:kubeapilinter
+	// Windows is the list of windows during which nodes can be disrupted. Windows that overlap or directly follow
+	// each other are merged.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=50
+	// +listType=atomic
+	Windows []MaintenanceWindow `json:"windows" hash:"ignore"`
+	//nolint:kubeapilinter
+	// Exclusions is a list of dates, formatted as YYYY-MM-DD, on which no window is open, e.g. holiday freezes.
+	// Windows that are open when an excluded date starts close at midnight in the configured time zone.
+	// +kubebuilder:validation:MaxItems=100
+	// +kubebuilder:validation:items:Pattern=`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`
+	// +listType=set
+	// +optional
+	Exclusions []string `json:"exclusions,omitempty" hash:"ignore"`
+}
+
+// MaintenanceWindow is a recurring window during which nodes can be disrupted.
+type MaintenanceWindow struct {
+	//nolint:kubeapilinter
+	// Schedule specifies when the window opens, following the upstream cronjob syntax.
+	// The schedule is evaluated in the time zone of the maintenance windows.
+	// +kubebuilder:validation:Pattern:=`^(@(annually|yearly|monthly|weekly|daily|midnight|hourly))|((.+)\s(.+)\s(.+)\s(.+)\s(.+))$`
+	// +required
+	Schedule string `json:"schedule" hash:"ignore"`
+	//nolint:kubeapilinter
+	// Duration determines how long the window is open since each Schedule hit.
+	// Only minutes and hours are accepted, as cron does not work in seconds.
+	// +kubebuilder:validation:Pattern=`^((([0-9]+(h|m))|([0-9]+h[0-9]+m))(0s)?)$`
+	// +kubebuilder:validation:Type="string"
+	// +required
+	Duration metav1.Duration `json:"duration" hash:"ignore"`
+}
+
+// DrainStage selects a group of pods that are evicted together while a node is drained.
+// +kubebuilder:validation:XValidation:message="'podSelector' or 'priority' must be set",rule="has(self.podSelector) || has(self.priority)"
+type DrainStage struct {
+	// Name identifies the stage in events and logs.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	// +required
+	Name string `json:"name" hash:"ignore"`
+	// PodSelector selects the pods with matching labels.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty" hash:"ignore"`
+	// Priority selects the pods whose priority is within the range.
+	// If both podSelector and priority are set, pods must match both.
+	// +optional
+	Priority *PriorityRange `json:"priority,omitempty" hash:"ignore"`
+	//nolint:kubeapilinter
+	// Wait is how long to wait after the pods of all previous stages are gone before evicting this stage's pods.
+	// Stages without pods are skipped without waiting.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	Wait *metav1.Duration `json:"wait,omitempty" hash:"ignore"`
+}
+
+// PriorityRange is an inclusive range of pod priority values.
+// +kubebuilder:validation:XValidation:message="'min' or 'max' must be set",rule="has(self.min) || has(self.max)"
+// +kubebuilder:validation:XValidation:message="'min' must be less than or equal to 'max'",rule="!has(self.min) || !has(self.max) || self.min <= self.max"
+type PriorityRange struct {
+	// Min is the lowest priority in the range. If omitted, the range has no lower bound.
+	// +optional
+	Min *int32 `json:"min,omitempty" hash:"ignore"`
+	// Max is the highest priority in the range. If omitted, the range has no upper bound.
+	// +optional
+	Max *int32 `json:"max,omitempty" hash:"ignore"`
+}
+
+// Matches returns true if the priority is within the range.
+func (r *PriorityRange) Matches(priority int32) bool {
+	return (r.Min == nil || priority >= *r.Min) && (r.Max == nil || priority <= *r.Max)
 }
 
 // Budget defines when Karpenter will restrict the
@@ -120,7 +320,10 @@ type Disruption struct {
 type Budget struct {
 	// reasons is a list of disruption methods that this budget applies to. If Reasons is not set, this budget applies to all methods.
 	// Otherwise, this will apply to each reason defined.
-	// allowed reasons are Underutilized, Empty, and Drifted.
+	// allowed reasons are Underutilized, Empty, Drifted, Expired, and Repaired.
+	// Expired and Repaired are forceful disruptions that are only limited by budgets that explicitly list them.
+	// Budgets for forceful disruptions only limit nodes, and the NodePool's forcefulBudgetMaxWait determines
+	// whether nodes wait indefinitely when the budget is exhausted.
 	// +kubebuilder:validation:MaxItems=50
 	// +optional
 	// +listType=set
@@ -137,6 +340,19 @@ type Budget struct {
 	// +kubebuilder:default:="10%"
 	Nodes string `json:"nodes" hash:"ignore"`
 	//nolint:kubeapilinter
+	// Pods dictates the maximum number of pods that can be evicted at once from the NodeClaims owned by this
+	// NodePool that are being disrupted. DaemonSet pods aren't counted. If omitted, the number of pods is unbounded.
+	// Nodes still applies when pods is set, so nodes can be set to "100%" to only limit disruption by pods.
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	Pods *int32 `json:"pods,omitempty" hash:"ignore"`
+	//nolint:kubeapilinter
+	// Resources dictates the maximum capacity of the NodeClaims owned by this NodePool that can be
+	// terminating at once, e.g. {cpu: 64} allows at most 64 vCPU of nodes to be drained simultaneously.
+	// Resources that aren't specified are unbounded. Nodes still applies when resources is set.
+	// +optional
+	Resources v1.ResourceList `json:"resources,omitempty" hash:"ignore"`
+	//nolint:kubeapilinter
 	// Schedule specifies when a budget begins being active, following
 	// the upstream cronjob syntax. If omitted, the budget is always active.
 	// Timezones are not supported.
@@ -165,27 +381,44 @@ const (
 	ConsolidationPolicyBalanced                 ConsolidationPolicy = "Balanced"
 )
 
-// BalancedK is the scoring parameter for the Balanced policy. A move is
+// BalancedK is the default scoring parameter for the Balanced policy. A move is
 // approved when score >= 1/k = 0.5. k=2 is the smallest value where
 // within-family replaces pass, with 4-step max churn. See
 // designs/balanced-consolidation.md "Why k=2".
 const BalancedK int32 = 2
 
+// GetBalancedK returns the scoring parameter applied to moves under the Balanced policy,
+// falling back to BalancedK when the NodePool doesn't override it.
+func (d Disruption) GetBalancedK() int32 {
+	return lo.FromPtrOr(d.BalancedK, BalancedK)
+}
+
 // IsBalanced returns true for the Balanced consolidation policy.
 func (p ConsolidationPolicy) IsBalanced() bool {
 	return p == ConsolidationPolicyBalanced
 }
 
 // DisruptionReason defines valid reasons for disruption budgets.
-// +kubebuilder:validation:Enum={Underutilized,Empty,Drifted}
+// +kubebuilder:validation:Enum={Underutilized,Empty,Drifted,Expired,Repaired}
 type DisruptionReason string
 
 const (
 	DisruptionReasonUnderutilized DisruptionReason = "Underutilized"
 	DisruptionReasonEmpty         DisruptionReason = "Empty"
 	DisruptionReasonDrifted       DisruptionReason = "Drifted"
+	DisruptionReasonExpired       DisruptionReason = "Expired"
+	DisruptionReasonRepaired      DisruptionReason = "Repaired"
+	// DisruptionReasonInterrupted is the reason for NodeClaims that are disrupted due to a cloud provider interruption
+	// notice. Interruptions are involuntary, so they aren't subject to disruption budgets.
+	DisruptionReasonInterrupted DisruptionReason = "Interrupted"
 )
 
+// IsForceful returns true for reasons that disrupt nodes regardless of pod disruption budgets and do-not-disrupt
+// annotations. Budgets only apply to forceful reasons when they list them explicitly.
+func (r DisruptionReason) IsForceful() bool {
+	return r == DisruptionReasonExpired || r == DisruptionReasonRepaired
+}
+
 type Limits v1.ResourceList
 
 func (l Limits) ExceededBy(resources v1.ResourceList) error {
@@ -370,13 +603,90 @@ func (in *NodePool) GetAllowedDisruptionsByReason(c clock.Clock, numNodes int, r
 		if err != nil {
 			multiErr = multierr.Append(multiErr, err)
 		}
-		if budget.Reasons == nil || lo.Contains(budget.Reasons, reason) {
+		if budget.AppliesTo(reason) {
 			allowedNodes = lo.Min([]int{allowedNodes, val})
 		}
 	}
 	return allowedNodes, multiErr
 }
 
+// MustGetAllowedPodDisruptions calls GetAllowedPodDisruptionsByReason and fails closed if the budgets are invalid.
+func (in *NodePool) MustGetAllowedPodDisruptions(c clock.Clock, reason DisruptionReason) int {
+	allowedPods, err := in.GetAllowedPodDisruptionsByReason(c, reason)
+	if err != nil {
+		return 0
+	}
+	return allowedPods
+}
+
+// MustGetAllowedResourceDisruptions calls GetAllowedResourceDisruptionsByReason and fails closed if the budgets
+// are invalid. Resources limited by an invalid budget are returned with a zero quantity.
+func (in *NodePool) MustGetAllowedResourceDisruptions(c clock.Clock, reason DisruptionReason) v1.ResourceList {
+	allowedResources, _ := in.GetAllowedResourceDisruptionsByReason(c, reason)
+	return allowedResources
+}
+
+// GetAllowedPodDisruptionsByReason returns the minimum number of pods that can be evicted at once across all active
+// disruption budgets that apply to the reason. This returns MAXINT if no active budget limits pods.
+func (in *NodePool) GetAllowedPodDisruptionsByReason(c clock.Clock, reason DisruptionReason) (int, error) {
+	allowedPods := math.MaxInt32
+	var multiErr error
+	for _, budget := range in.Spec.Disruption.Budgets {
+		if budget.Pods == nil || !budget.AppliesTo(reason) {
+			continue
+		}
+		active, err := budget.IsActive(c)
+		// If the budget is misconfigured, fail closed.
+		if err != nil {
+			multiErr = multierr.Append(multiErr, err)
+			allowedPods = 0
+			continue
+		}
+		if active {
+			allowedPods = lo.Min([]int{allowedPods, int(lo.FromPtr(budget.Pods))})
+		}
+	}
+	return allowedPods, multiErr
+}
+
+// GetAllowedResourceDisruptionsByReason returns the minimum quantity of each resource that can be disrupted at once
+// across all active disruption budgets that apply to the reason. Resources that no active budget limits are omitted.
+func (in *NodePool) GetAllowedResourceDisruptionsByReason(c clock.Clock, reason DisruptionReason) (v1.ResourceList, error) {
+	allowedResources := v1.ResourceList{}
+	var multiErr error
+	for _, budget := range in.Spec.Disruption.Budgets {
+		if len(budget.Resources) == 0 || !budget.AppliesTo(reason) {
+			continue
+		}
+		active, err := budget.IsActive(c)
+		if err != nil {
+			multiErr = multierr.Append(multiErr, err)
+		}
+		if !active && err == nil {
+			continue
+		}
+		for resourceName, quantity := range budget.Resources {
+			// If the budget is misconfigured, fail closed.
+			if err != nil {
+				quantity = resource.Quantity{}
+			}
+			if current, ok := allowedResources[resourceName]; !ok || quantity.Cmp(current) < 0 {
+				allowedResources[resourceName] = quantity
+			}
+		}
+	}
+	return allowedResources, multiErr
+}
+
+// AppliesTo returns true if the budget limits disruptions for the reason. Budgets without reasons apply to all
+// reasons except forceful ones, which must be listed explicitly.
+func (in *Budget) AppliesTo(reason DisruptionReason) bool {
+	if in.Reasons == nil {
+		return !reason.IsForceful()
+	}
+	return lo.Contains(in.Reasons, reason)
+}
+
 // GetAllowedDisruptions returns an intstr.IntOrString that can be used a comparison
 // for calculating if a disruption action is allowed. It returns an error if the
 // schedule is invalid. This returns MAXINT if the value is unbounded.
@@ -426,6 +736,124 @@ func (in *Budget) IsActive(c clock.Clock) (bool, error) {
 	return !nextHit.After(c.Now().UTC()), nil
 }
 
+// MaintenanceWindowRemaining returns how long the NodePool's maintenance window stays open. If no window is
+// open, 0 is returned. NodePools without maintenance windows can always be disrupted, so math.MaxInt64 is returned.
+func (in *NodePool) MaintenanceWindowRemaining(c clock.Clock) (time.Duration, error) {
+	if in.Spec.Disruption.MaintenanceWindows == nil {
+		return time.Duration(math.MaxInt64), nil
+	}
+	until, open, err := in.Spec.Disruption.MaintenanceWindows.OpenUntil(c.Now())
+	if err != nil || !open {
+		// Fail closed when the windows are invalid, since we don't know when the user wants
+		// their nodes to be disrupted.
+		return 0, err
+	}
+	return until.Sub(c.Now()), nil
+}
+
+// maxMergedWindow bounds how far OpenUntil follows windows that chain into each other, so that schedules which
+// are always open don't have to be walked forever.
+const maxMergedWindow = 7 * 24 * time.Hour
+
+// OpenUntil returns whether a window is open at the given time and, if so, when it closes. Windows that overlap
+// or start as soon as the open window ends are merged, up to a week from the given time.
+func (in *MaintenanceWindows) OpenUntil(now time.Time) (time.Time, bool, error) {
+	loc, schedules, err := in.parse()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	now = now.In(loc)
+	if in.isExcluded(now) {
+		return time.Time{}, false, nil
+	}
+	limit := now.Add(maxMergedWindow)
+	until := now
+	for extended := true; extended && until.Before(limit); {
+		extended = false
+		for i, schedule := range schedules {
+			duration := in.Windows[i].Duration.Duration
+			// Walk back in time for the duration of the window, and find the last schedule hit before the
+			// current closing time
+			hit := schedule.Next(until.Add(-duration))
+			if hit.IsZero() || hit.After(until) {
+				continue
+			}
+			for next := schedule.Next(hit); !next.IsZero() && !next.After(until); next = schedule.Next(next) {
+				hit = next
+			}
+			if end := hit.Add(duration); end.After(until) {
+				until, extended = end, true
+			}
+		}
+	}
+	if until.After(limit) {
+		until = limit
+	}
+	if until.Equal(now) {
+		return time.Time{}, false, nil
+	}
+	// Close the window early if it runs into an excluded date
+	for day := startOfDay(now).AddDate(0, 0, 1); day.Before(until); day = day.AddDate(0, 0, 1) {
+		if in.isExcluded(day) {
+			return day, true, nil
+		}
+	}
+	return until, true, nil
+}
+
+// NextOpen returns the next time after the given time that a window opens on a date that isn't excluded.
+// False is returned if no window opens within a year.
+func (in *MaintenanceWindows) NextOpen(now time.Time) (time.Time, bool, error) {
+	loc, schedules, err := in.parse()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	now = now.In(loc)
+	limit := now.AddDate(1, 0, 0)
+	var next time.Time
+	for _, schedule := range schedules {
+		for hit := schedule.Next(now); !hit.IsZero() && hit.Before(limit); hit = schedule.Next(hit) {
+			if !in.isExcluded(hit) {
+				if next.IsZero() || hit.Before(next) {
+					next = hit
+				}
+				break
+			}
+		}
+	}
+	return next, !next.IsZero(), nil
+}
+
+func (in *MaintenanceWindows) parse() (*time.Location, []cron.Schedule, error) {
+	tz := lo.FromPtrOr(in.TimeZone, "UTC")
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, nil, serrors.Wrap(fmt.Errorf("invalid time zone, %w", err), "time-zone", tz)
+	}
+	schedules := make([]cron.Schedule, 0, len(in.Windows))
+	for _, window := range in.Windows {
+		schedule, err := cron.ParseStandard(fmt.Sprintf("TZ=%s %s", tz, window.Schedule))
+		if err != nil {
+			return nil, nil, serrors.Wrap(fmt.Errorf("invalid cron, %w", err), "cron", window.Schedule)
+		}
+		schedules = append(schedules, schedule)
+	}
+	for _, exclusion := range in.Exclusions {
+		if _, err := time.ParseInLocation(time.DateOnly, exclusion, loc); err != nil {
+			return nil, nil, serrors.Wrap(fmt.Errorf("invalid exclusion, %w", err), "exclusion", exclusion)
+		}
+	}
+	return loc, schedules, nil
+}
+
+func (in *MaintenanceWindows) isExcluded(t time.Time) bool {
+	return lo.Contains(in.Exclusions, t.Format(time.DateOnly))
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
 func GetIntStrFromValue(str string) intstr.IntOrString {
 	// If err is nil, we treat it as an int.
 	if intVal, err := strconv.Atoi(str); err == nil {
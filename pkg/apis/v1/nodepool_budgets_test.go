@@ -23,6 +23,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clock "k8s.io/utils/clock/testing"
 
@@ -175,6 +177,93 @@ var _ = Describe("Budgets", func() {
 
 	})
 
+	Context("Forceful reasons", func() {
+		It("should only apply budgets that explicitly list a forceful reason", func() {
+			for _, reason := range []DisruptionReason{DisruptionReasonExpired, DisruptionReasonRepaired} {
+				allowedDisruption, err := nodePool.GetAllowedDisruptionsByReason(fakeClock, 100, reason)
+				Expect(err).To(BeNil())
+				Expect(allowedDisruption).To(Equal(math.MaxInt32))
+			}
+
+			nodePool.Spec.Disruption.Budgets = append(nodePool.Spec.Disruption.Budgets, Budget{
+				Nodes:   "2",
+				Reasons: []DisruptionReason{DisruptionReasonExpired},
+			})
+			allowedDisruption, err := nodePool.GetAllowedDisruptionsByReason(fakeClock, 100, DisruptionReasonExpired)
+			Expect(err).To(BeNil())
+			Expect(allowedDisruption).To(Equal(2))
+			allowedDisruption, err = nodePool.GetAllowedDisruptionsByReason(fakeClock, 100, DisruptionReasonRepaired)
+			Expect(err).To(BeNil())
+			Expect(allowedDisruption).To(Equal(math.MaxInt32))
+		})
+	})
+
+	Context("GetAllowedPodDisruptionsByReason", func() {
+		It("should return MaxInt32 when no budget limits pods", func() {
+			allowedPods, err := nodePool.GetAllowedPodDisruptionsByReason(fakeClock, DisruptionReasonEmpty)
+			Expect(err).To(BeNil())
+			Expect(allowedPods).To(Equal(math.MaxInt32))
+		})
+		It("should get the minimum pod budget for each reason", func() {
+			budgets[0].Pods = new(int32(50))
+			budgets[4].Pods = new(int32(20))
+
+			allowedPods, err := nodePool.GetAllowedPodDisruptionsByReason(fakeClock, DisruptionReasonEmpty)
+			Expect(err).To(BeNil())
+			Expect(allowedPods).To(Equal(50))
+			allowedPods, err = nodePool.GetAllowedPodDisruptionsByReason(fakeClock, DisruptionReasonDrifted)
+			Expect(err).To(BeNil())
+			Expect(allowedPods).To(Equal(20))
+		})
+		It("should ignore pod budgets when inactive", func() {
+			budgets[0].Pods = new(int32(50))
+			budgets[0].Schedule = new("@yearly")
+
+			allowedPods, err := nodePool.GetAllowedPodDisruptionsByReason(fakeClock, DisruptionReasonEmpty)
+			Expect(err).To(BeNil())
+			Expect(allowedPods).To(Equal(math.MaxInt32))
+		})
+		It("should return zero if a schedule is invalid", func() {
+			budgets[0].Pods = new(int32(50))
+			budgets[0].Schedule = new("@wrongly")
+
+			allowedPods, err := nodePool.GetAllowedPodDisruptionsByReason(fakeClock, DisruptionReasonEmpty)
+			Expect(err).ToNot(Succeed())
+			Expect(allowedPods).To(Equal(0))
+		})
+	})
+
+	Context("GetAllowedResourceDisruptionsByReason", func() {
+		It("should return no resources when no budget limits resources", func() {
+			allowedResources, err := nodePool.GetAllowedResourceDisruptionsByReason(fakeClock, DisruptionReasonEmpty)
+			Expect(err).To(BeNil())
+			Expect(allowedResources).To(BeEmpty())
+		})
+		It("should get the minimum quantity of each resource for each reason", func() {
+			budgets[0].Resources = v1.ResourceList{v1.ResourceCPU: resource.MustParse("64"), v1.ResourceMemory: resource.MustParse("256Gi")}
+			budgets[4].Resources = v1.ResourceList{v1.ResourceCPU: resource.MustParse("16")}
+
+			allowedResources, err := nodePool.GetAllowedResourceDisruptionsByReason(fakeClock, DisruptionReasonEmpty)
+			Expect(err).To(BeNil())
+			Expect(allowedResources).To(HaveLen(2))
+			Expect(allowedResources.Cpu().String()).To(Equal("64"))
+			Expect(allowedResources.Memory().String()).To(Equal("256Gi"))
+			allowedResources, err = nodePool.GetAllowedResourceDisruptionsByReason(fakeClock, DisruptionReasonDrifted)
+			Expect(err).To(BeNil())
+			Expect(allowedResources).To(HaveLen(2))
+			Expect(allowedResources.Cpu().String()).To(Equal("16"))
+			Expect(allowedResources.Memory().String()).To(Equal("256Gi"))
+		})
+		It("should return zero quantities if a schedule is invalid", func() {
+			budgets[0].Resources = v1.ResourceList{v1.ResourceCPU: resource.MustParse("64")}
+			budgets[0].Schedule = new("@wrongly")
+
+			allowedResources, err := nodePool.GetAllowedResourceDisruptionsByReason(fakeClock, DisruptionReasonEmpty)
+			Expect(err).ToNot(Succeed())
+			Expect(allowedResources.Cpu().IsZero()).To(BeTrue())
+		})
+	})
+
 	Context("AllowedDisruptions", func() {
 		It("should return zero values if a schedule is invalid", func() {
 			budgets[0].Schedule = new("@wrongly")
@@ -0,0 +1,159 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1_test
+
+import (
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	. "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+var _ = Describe("MaintenanceWindows", func() {
+	var nodePool *NodePool
+	var fakeClock *clock.FakeClock
+
+	BeforeEach(func() {
+		// Thursday, June 15th 2000 at 12:30:30 UTC
+		fakeClock = clock.NewFakeClock(time.Date(2000, time.June, 15, 12, 30, 30, 0, time.UTC))
+		nodePool = test.NodePool()
+		nodePool.Spec.Disruption.MaintenanceWindows = &MaintenanceWindows{}
+	})
+	window := func(schedule string, duration string) MaintenanceWindow {
+		return MaintenanceWindow{Schedule: schedule, Duration: metav1.Duration{Duration: lo.Must(time.ParseDuration(duration))}}
+	}
+
+	Context("MaintenanceWindowRemaining", func() {
+		It("should always allow disruption without maintenance windows", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = nil
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(Equal(time.Duration(math.MaxInt64)))
+		})
+		It("should return the remaining time of an open window", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 12 * * *", "1h")}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(Equal(29*time.Minute + 30*time.Second))
+		})
+		It("should return zero when no window is open", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 14 * * *", "1h"), window("0 12 * * 1", "4h")}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(BeZero())
+		})
+		It("should evaluate windows in the configured time zone", func() {
+			// 14:00 in Berlin is 12:00 UTC during summer time
+			nodePool.Spec.Disruption.MaintenanceWindows.TimeZone = new("Europe/Berlin")
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 14 * * *", "1h")}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(Equal(29*time.Minute + 30*time.Second))
+		})
+		It("should return the latest closing time of overlapping windows", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 12 * * *", "1h"), window("0 11 * * *", "3h")}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(Equal(time.Hour + 29*time.Minute + 30*time.Second))
+		})
+		It("should consider every schedule hit within the window", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("*/10 12 * * *", "5m")}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(Equal(4*time.Minute + 30*time.Second))
+		})
+		It("should merge windows that open while another window is open", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 12 * * *", "1h"), window("30 12 * * *", "1h"), window("0 13 * * *", "2h")}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(Equal(2*time.Hour + 29*time.Minute + 30*time.Second))
+		})
+		It("should merge windows that open as soon as the open window closes", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 12-14 * * *", "1h")}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(Equal(2*time.Hour + 29*time.Minute + 30*time.Second))
+		})
+		It("should limit merged windows to a week", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("*/10 * * * *", "1h")}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(Equal(7 * 24 * time.Hour))
+		})
+		It("should return zero on excluded dates", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 12 * * *", "1h")}
+			nodePool.Spec.Disruption.MaintenanceWindows.Exclusions = []string{"2000-06-15"}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(BeZero())
+		})
+		It("should close windows at the start of an excluded date", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 12 * * *", "48h")}
+			nodePool.Spec.Disruption.MaintenanceWindows.Exclusions = []string{"2000-06-16"}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(Equal(11*time.Hour + 29*time.Minute + 30*time.Second))
+		})
+		It("should evaluate exclusions in the configured time zone", func() {
+			// 23:00 UTC on June 15th is already June 16th in Tokyo
+			fakeClock.SetTime(time.Date(2000, time.June, 15, 23, 0, 0, 0, time.UTC))
+			nodePool.Spec.Disruption.MaintenanceWindows.TimeZone = new("Asia/Tokyo")
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("@daily", "24h")}
+			nodePool.Spec.Disruption.MaintenanceWindows.Exclusions = []string{"2000-06-16"}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(remaining).To(BeZero())
+		})
+		It("should fail closed for an unknown time zone", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.TimeZone = new("Mars/Olympus_Mons")
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 12 * * *", "1h")}
+			remaining, err := nodePool.MaintenanceWindowRemaining(fakeClock)
+			Expect(err).ToNot(BeNil())
+			Expect(remaining).To(BeZero())
+		})
+	})
+	Context("NextOpen", func() {
+		It("should return the next time a window opens", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 14 * * *", "1h"), window("0 13 * * 1", "1h")}
+			next, ok, err := nodePool.Spec.Disruption.MaintenanceWindows.NextOpen(fakeClock.Now())
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeTrue())
+			Expect(next).To(BeTemporally("==", time.Date(2000, time.June, 15, 14, 0, 0, 0, time.UTC)))
+		})
+		It("should skip windows that open on excluded dates", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 14 * * *", "1h")}
+			nodePool.Spec.Disruption.MaintenanceWindows.Exclusions = []string{"2000-06-15", "2000-06-16"}
+			next, ok, err := nodePool.Spec.Disruption.MaintenanceWindows.NextOpen(fakeClock.Now())
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeTrue())
+			Expect(next).To(BeTemporally("==", time.Date(2000, time.June, 17, 14, 0, 0, 0, time.UTC)))
+		})
+		It("should return false when no window opens within a year", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows.Windows = []MaintenanceWindow{window("0 14 30 2 *", "1h")}
+			_, ok, err := nodePool.Spec.Disruption.MaintenanceWindows.NextOpen(fakeClock.Now())
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -26,10 +26,20 @@ import (
 
 // RuntimeValidate will be used to validate any part of the CRD that can not be validated at CRD creation
 func (in *NodePool) RuntimeValidate(ctx context.Context) (errs error) {
-	errs = multierr.Combine(in.Spec.Template.validateLabels(), in.Spec.Template.Spec.validateTaints(), in.Spec.Template.Spec.validateRequirements(ctx), in.Spec.Template.validateRequirementsNodePoolKeyDoesNotExist())
+	errs = multierr.Combine(in.Spec.Template.validateLabels(), in.Spec.Template.Spec.validateTaints(), in.Spec.Template.Spec.validateRequirements(ctx), in.Spec.Template.validateRequirementsNodePoolKeyDoesNotExist(), in.Spec.Disruption.validateMaintenanceWindows())
 	return errs
 }
 
+func (in *Disruption) validateMaintenanceWindows() error {
+	if in.MaintenanceWindows == nil {
+		return nil
+	}
+	if _, _, err := in.MaintenanceWindows.parse(); err != nil {
+		return fmt.Errorf("invalid maintenance windows, %w", err)
+	}
+	return nil
+}
+
 func (in *NodeClaimTemplate) validateLabels() (errs error) {
 	for key, value := range in.Labels {
 		if key == NodePoolLabelKey {
@@ -198,6 +198,32 @@ var _ = Describe("CEL/Validation", func() {
 			Entry("WhenEmptyOrUnderutilized", ConsolidationPolicyWhenEmptyOrUnderutilized),
 			Entry("Balanced", ConsolidationPolicyBalanced),
 		)
+		DescribeTable("should succeed on a valid balancedK with consolidationPolicy=Balanced", func(k int32) {
+			nodePool.Spec.Disruption.ConsolidationPolicy = ConsolidationPolicyBalanced
+			nodePool.Spec.Disruption.BalancedK = new(k)
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		},
+			Entry("k=1", int32(1)),
+			Entry("k=2", int32(2)),
+			Entry("k=3", int32(3)),
+		)
+		DescribeTable("should fail on an out of range balancedK", func(k int32) {
+			nodePool.Spec.Disruption.ConsolidationPolicy = ConsolidationPolicyBalanced
+			nodePool.Spec.Disruption.BalancedK = new(k)
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		},
+			Entry("k=0", int32(0)),
+			Entry("k=4", int32(4)),
+			Entry("negative", int32(-1)),
+		)
+		DescribeTable("should fail when setting balancedK without consolidationPolicy=Balanced", func(policy ConsolidationPolicy) {
+			nodePool.Spec.Disruption.ConsolidationPolicy = policy
+			nodePool.Spec.Disruption.BalancedK = new(int32(2))
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		},
+			Entry("WhenEmpty", ConsolidationPolicyWhenEmpty),
+			Entry("WhenEmptyOrUnderutilized", ConsolidationPolicyWhenEmptyOrUnderutilized),
+		)
 		It("should fail when creating a budget with an invalid cron", func() {
 			nodePool.Spec.Disruption.Budgets = []Budget{{
 				Nodes:    "10",
@@ -236,6 +262,48 @@ var _ = Describe("CEL/Validation", func() {
 			}}
 			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
 		})
+		It("should fail when creating a budget with a negative pods value", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				Nodes: "100%",
+				Pods:  new(int32(-1)),
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should succeed when creating a budget with pods and resources", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				Nodes:     "100%",
+				Pods:      new(int32(50)),
+				Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("64")},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should succeed when creating maintenance windows", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = &MaintenanceWindows{
+				TimeZone:   new("Europe/Berlin"),
+				Windows:    []MaintenanceWindow{{Schedule: "0 22 * * 1-5", Duration: metav1.Duration{Duration: lo.Must(time.ParseDuration("4h"))}}},
+				Exclusions: []string{"2000-12-24", "2000-12-31"},
+			}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+			Expect(nodePool.RuntimeValidate(ctx)).To(Succeed())
+		})
+		It("should fail when creating maintenance windows without windows", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = &MaintenanceWindows{}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail when creating maintenance windows with an invalid exclusion", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = &MaintenanceWindows{
+				Windows:    []MaintenanceWindow{{Schedule: "@daily", Duration: metav1.Duration{Duration: lo.Must(time.ParseDuration("4h"))}}},
+				Exclusions: []string{"December 24th"},
+			}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail runtime validation for maintenance windows with an unknown time zone", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = &MaintenanceWindows{
+				TimeZone: new("Mars/Olympus_Mons"),
+				Windows:  []MaintenanceWindow{{Schedule: "@daily", Duration: metav1.Duration{Duration: lo.Must(time.ParseDuration("4h"))}}},
+			}
+			Expect(nodePool.RuntimeValidate(ctx)).ToNot(Succeed())
+		})
 		It("should fail when creating a budget with a negative value percent", func() {
 			nodePool.Spec.Disruption.Budgets = []Budget{{
 				Nodes: "-10%",
@@ -360,6 +428,97 @@ var _ = Describe("CEL/Validation", func() {
 			}}
 			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
 		})
+		Context("DrainStages", func() {
+			It("should succeed when creating drain stages", func() {
+				nodePool.Spec.Disruption.DrainStages = []DrainStage{
+					{Name: "batch", Priority: &PriorityRange{Max: new(int32(0))}},
+					{Name: "ingress", PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ingress"}}, Wait: &metav1.Duration{Duration: 30 * time.Second}},
+					{Name: "critical", Priority: &PriorityRange{Min: new(int32(1000)), Max: new(int32(2000000000))}},
+				}
+				Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+			})
+			It("should fail when a drain stage doesn't select any pods", func() {
+				nodePool.Spec.Disruption.DrainStages = []DrainStage{{Name: "empty"}}
+				Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			})
+			It("should fail when a drain stage name is listed twice", func() {
+				nodePool.Spec.Disruption.DrainStages = []DrainStage{
+					{Name: "batch", Priority: &PriorityRange{Max: new(int32(0))}},
+					{Name: "batch", Priority: &PriorityRange{Min: new(int32(1))}},
+				}
+				Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			})
+			It("should fail when a drain stage name is invalid", func() {
+				nodePool.Spec.Disruption.DrainStages = []DrainStage{{Name: "Batch_Jobs", Priority: &PriorityRange{Max: new(int32(0))}}}
+				Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			})
+			It("should fail when a priority range has no bounds", func() {
+				nodePool.Spec.Disruption.DrainStages = []DrainStage{{Name: "batch", Priority: &PriorityRange{}}}
+				Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			})
+			It("should fail when a priority range's min is greater than its max", func() {
+				nodePool.Spec.Disruption.DrainStages = []DrainStage{{Name: "batch", Priority: &PriorityRange{Min: new(int32(10)), Max: new(int32(1))}}}
+				Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			})
+			It("should fail when a drain stage wait is negative", func() {
+				nodePool.Spec.Disruption.DrainStages = []DrainStage{{Name: "batch", Priority: &PriorityRange{Max: new(int32(0))}, Wait: &metav1.Duration{Duration: -time.Second}}}
+				Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			})
+		})
+		DescribeTable("should succeed when creating a valid surge drain policy", func(policy SurgeDrainPolicy) {
+			nodePool.Spec.Disruption.SurgeDrainPolicy = policy
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		},
+			Entry("Never", SurgeDrainPolicyNever),
+			Entry("WhenSingleReplica", SurgeDrainPolicyWhenSingleReplica),
+		)
+		It("should fail when creating an invalid surge drain policy", func() {
+			nodePool.Spec.Disruption.SurgeDrainPolicy = "Always"
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("InstanceSelection", func() {
+		It("should succeed when creating an instance selection policy", func() {
+			nodePool.Spec.InstanceSelection = &InstanceSelection{Objectives: []InstanceSelectionObjective{
+				{Type: InstanceSelectionObjectivePrice, Weight: 50},
+				{Type: InstanceSelectionObjectiveNodeCount, Weight: 10},
+				{Type: InstanceSelectionObjectiveFamilyPreference, Weight: 20, Key: new("karpenter.test.sh/instance-family"), Values: []string{"c7", "c6"}},
+				{Type: InstanceSelectionObjectiveInterruption, Weight: 20},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail when creating an instance selection policy without objectives", func() {
+			nodePool.Spec.InstanceSelection = &InstanceSelection{}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail when an objective type is listed twice", func() {
+			nodePool.Spec.InstanceSelection = &InstanceSelection{Objectives: []InstanceSelectionObjective{
+				{Type: InstanceSelectionObjectivePrice, Weight: 50},
+				{Type: InstanceSelectionObjectivePrice, Weight: 10},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		DescribeTable("should fail on an out of range weight", func(weight int32) {
+			nodePool.Spec.InstanceSelection = &InstanceSelection{Objectives: []InstanceSelectionObjective{
+				{Type: InstanceSelectionObjectivePrice, Weight: weight},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		},
+			Entry("weight=0", int32(0)),
+			Entry("weight=101", int32(101)),
+		)
+		It("should fail when the FamilyPreference objective doesn't have a key and values", func() {
+			nodePool.Spec.InstanceSelection = &InstanceSelection{Objectives: []InstanceSelectionObjective{
+				{Type: InstanceSelectionObjectiveFamilyPreference, Weight: 50, Key: new("karpenter.test.sh/instance-family")},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail when another objective has a key and values", func() {
+			nodePool.Spec.InstanceSelection = &InstanceSelection{Objectives: []InstanceSelectionObjective{
+				{Type: InstanceSelectionObjectivePrice, Weight: 50, Key: new("karpenter.test.sh/instance-family"), Values: []string{"c7"}},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
 	})
 	Context("Taints", func() {
 		It("should succeed for valid taints", func() {
@@ -0,0 +1,171 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DisruptionPlanSpec is a snapshot of a disruption command, updated when Karpenter starts executing it.
+// DisruptionPlans are written by Karpenter and are read-only to users.
+type DisruptionPlanSpec struct {
+	//nolint:kubeapilinter
+	// Reason is the disruption reason that produced the command (e.g. Underutilized, Empty, Drifted)
+	// +required
+	Reason string `json:"reason"`
+	//nolint:kubeapilinter
+	// Decision is the action taken on the candidates, either delete or replace
+	// +required
+	Decision string `json:"decision"`
+	//nolint:kubeapilinter
+	// NodePools are the NodePools that own the candidates
+	// +optional
+	// +listType=atomic
+	NodePools []string `json:"nodePools,omitempty"`
+	//nolint:kubeapilinter
+	// Candidates are the nodes that are being disrupted
+	// +optional
+	// +listType=atomic
+	Candidates []DisruptionPlanCandidate `json:"candidates,omitempty"`
+	//nolint:kubeapilinter
+	// Replacements are the nodes that are launched to replace the candidates
+	// +optional
+	// +listType=atomic
+	Replacements []DisruptionPlanReplacement `json:"replacements,omitempty"`
+	//nolint:kubeapilinter
+	// EstimatedSavings is the estimated hourly cost savings of the command
+	// +optional
+	EstimatedSavings string `json:"estimatedSavings,omitempty"`
+}
+
+// DisruptionPlanCandidate is a node that is disrupted by a DisruptionPlan
+type DisruptionPlanCandidate struct {
+	//nolint:kubeapilinter
+	// NodeClaim is the name of the candidate's NodeClaim
+	// +required
+	NodeClaim string `json:"nodeClaim"`
+	//nolint:kubeapilinter
+	// Node is the name of the candidate's Node
+	// +optional
+	Node string `json:"node,omitempty"`
+	//nolint:kubeapilinter
+	// NodePool is the name of the NodePool that owns the candidate
+	// +optional
+	NodePool string `json:"nodePool,omitempty"`
+	//nolint:kubeapilinter
+	// InstanceType is the instance type of the candidate
+	// +optional
+	InstanceType string `json:"instanceType,omitempty"`
+	//nolint:kubeapilinter
+	// CapacityType is the capacity type of the candidate
+	// +optional
+	CapacityType string `json:"capacityType,omitempty"`
+	//nolint:kubeapilinter
+	// Pods is the number of reschedulable pods on the candidate
+	// +optional
+	Pods int32 `json:"pods,omitempty"`
+}
+
+// DisruptionPlanReplacement is a node that is launched by a DisruptionPlan
+type DisruptionPlanReplacement struct {
+	//nolint:kubeapilinter
+	// NodeClaim is the name of the replacement NodeClaim
+	// +required
+	NodeClaim string `json:"nodeClaim"`
+	//nolint:kubeapilinter
+	// CapacityType is the capacity type that the replacement is launched with
+	// +optional
+	CapacityType string `json:"capacityType,omitempty"`
+	//nolint:kubeapilinter
+	// InstanceTypes are the cheapest instance types that the replacement may launch as
+	// +optional
+	// +listType=atomic
+	InstanceTypes []string `json:"instanceTypes,omitempty"`
+}
+
+// DisruptionPlanPhase is the execution phase of a DisruptionPlan
+type DisruptionPlanPhase string
+
+const (
+	// DisruptionPlanPhasePending indicates that Karpenter has computed the command and is waiting to validate it
+	// before executing it
+	DisruptionPlanPhasePending DisruptionPlanPhase = "Pending"
+	// DisruptionPlanPhaseWaitingForReplacements indicates that the candidates are tainted and Karpenter is waiting
+	// for the replacements to initialize before deleting the candidates
+	DisruptionPlanPhaseWaitingForReplacements DisruptionPlanPhase = "WaitingForReplacements"
+	// DisruptionPlanPhaseDraining indicates that the candidates are deleted and their pods are being evicted
+	DisruptionPlanPhaseDraining DisruptionPlanPhase = "Draining"
+	// DisruptionPlanPhaseTerminating indicates that the candidates are drained and their instances are terminating
+	DisruptionPlanPhaseTerminating DisruptionPlanPhase = "Terminating"
+	// DisruptionPlanPhaseSucceeded indicates that all of the candidates are gone
+	DisruptionPlanPhaseSucceeded DisruptionPlanPhase = "Succeeded"
+	// DisruptionPlanPhaseFailed indicates that the command was abandoned and the candidates were returned to service
+	DisruptionPlanPhaseFailed DisruptionPlanPhase = "Failed"
+)
+
+// IsTerminal returns true if the phase will not change again
+func (p DisruptionPlanPhase) IsTerminal() bool {
+	return p == DisruptionPlanPhaseSucceeded || p == DisruptionPlanPhaseFailed
+}
+
+// DisruptionPlanStatus defines the observed state of DisruptionPlan
+type DisruptionPlanStatus struct {
+	//nolint:kubeapilinter
+	// Phase is the current execution phase of the command
+	// +kubebuilder:validation:Enum:={Pending,WaitingForReplacements,Draining,Terminating,Succeeded,Failed}
+	// +optional
+	Phase DisruptionPlanPhase `json:"phase,omitempty"`
+	//nolint:kubeapilinter
+	// StartTime is the time that Karpenter started executing the command, unset while the command is pending
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	//nolint:kubeapilinter
+	// CompletionTime is the time that the command reached a terminal phase
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	//nolint:kubeapilinter
+	// FailureReason describes why the command failed
+	// +optional
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// DisruptionPlan exposes a disruption command that Karpenter is about to execute, is executing or has recently completed.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=disruptionplans,scope=Cluster,categories=karpenter
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".spec.reason",description=""
+// +kubebuilder:printcolumn:name="Decision",type="string",JSONPath=".spec.decision",description=""
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description=""
+// +kubebuilder:printcolumn:name="Savings",type="string",JSONPath=".spec.estimatedSavings",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:printcolumn:name="Failure",type="string",JSONPath=".status.failureReason",priority=1,description=""
+// +kubebuilder:subresource:status
+type DisruptionPlan struct {
+	metav1.TypeMeta `json:",inline"`
+	//nolint:kubeapilinter
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	//nolint:kubeapilinter
+	Spec   DisruptionPlanSpec   `json:"spec"`
+	Status DisruptionPlanStatus `json:"status,omitempty"` //nolint:kubeapilinter
+}
+
+// +kubebuilder:object:root=true
+type DisruptionPlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DisruptionPlan `json:"items"`
+}
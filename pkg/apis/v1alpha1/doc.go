@@ -34,5 +34,9 @@ func init() {
 	scheme.Scheme.AddKnownTypes(gv,
 		&NodeOverlay{},
 		&NodeOverlayList{},
+		&DisruptionPlan{},
+		&DisruptionPlanList{},
+		&ResourcePrediction{},
+		&ResourcePredictionList{},
 	)
 }
@@ -0,0 +1,92 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourcePredictionSpec is the predicted resource requests for the pods of a workload. ResourcePredictions are
+// written by recommenders other than VPA and are read by Karpenter when the ResourcePrediction feature gate is enabled.
+type ResourcePredictionSpec struct {
+	//nolint:kubeapilinter
+	// TargetRef is the workload whose pods the prediction applies to. The workload must be in the same namespace
+	// as the ResourcePrediction.
+	// +required
+	TargetRef ResourcePredictionTargetReference `json:"targetRef"`
+	//nolint:kubeapilinter
+	// Containers are the predicted resource requests of the workload's containers
+	// +kubebuilder:validation:MinItems:=1
+	// +listType=map
+	// +listMapKey=name
+	// +required
+	Containers []ContainerResourcePrediction `json:"containers"`
+}
+
+// ResourcePredictionTargetReference identifies the workload that a ResourcePrediction applies to
+type ResourcePredictionTargetReference struct {
+	//nolint:kubeapilinter
+	// APIVersion is the API version of the workload
+	// +required
+	APIVersion string `json:"apiVersion"`
+	//nolint:kubeapilinter
+	// Kind is the kind of the workload, one of Deployment, ReplicaSet, StatefulSet, DaemonSet or ReplicationController
+	// +kubebuilder:validation:Enum:={Deployment,ReplicaSet,StatefulSet,DaemonSet,ReplicationController}
+	// +required
+	Kind string `json:"kind"`
+	//nolint:kubeapilinter
+	// Name is the name of the workload
+	// +kubebuilder:validation:MinLength:=1
+	// +required
+	Name string `json:"name"`
+}
+
+// ContainerResourcePrediction is the predicted resource requests of a single container
+type ContainerResourcePrediction struct {
+	//nolint:kubeapilinter
+	// Name is the name of the container
+	// +required
+	Name string `json:"name"`
+	//nolint:kubeapilinter
+	// Requests are the predicted resource requests of the container. Resources that aren't listed keep the
+	// requests from the pod spec.
+	// +required
+	Requests corev1.ResourceList `json:"requests"`
+}
+
+// ResourcePrediction publishes predicted resource requests for a workload from a recommender other than VPA.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=resourcepredictions,scope=Namespaced,categories=karpenter
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.targetRef.kind",description=""
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.targetRef.name",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+type ResourcePrediction struct {
+	metav1.TypeMeta `json:",inline"`
+	//nolint:kubeapilinter
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	//nolint:kubeapilinter
+	Spec ResourcePredictionSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type ResourcePredictionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourcePrediction `json:"items"`
+}
@@ -47,6 +47,7 @@ func init() {
 }
 
 var _ cloudprovider.CloudProvider = (*CloudProvider)(nil)
+var _ cloudprovider.InterruptionProvider = (*CloudProvider)(nil)
 
 type CloudProvider struct {
 	InstanceTypes            []*cloudprovider.InstanceType
@@ -67,6 +68,9 @@ type CloudProvider struct {
 	Drifted                   cloudprovider.DriftReason
 	NodeClassGroupVersionKind []schema.GroupVersionKind
 	RepairPolicy              []cloudprovider.RepairPolicy
+	// PendingInterruptions are returned by Interruptions, to inject interruption notices in testing
+	PendingInterruptions []cloudprovider.Interruption
+	NextInterruptionsErr error
 }
 
 func NewCloudProvider() *CloudProvider {
@@ -94,6 +98,8 @@ func (c *CloudProvider) Reset() {
 	c.DeleteCalls = []*v1.NodeClaim{}
 	c.GetCalls = nil
 	c.Drifted = ""
+	c.PendingInterruptions = nil
+	c.NextInterruptionsErr = nil
 	c.NodeClassGroupVersionKind = []schema.GroupVersionKind{
 		{
 			Group:   "",
@@ -289,6 +295,18 @@ func (c *CloudProvider) IsDrifted(context.Context, *v1.NodeClaim) (cloudprovider
 	return c.Drifted, nil
 }
 
+func (c *CloudProvider) Interruptions(context.Context) ([]cloudprovider.Interruption, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.NextInterruptionsErr != nil {
+		tempError := c.NextInterruptionsErr
+		c.NextInterruptionsErr = nil
+		return nil, tempError
+	}
+	return append([]cloudprovider.Interruption{}, c.PendingInterruptions...), nil
+}
+
 func (c *CloudProvider) RepairPolicies() []cloudprovider.RepairPolicy {
 	return c.RepairPolicy
 }
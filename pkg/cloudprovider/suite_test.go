@@ -17,6 +17,7 @@ limitations under the License.
 package cloudprovider_test
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -25,7 +26,9 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 
+	karpenterv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
 )
 
 func TestCloudProvider(t *testing.T) {
@@ -215,6 +218,106 @@ var _ = Describe("Instance Type", func() {
 	})
 })
 
+var _ = Describe("Instance Selection", func() {
+	const familyLabelKey = "test-family"
+	offering := func(price float64, interruptionScore float64) *cloudprovider.Offering {
+		return &cloudprovider.Offering{
+			Available:         true,
+			Price:             price,
+			InterruptionScore: interruptionScore,
+			Requirements: scheduling.NewLabelRequirements(map[string]string{
+				karpenterv1.CapacityTypeLabelKey: karpenterv1.CapacityTypeSpot,
+				v1.LabelTopologyZone:             "test-zone-1",
+			}),
+		}
+	}
+	instanceType := func(name string, family string, cpu int64, offerings ...*cloudprovider.Offering) *cloudprovider.InstanceType {
+		return &cloudprovider.InstanceType{
+			Name:         name,
+			Requirements: scheduling.NewRequirements(scheduling.NewRequirement(familyLabelKey, v1.NodeSelectorOpIn, family)),
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:    *resource.NewQuantity(cpu, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(cpu*4*1024*1024*1024, resource.BinarySI),
+			},
+			Overhead:  &cloudprovider.InstanceTypeOverhead{},
+			Offerings: offerings,
+		}
+	}
+	names := func(its cloudprovider.InstanceTypes) []string {
+		return lo.Map(its, func(it *cloudprovider.InstanceType, _ int) string { return it.Name })
+	}
+	var instanceTypes cloudprovider.InstanceTypes
+	BeforeEach(func() {
+		instanceTypes = cloudprovider.InstanceTypes{
+			instanceType("large-old", "old", 8, offering(4, 0.5)),
+			instanceType("small-new", "new", 2, offering(1, 0.1)),
+			instanceType("medium-other", "other", 4, offering(2, 0.9)),
+		}
+	})
+	It("should order by price without a policy", func() {
+		Expect(names(instanceTypes.OrderBySelection(scheduling.NewRequirements(), nil))).To(Equal([]string{"small-new", "medium-other", "large-old"}))
+	})
+	It("should order by size for the NodeCount objective", func() {
+		selection := &karpenterv1.InstanceSelection{Objectives: []karpenterv1.InstanceSelectionObjective{
+			{Type: karpenterv1.InstanceSelectionObjectiveNodeCount, Weight: 1},
+		}}
+		Expect(names(instanceTypes.OrderBySelection(scheduling.NewRequirements(), selection))).To(Equal([]string{"large-old", "medium-other", "small-new"}))
+	})
+	It("should order by the preferred values for the FamilyPreference objective", func() {
+		selection := &karpenterv1.InstanceSelection{Objectives: []karpenterv1.InstanceSelectionObjective{
+			{Type: karpenterv1.InstanceSelectionObjectiveFamilyPreference, Weight: 1, Key: lo.ToPtr(familyLabelKey), Values: []string{"other", "old"}},
+		}}
+		Expect(names(instanceTypes.OrderBySelection(scheduling.NewRequirements(), selection))).To(Equal([]string{"medium-other", "large-old", "small-new"}))
+	})
+	It("should only prefer instance types that explicitly have the preferred values for the FamilyPreference objective", func() {
+		instanceTypes[0].Requirements = scheduling.NewRequirements(scheduling.NewRequirement(familyLabelKey, v1.NodeSelectorOpNotIn, "new"))
+		instanceTypes[1].Requirements = scheduling.NewRequirements(scheduling.NewRequirement(familyLabelKey, v1.NodeSelectorOpExists))
+		selection := &karpenterv1.InstanceSelection{Objectives: []karpenterv1.InstanceSelectionObjective{
+			{Type: karpenterv1.InstanceSelectionObjectiveFamilyPreference, Weight: 1, Key: lo.ToPtr(familyLabelKey), Values: []string{"old", "other"}},
+		}}
+		Expect(names(instanceTypes.OrderBySelection(scheduling.NewRequirements(), selection))).To(Equal([]string{"medium-other", "small-new", "large-old"}))
+	})
+	It("should order by the interruption score of the offerings for the Interruption objective", func() {
+		selection := &karpenterv1.InstanceSelection{Objectives: []karpenterv1.InstanceSelectionObjective{
+			{Type: karpenterv1.InstanceSelectionObjectiveInterruption, Weight: 1},
+		}}
+		Expect(names(instanceTypes.OrderBySelection(scheduling.NewRequirements(), selection))).To(Equal([]string{"small-new", "large-old", "medium-other"}))
+	})
+	It("should only consider compatible and available offerings", func() {
+		instanceTypes[1].Offerings = append(instanceTypes[1].Offerings, offering(0.1, 0))
+		instanceTypes[1].Offerings[1].Available = false
+		instanceTypes[2].Offerings = append(instanceTypes[2].Offerings, offering(0.1, 0))
+		instanceTypes[2].Offerings[1].Requirements = scheduling.NewLabelRequirements(map[string]string{
+			karpenterv1.CapacityTypeLabelKey: karpenterv1.CapacityTypeOnDemand,
+			v1.LabelTopologyZone:             "test-zone-1",
+		})
+		selection := &karpenterv1.InstanceSelection{Objectives: []karpenterv1.InstanceSelectionObjective{
+			{Type: karpenterv1.InstanceSelectionObjectiveInterruption, Weight: 1},
+		}}
+		reqs := scheduling.NewRequirements(scheduling.NewRequirement(karpenterv1.CapacityTypeLabelKey, v1.NodeSelectorOpIn, karpenterv1.CapacityTypeSpot))
+		Expect(names(instanceTypes.OrderBySelection(reqs, selection))).To(Equal([]string{"small-new", "large-old", "medium-other"}))
+	})
+	DescribeTable("should combine weighted objectives",
+		func(priceWeight int32, familyWeight int32, expected []string) {
+			selection := &karpenterv1.InstanceSelection{Objectives: []karpenterv1.InstanceSelectionObjective{
+				{Type: karpenterv1.InstanceSelectionObjectivePrice, Weight: priceWeight},
+				{Type: karpenterv1.InstanceSelectionObjectiveFamilyPreference, Weight: familyWeight, Key: lo.ToPtr(familyLabelKey), Values: []string{"old"}},
+			}}
+			Expect(names(instanceTypes.OrderBySelection(scheduling.NewRequirements(), selection))).To(Equal(expected))
+		},
+		Entry("when price outweighs the family", int32(3), int32(1), []string{"small-new", "medium-other", "large-old"}),
+		Entry("when the family outweighs price", int32(1), int32(3), []string{"large-old", "small-new", "medium-other"}),
+	)
+	It("should keep the instance types that are ranked first when truncating", func() {
+		selection := &karpenterv1.InstanceSelection{Objectives: []karpenterv1.InstanceSelectionObjective{
+			{Type: karpenterv1.InstanceSelectionObjectiveNodeCount, Weight: 1},
+		}}
+		truncated, err := instanceTypes.Truncate(context.Background(), scheduling.NewRequirements(), selection, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names(truncated)).To(Equal([]string{"large-old", "medium-other"}))
+	})
+})
+
 type BaseError struct {
 	error
 }
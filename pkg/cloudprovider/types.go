@@ -32,6 +32,7 @@ import (
 	"github.com/awslabs/operatorpkg/status"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
@@ -117,6 +118,53 @@ type NodeLifecycleHook interface {
 	Registered(context.Context, *v1.NodeClaim) (NodeLifecycleHookResult, error)
 }
 
+type NodeTerminationHookStage string
+
+const (
+	// NodeTerminationHookStagePreDrain hooks run after the node is tainted and before its pods are drained
+	NodeTerminationHookStagePreDrain NodeTerminationHookStage = "PreDrain"
+	// NodeTerminationHookStagePreDelete hooks run after the node is drained and its volumes are detached, and before
+	// its instance is deleted
+	NodeTerminationHookStagePreDelete NodeTerminationHookStage = "PreDelete"
+)
+
+// NodeTerminationHook gates node termination, e.g. to deregister a node from an external load balancer before it's
+// drained. All hooks of a stage must return an empty result before termination proceeds past the stage, unless the
+// NodeClaim's terminationGracePeriod elapses. Hooks only run for nodes that have a NodeClaim, and a hook's status is
+// surfaced as the NodeClaim condition named by v1.TerminationHookConditionType.
+type NodeTerminationHook interface {
+	// Name for the hook. It must be a valid condition type, e.g. "LoadBalancerDeregistration".
+	Name() string
+	// Stage of termination that the hook gates.
+	Stage() NodeTerminationHookStage
+	// Terminating returns an empty result once this hook's preconditions are satisfied and termination can proceed.
+	// It isn't called again once it has returned an empty result for the NodeClaim.
+	Terminating(context.Context, *v1.NodeClaim, *corev1.Node) (NodeLifecycleHookResult, error)
+}
+
+// Interruption is a notice of an upcoming involuntary interruption of an instance, e.g. a spot reclaim, scheduled
+// maintenance or a host retirement.
+type Interruption struct {
+	// ProviderID of the instance that will be interrupted
+	ProviderID string
+	// Reason is a short CamelCase description of the interruption, e.g. "SpotInterruption"
+	Reason string
+	// Message is a human-readable description of the interruption
+	Message string
+	// Deadline is the time at which the instance will be interrupted. A zero Deadline means that the cloud provider
+	// didn't give one, in which case the NodeClaim's terminationGracePeriod applies.
+	Deadline time.Time
+}
+
+// InterruptionProvider is optionally implemented by cloud providers that receive notices of upcoming involuntary
+// interruptions. Karpenter launches replacements for the pods on an interrupted NodeClaim, and drains and deletes
+// it before the interruption's deadline.
+type InterruptionProvider interface {
+	// Interruptions returns the pending interruptions of the cloud provider's instances. An interruption may be
+	// returned until its instance is terminated; Karpenter disrupts each NodeClaim once.
+	Interruptions(context.Context) ([]Interruption, error)
+}
+
 // InstanceType describes the properties of a potential node (either concrete attributes of an instance of this type
 // or supported options in the case of arrays)
 // +k8s:deepcopy-gen=true
@@ -354,6 +402,94 @@ func (its InstanceTypes) OrderByPrice(reqs scheduling.Requirements) InstanceType
 	return its
 }
 
+// OrderBySelection orders instance types by the weighted objectives of a NodePool's instance selection policy, only
+// considering the offerings that are available and compatible with the requirements. Instance types with equal scores
+// are ordered by price, and instance types are only ordered by price if there is no policy.
+func (its InstanceTypes) OrderBySelection(reqs scheduling.Requirements, selection *v1.InstanceSelection) InstanceTypes {
+	its = its.OrderByPrice(reqs)
+	if selection == nil || len(its) == 0 {
+		return its
+	}
+	scores := make([]float64, len(its))
+	for _, objective := range selection.Objectives {
+		for i, score := range its.objectiveScores(reqs, objective) {
+			scores[i] += float64(objective.Weight) * score
+		}
+	}
+	ordered := lo.Range(len(its))
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return scores[ordered[i]] < scores[ordered[j]]
+	})
+	copy(its, lo.Map(ordered, func(i int, _ int) *InstanceType { return its[i] }))
+	return its
+}
+
+// objectiveScores scores each instance type for the objective, from 0 for the best to 1 for the worst
+func (its InstanceTypes) objectiveScores(reqs scheduling.Requirements, objective v1.InstanceSelectionObjective) []float64 {
+	switch objective.Type {
+	case v1.InstanceSelectionObjectivePrice:
+		return normalizeScores(lo.Map(its, func(it *InstanceType, _ int) float64 {
+			if of := it.Offerings.Available().Compatible(reqs).Cheapest(); of != nil {
+				return of.Price
+			}
+			return math.Inf(1)
+		}))
+	case v1.InstanceSelectionObjectiveNodeCount:
+		// Larger instance types need fewer nodes, so their resources are negated to score them better
+		cpu := normalizeScores(lo.Map(its, func(it *InstanceType, _ int) float64 {
+			allocatable := it.Allocatable()
+			return -float64(allocatable.Cpu().MilliValue())
+		}))
+		memory := normalizeScores(lo.Map(its, func(it *InstanceType, _ int) float64 {
+			allocatable := it.Allocatable()
+			return -float64(allocatable.Memory().Value())
+		}))
+		return lo.Map(cpu, func(score float64, i int) float64 { return (score + memory[i]) / 2 })
+	case v1.InstanceSelectionObjectiveFamilyPreference:
+		key := lo.FromPtr(objective.Key)
+		return lo.Map(its, func(it *InstanceType, _ int) float64 {
+			// Only instance types that explicitly list the values match, since NotIn and Exists requirements would match
+			// every preferred value
+			if !it.Requirements.Has(key) || it.Requirements.Get(key).Operator() != corev1.NodeSelectorOpIn {
+				return 1
+			}
+			for i, value := range objective.Values {
+				if it.Requirements.Get(key).Has(value) {
+					return float64(i) / float64(len(objective.Values))
+				}
+			}
+			return 1
+		})
+	case v1.InstanceSelectionObjectiveInterruption:
+		return normalizeScores(lo.Map(its, func(it *InstanceType, _ int) float64 {
+			offerings := it.Offerings.Available().Compatible(reqs)
+			if len(offerings) == 0 {
+				return math.Inf(1)
+			}
+			return lo.Min(lo.Map(offerings, func(of *Offering, _ int) float64 { return of.InterruptionScore }))
+		}))
+	default:
+		return make([]float64, len(its))
+	}
+}
+
+// normalizeScores scales the values from 0 for the lowest value to 1 for the highest value. Infinite values, e.g. the
+// price of an instance type without compatible offerings, score 1.
+func normalizeScores(values []float64) []float64 {
+	finite := lo.Reject(values, func(v float64, _ int) bool { return math.IsInf(v, 0) })
+	lowest, highest := lo.Min(finite), lo.Max(finite)
+	return lo.Map(values, func(v float64, _ int) float64 {
+		switch {
+		case math.IsInf(v, 0):
+			return 1
+		case highest == lowest:
+			return 0
+		default:
+			return (v - lowest) / (highest - lowest)
+		}
+	})
+}
+
 // Compatible returns the list of instanceTypes based on the supported capacityType and zones in the requirements
 func (its InstanceTypes) Compatible(requirements scheduling.Requirements) InstanceTypes {
 	var filteredInstanceTypes []*InstanceType
@@ -432,10 +568,11 @@ func (its InstanceTypes) SatisfiesMinValues(requirements scheduling.Requirements
 	return len(its), nil, nil
 }
 
-// Truncate truncates the InstanceTypes based on the passed-in requirements
+// Truncate truncates the InstanceTypes based on the passed-in requirements, keeping the instance types that the
+// instance selection policy ranks first
 // It returns an error if it isn't possible to truncate the instance types on maxItems without violating minValues
-func (its InstanceTypes) Truncate(ctx context.Context, requirements scheduling.Requirements, maxItems int) (InstanceTypes, error) {
-	truncatedInstanceTypes := lo.Slice(its.OrderByPrice(requirements), 0, maxItems)
+func (its InstanceTypes) Truncate(ctx context.Context, requirements scheduling.Requirements, selection *v1.InstanceSelection, maxItems int) (InstanceTypes, error) {
+	truncatedInstanceTypes := lo.Slice(its.OrderBySelection(requirements, selection), 0, maxItems)
 	// Only check for a validity of NodeClaim if its requirement has minValues in it.
 	if requirements.HasMinValues() {
 		// If minValues is NOT met for any of the requirement across InstanceTypes, then only allow it if min values policy is set to BestEffort.
@@ -472,6 +609,12 @@ type Offering struct {
 	Price               float64
 	Available           bool
 	ReservationCapacity int
+	// ReservationEndTime is the time that the offering's capacity reservation ends, after which the capacity is no longer
+	// reserved. It's zero if the reservation doesn't end.
+	ReservationEndTime metav1.Time
+	// InterruptionScore is the relative likelihood, between 0 and 1, that capacity launched from the offering is
+	// interrupted, e.g. because spot capacity is reclaimed. It's zero if the cloud provider doesn't score interruptions.
+	InterruptionScore float64
 
 	// CapacityOverride specifies resource overrides for this offering's capacity.
 	// Values are merged with the instance type's base capacity — new keys are added,
@@ -90,6 +90,7 @@ func (in *Offering) DeepCopyInto(out *Offering) {
 			(*out)[key] = outVal
 		}
 	}
+	in.ReservationEndTime.DeepCopyInto(&out.ReservationEndTime)
 	if in.CapacityOverride != nil {
 		in, out := &in.CapacityOverride, &out.CapacityOverride
 		*out = make(v1.ResourceList, len(*in))
@@ -42,6 +42,7 @@ import (
 	metricsnode "sigs.k8s.io/karpenter/pkg/controllers/metrics/node"
 	metricsnodepool "sigs.k8s.io/karpenter/pkg/controllers/metrics/nodepool"
 	metricspod "sigs.k8s.io/karpenter/pkg/controllers/metrics/pod"
+	metricsreservation "sigs.k8s.io/karpenter/pkg/controllers/metrics/reservation"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/health"
 	nodehydration "sigs.k8s.io/karpenter/pkg/controllers/node/hydration"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination"
@@ -51,6 +52,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/expiration"
 	nodeclaimgarbagecollection "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/garbagecollection"
 	nodeclaimhydration "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/hydration"
+	nodeclaiminterruption "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/interruption"
 	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/podevents"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeoverlay"
@@ -70,11 +72,14 @@ import (
 	"sigs.k8s.io/karpenter/pkg/state/cost"
 	"sigs.k8s.io/karpenter/pkg/state/nodepoolhealth"
 	"sigs.k8s.io/karpenter/pkg/state/prediction"
+	disruptionutils "sigs.k8s.io/karpenter/pkg/utils/disruption"
 )
 
 type ControllerOptions struct {
 	registrationHooks    []cloudprovider.NodeLifecycleHook
+	terminationHooks     []cloudprovider.NodeTerminationHook
 	disableVPAPrediction bool
+	predictionSources    []prediction.Source
 }
 
 // WithoutVPAPrediction disables the VPA prediction controller. Use this when
@@ -85,6 +90,16 @@ func WithoutVPAPrediction() option.Function[ControllerOptions] {
 	}
 }
 
+// WithPredictionSource registers an additional source of resource predictions, e.g. an in-house recommender.
+// Predictions from every source share the same store, so when multiple sources target the same workload the
+// earliest created recommendation wins. The built-in prediction.ConfigMapSource and prediction.ResourcePredictionSource
+// are registered when the ResourcePrediction feature gate is enabled.
+func WithPredictionSource(source prediction.Source) option.Function[ControllerOptions] {
+	return func(o *ControllerOptions) {
+		o.predictionSources = append(o.predictionSources, source)
+	}
+}
+
 // WithRegistrationHook registers a hook that blocks Karpenter from marking a node as registered
 // until the hook's preconditions are satisfied. This is useful when a cloud provider needs to
 // apply well-known labels asynchronously after instance launch (e.g., capacity reservation labels
@@ -95,6 +110,15 @@ func WithRegistrationHook(hook cloudprovider.NodeLifecycleHook) option.Function[
 	}
 }
 
+// WithTerminationHook registers a hook that blocks Karpenter from proceeding past a stage of node termination
+// until the hook's preconditions are satisfied, e.g. deregistering the node from an external load balancer
+// before it's drained, or waiting for an external job scheduler to release the node before it's deleted.
+func WithTerminationHook(hook cloudprovider.NodeTerminationHook) option.Function[ControllerOptions] {
+	return func(o *ControllerOptions) {
+		o.terminationHooks = append(o.terminationHooks, hook)
+	}
+}
+
 func NewControllers(
 	ctx context.Context,
 	mgr manager.Manager,
@@ -111,26 +135,28 @@ func NewControllers(
 	o := option.Resolve(opts...)
 	deviceAllocationController := deviceallocation.NewController(kubeClient)
 	virtualPodCache := virtualpods.NewVirtualPodCache(kubeClient)
-	p := provisioning.NewProvisioner(kubeClient, recorder, cloudProvider, cluster, clock, deviceAllocationController, virtualPodCache)
-	evictionQueue := terminator.NewQueue(kubeClient, recorder)
+	p := provisioning.NewProvisioner(kubeClient, recorder, cloudProvider, cluster, clock, deviceAllocationController, virtualPodCache, predictionStore)
+	evictionQueue := terminator.NewQueue(kubeClient, recorder, clock)
 	disruptionQueue := disruption.NewQueue(kubeClient, recorder, cluster, clock, p)
 	npState := nodepoolhealth.NewState()
 	clusterCost := cost.NewClusterCost(ctx, cloudProvider, kubeClient)
+	forcefulBudgets := disruptionutils.NewForcefulBudgets()
 	controllers := []controller.Controller{
 		p, evictionQueue, disruptionQueue,
 		disruption.NewController(clock, kubeClient, p, cloudProvider, recorder, cluster, disruptionQueue, clusterCost),
 		provisioning.NewPodController(kubeClient, p, cluster),
 		provisioning.NewNodeController(kubeClient, p),
 		nodepoolhash.NewController(kubeClient, cloudProvider),
-		expiration.NewController(clock, kubeClient, cloudProvider),
+		expiration.NewController(clock, kubeClient, cloudProvider, forcefulBudgets),
 		informer.NewDaemonSetController(kubeClient, cluster),
 		informer.NewNodeController(kubeClient, cluster),
 		informer.NewPodController(kubeClient, cluster),
+		informer.NewNamespaceController(kubeClient, cluster),
 		informer.NewNodePoolController(kubeClient, cloudProvider, cluster, clusterCost),
 		informer.NewNodeClaimController(kubeClient, cloudProvider, cluster, clusterCost),
 		informer.NewPricingController(kubeClient, cloudProvider, clusterCost),
 		statenodeclaimgc.NewController(kubeClient, cluster),
-		termination.NewController(clock, kubeClient, cloudProvider, terminator.NewTerminator(clock, kubeClient, evictionQueue, recorder), recorder),
+		termination.NewController(clock, kubeClient, cloudProvider, terminator.NewTerminator(clock, kubeClient, evictionQueue, recorder), recorder, o.terminationHooks),
 		nodepoolreadiness.NewController(clock, kubeClient, cloudProvider),
 		nodepoolregistrationhealth.NewController(clock, kubeClient, cloudProvider, npState),
 		nodepoolcounter.NewController(kubeClient, cloudProvider, cluster),
@@ -144,6 +170,10 @@ func NewControllers(
 		nodehydration.NewController(kubeClient, cloudProvider),
 	}
 
+	if options.FromContext(ctx).EnableProfiling {
+		lo.Must0(mgr.AddMetricsServerExtraHandler("/debug/state", state.NewDebugHandler(cluster)), "failed to add cluster state debug handler")
+	}
+
 	if !options.FromContext(ctx).IgnoreDRARequests {
 		controllers = append(controllers, deviceAllocationController)
 	}
@@ -153,6 +183,7 @@ func NewControllers(
 			metricspod.NewController(kubeClient, cluster),
 			metricsnodepool.NewController(kubeClient, cloudProvider, clusterCost),
 			metricsnode.NewController(cluster),
+			metricsreservation.NewController(kubeClient, cloudProvider, cluster),
 			status.NewController[*v1.NodeClaim](
 				kubeClient,
 				mgr.GetEventRecorderFor("karpenter"), //nolint:staticcheck // SA1019: will be replaced by mgr.GetEventRecorder once operatorpkg is updated
@@ -176,7 +207,13 @@ func NewControllers(
 
 	// The cloud provider must define status conditions for the node repair controller to use to detect unhealthy nodes
 	if len(cloudProvider.RepairPolicies()) != 0 && options.FromContext(ctx).FeatureGates.NodeRepair {
-		controllers = append(controllers, health.NewController(kubeClient, cloudProvider, clock, recorder))
+		controllers = append(controllers, health.NewController(kubeClient, cloudProvider, clock, recorder, forcefulBudgets))
+	}
+
+	// The cloud provider must implement the InterruptionProvider interface for Karpenter to handle interruption notices.
+	// We check the undecorated cloud provider since decorators don't implement optional interfaces.
+	if interruptionProvider, ok := overlayUndecoratedCloudProvider.(cloudprovider.InterruptionProvider); ok {
+		controllers = append(controllers, nodeclaiminterruption.NewController(clock, kubeClient, cloudProvider, interruptionProvider, cluster, p, recorder))
 	}
 
 	if options.FromContext(ctx).FeatureGates.StaticCapacity {
@@ -184,6 +221,10 @@ func NewControllers(
 		controllers = append(controllers, staticdeprovisioning.NewController(kubeClient, cluster, cloudProvider, clock, recorder))
 	}
 
+	if options.FromContext(ctx).FeatureGates.DisruptionPlan {
+		controllers = append(controllers, disruption.NewPlanController(clock, kubeClient, disruptionQueue))
+	}
+
 	if options.FromContext(ctx).FeatureGates.NodeOverlay {
 		controllers = append(controllers, nodeoverlay.NewController(clock, kubeClient, overlayUndecoratedCloudProvider, instanceTypeStore, cluster))
 	}
@@ -209,6 +250,12 @@ func NewControllers(
 	if !o.disableVPAPrediction {
 		controllers = append(controllers, informer.NewVPAController(kubeClient, mgr.GetAPIReader(), predictionStore))
 	}
+	if options.FromContext(ctx).FeatureGates.ResourcePrediction {
+		o.predictionSources = append(o.predictionSources, prediction.NewConfigMapSource(kubeClient), prediction.NewResourcePredictionSource(kubeClient))
+	}
+	for _, source := range o.predictionSources {
+		controllers = append(controllers, informer.NewPredictionSourceController(source, predictionStore))
+	}
 
 	return controllers
 }
@@ -156,7 +156,7 @@ func EvaluateBalancedMove(ctx context.Context, cmd Command, nodePoolTotals map[s
 			poolSavings = savings * (poolCost / totalCost)
 		}
 
-		result := ScoreMove(poolSavings, disruptionCost, totals, v1.BalancedK)
+		result := ScoreMove(poolSavings, disruptionCost, totals, nodePool.Spec.Disruption.GetBalancedK())
 		perPool[poolName] = result
 
 		log.FromContext(ctx).V(1).Info("consolidation score",
@@ -169,7 +169,7 @@ func EvaluateBalancedMove(ctx context.Context, cmd Command, nodePoolTotals map[s
 			"nodepool_total_cost", totals.TotalCost,
 			"nodepool_total_disruption_cost", totals.TotalDisruptionCost,
 			"threshold", result.Threshold(),
-			"k", v1.BalancedK,
+			"k", result.K,
 			"approved", result.Approved(),
 			"decision", cmd.Decision(),
 			"candidates", lo.Map(poolCandidates, func(c *Candidate, _ int) string { return c.Name() }),
@@ -294,6 +294,6 @@ func (e *balancedEvaluator) CanPassThreshold(c *Candidate) bool {
 	}
 	// A DELETE saves the full node cost with zero replacement cost — the upper
 	// bound on any move's score. If even DELETE can't pass, no REPLACE will.
-	result := ScoreMove(c.Price, c.RescheduleDisruptionCost, totals, v1.BalancedK)
+	result := ScoreMove(c.Price, c.RescheduleDisruptionCost, totals, c.NodePool.Spec.Disruption.GetBalancedK())
 	return result.Approved()
 }
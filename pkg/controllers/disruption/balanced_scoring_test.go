@@ -84,7 +84,7 @@ func makeCandidate(nodeName string, np *v1.NodePool, it *cloudprovider.InstanceT
 		NodePool:                 np,
 		reschedulablePods:        pods,
 		Price:                    resolveNodePrice(sn, it),
-		RescheduleDisruptionCost: computeRescheduleDisruptionCost(context.Background(), pods),
+		RescheduleDisruptionCost: computeRescheduleDisruptionCost(context.Background(), pods, nil),
 	}
 }
 
@@ -97,10 +97,12 @@ func makeNodePool(name string, policy v1.ConsolidationPolicy) *v1.NodePool {
 	return np
 }
 
-// makeBalancedNodePool creates a scoring NodePool with Balanced policy (k=2).
-// The threshold parameter is ignored (k is hardcoded at 2).
-func makeBalancedNodePool(name string, _ *int32) *v1.NodePool {
-	return makeNodePool(name, v1.ConsolidationPolicyBalanced)
+// makeBalancedNodePool creates a scoring NodePool with Balanced policy.
+// A nil k leaves balancedK unset so the default (k=2) applies.
+func makeBalancedNodePool(name string, k *int32) *v1.NodePool {
+	np := makeNodePool(name, v1.ConsolidationPolicyBalanced)
+	np.Spec.Disruption.BalancedK = k
+	return np
 }
 
 // makePod creates a minimal pod. If deletionCost is non-empty, the annotation is set.
@@ -307,6 +309,34 @@ var _ = Describe("Balanced Scoring", func() {
 			Expect(result.Score()).To(BeNumerically("~", 1.0, 0.05))
 		})
 
+		DescribeTable("should score against the NodePool's balancedK",
+			func(k *int32, expectedK int32, expectApproved bool) {
+				ctx := context.Background()
+				np := makeBalancedNodePool("pool-k", k)
+				it := makeInstanceType("m7i.xlarge", 4.84)
+				pod := makePod("pod", "")
+
+				allCandidates := make([]*Candidate, 10)
+				for i := range allCandidates {
+					allCandidates[i] = makeCandidate("node-"+string(rune('0'+i)), np, it, []*corev1.Pod{pod})
+					allCandidates[i].RescheduleDisruptionCost = 2.0
+				}
+				allCandidates[0].RescheduleDisruptionCost = 6.0
+				nodePoolTotals := computeNodePoolTotals(context.Background(), allCandidates, candidateNodes(allCandidates), nil)
+
+				// savings_fraction = 0.10, disruption_fraction = 6/24 = 0.25, score = 0.40
+				allApproved, perPool := EvaluateBalancedMove(ctx, Command{Candidates: []*Candidate{allCandidates[0]}}, nodePoolTotals)
+				result := perPool["pool-k"]
+				Expect(result.Score()).To(BeNumerically("~", 0.4, 0.01))
+				Expect(result.K).To(Equal(expectedK))
+				Expect(result.Threshold()).To(BeNumerically("~", 1.0/float64(expectedK), 0.01))
+				Expect(allApproved).To(Equal(expectApproved))
+			},
+			Entry("default k=2 rejects", nil, v1.BalancedK, false),
+			Entry("k=1 rejects", int32Ptr(1), int32(1), false),
+			Entry("k=3 approves", int32Ptr(3), int32(3), true),
+		)
+
 		It("should attribute savings proportionally in cross-NodePool scenarios", func() {
 			ctx := context.Background()
 			balancedNP := makeBalancedNodePool("pool-balanced", int32Ptr(2))
@@ -439,6 +469,17 @@ var _ = Describe("Balanced Scoring", func() {
 			c3 := makeCandidate("node3", np, nil, pods)
 			Expect(c3.SavingsRatio()).To(Equal(0.0))
 		})
+		It("should use the karpenter.sh/disruption-cost annotation", func() {
+			np := makeNodePool("pool", v1.ConsolidationPolicyBalanced)
+			it := makeInstanceType("m7i.xlarge", 4.84)
+
+			// disruption = 1.0 + 4.0 = 5.0, ratio = 4.84 / 5.0 = 0.968
+			pod := makePod("p1", "")
+			pod.Annotations = map[string]string{v1.DisruptionCostAnnotationKey: "4"}
+			c := makeCandidate("node", np, it, []*corev1.Pod{pod})
+			Expect(c.RescheduleDisruptionCost).To(BeNumerically("~", 5.0, 0.001))
+			Expect(c.SavingsRatio()).To(BeNumerically("~", 0.968, 0.001))
+		})
 	})
 
 	Describe("ScoreMove (SavingsRatio)", func() {
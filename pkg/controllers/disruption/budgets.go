@@ -0,0 +1,208 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+)
+
+// DisruptionBudget is the remaining disruption that a NodePool allows for a disruption reason
+type DisruptionBudget struct {
+	// Nodes is the number of nodes that can still be disrupted
+	Nodes int
+	// Pods is the number of pods that can still be evicted, or MAXINT if pods aren't limited
+	Pods int
+	// Resources is the capacity that can still be disrupted. Resources that aren't limited are omitted.
+	Resources corev1.ResourceList
+	// idle is true while nothing in the NodePool is consuming the budget
+	idle bool
+}
+
+func newDisruptionBudget(nodePool *v1.NodePool, clk clock.Clock, reason v1.DisruptionReason) *DisruptionBudget {
+	return &DisruptionBudget{
+		Nodes:     math.MaxInt32,
+		Pods:      nodePool.MustGetAllowedPodDisruptions(clk, reason),
+		Resources: nodePool.MustGetAllowedResourceDisruptions(clk, reason).DeepCopy(),
+		idle:      true,
+	}
+}
+
+// bounded returns true if the budget limits pods or resources
+func (b *DisruptionBudget) bounded() bool {
+	return b.Pods != math.MaxInt32 || len(b.Resources) > 0
+}
+
+// exhausted returns true if no more pods or resources can be disrupted
+func (b *DisruptionBudget) exhausted() bool {
+	return b.Pods <= 0 || lo.SomeBy(lo.Values(b.Resources), func(q resource.Quantity) bool { return q.Sign() <= 0 })
+}
+
+// allows returns true if the pod and resource budgets allow disrupting a node with the given usage. Similar to how
+// percentage node budgets round up, a node that is larger than the budget can still be disrupted when nothing else
+// in the NodePool is being disrupted so that large nodes aren't blocked from disruption forever.
+func (b *DisruptionBudget) allows(u disruptionUsage) bool {
+	if b.exhausted() {
+		return false
+	}
+	if b.idle {
+		return true
+	}
+	if u.pods > b.Pods {
+		return false
+	}
+	for resourceName, remaining := range b.Resources {
+		if quantity, ok := u.resources[resourceName]; ok && quantity.Cmp(remaining) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// consume subtracts the usage from the pod and resource budgets
+func (b *DisruptionBudget) consume(u disruptionUsage) {
+	b.idle = false
+	if b.Pods != math.MaxInt32 {
+		b.Pods -= u.pods
+	}
+	for resourceName, remaining := range b.Resources {
+		remaining.Sub(u.resources[resourceName])
+		b.Resources[resourceName] = remaining
+	}
+}
+
+func (b *DisruptionBudget) clone() *DisruptionBudget {
+	return &DisruptionBudget{
+		Nodes:     b.Nodes,
+		Pods:      b.Pods,
+		Resources: b.Resources.DeepCopy(),
+		idle:      b.idle,
+	}
+}
+
+// disruptionUsage is the amount of the pod and resource budgets that disrupting a node consumes. Pods are counted
+// while they're bound to the node, so the budget frees up as the node drains. Resources are the node's capacity.
+type disruptionUsage struct {
+	pods      int
+	resources corev1.ResourceList
+}
+
+func usageOf(node *state.StateNode) disruptionUsage {
+	return disruptionUsage{
+		pods:      node.PodCount(),
+		resources: node.Capacity(),
+	}
+}
+
+// limitToBudgets removes candidates from the commands that would exceed the pod and resource budgets of their
+// NodePools. Node budgets are already enforced by the disruption methods. Deleting a subset of the candidates of a
+// valid delete command is still valid, but replacements are computed for the command as a whole, so a command that
+// launches replacements is dropped if any of its candidates exceeds the budgets.
+func limitToBudgets(budgets map[string]*DisruptionBudget, cmds []Command) []Command {
+	var limited []Command
+	for _, cmd := range cmds {
+		remaining := lo.MapValues(budgets, func(b *DisruptionBudget, _ string) *DisruptionBudget { return b.clone() })
+		allowed := lo.Filter(cmd.Candidates, func(c *Candidate, _ int) bool {
+			budget, ok := remaining[c.NodePool.Name]
+			if !ok || !budget.allows(usageOf(c.StateNode)) {
+				return false
+			}
+			budget.consume(usageOf(c.StateNode))
+			return true
+		})
+		if len(allowed) == 0 || (len(allowed) < len(cmd.Candidates) && len(cmd.Replacements) > 0) {
+			continue
+		}
+		if len(allowed) < len(cmd.Candidates) {
+			cmd.Candidates, cmd.PoolDisruptionCosts = allowed, computePoolDisruptionCosts(allowed)
+		}
+		budgets = remaining
+		limited = append(limited, cmd)
+	}
+	return limited
+}
+
+// reservation is the usage of a candidate that counts against its NodePool's budgets until it's marked for deletion
+type reservation struct {
+	nodePool string
+	usage    disruptionUsage
+}
+
+// reserveBudgets returns an error if starting the command would exceed the pod or resource budgets of its NodePools.
+// The budgets may have changed since the command was computed, and other commands may be starting concurrently, so
+// the budgets are recalculated from cluster state and the candidates of commands that are still being started.
+func (q *Queue) reserveBudgets(cmd *Command) error {
+	budgets := map[string]*DisruptionBudget{}
+	for _, c := range cmd.Candidates {
+		if _, ok := budgets[c.NodePool.Name]; !ok {
+			budgets[c.NodePool.Name] = newDisruptionBudget(c.NodePool, q.clock, cmd.Reason())
+		}
+	}
+	budgets = lo.PickBy(budgets, func(_ string, b *DisruptionBudget) bool { return b.bounded() })
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	candidates := sets.New(lo.Map(cmd.Candidates, func(c *Candidate, _ int) string { return c.ProviderID() })...)
+	q.budgetMu.Lock()
+	defer q.budgetMu.Unlock()
+	for node := range q.cluster.Nodes() {
+		nodePool, ok := budgetedNodePool(node)
+		if !ok || budgets[nodePool] == nil || candidates.Has(node.ProviderID()) {
+			continue
+		}
+		if _, reserved := q.reservations[node.ProviderID()]; !reserved && isDisrupting(node) {
+			budgets[nodePool].consume(usageOf(node))
+		}
+	}
+	for _, r := range q.reservations {
+		if budget, ok := budgets[r.nodePool]; ok {
+			budget.consume(r.usage)
+		}
+	}
+	for _, c := range cmd.Candidates {
+		budget, ok := budgets[c.NodePool.Name]
+		if !ok {
+			continue
+		}
+		if !budget.allows(usageOf(c.StateNode)) {
+			return fmt.Errorf("disruption budget for nodepool %q is exceeded", c.NodePool.Name)
+		}
+		budget.consume(usageOf(c.StateNode))
+	}
+	for _, c := range cmd.Candidates {
+		q.reservations[c.ProviderID()] = reservation{nodePool: c.NodePool.Name, usage: usageOf(c.StateNode)}
+	}
+	return nil
+}
+
+func (q *Queue) releaseBudgets(providerIDs ...string) {
+	q.budgetMu.Lock()
+	defer q.budgetMu.Unlock()
+	for _, id := range providerIDs {
+		delete(q.reservations, id)
+	}
+}
@@ -48,6 +48,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/state/cost"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
@@ -65,11 +66,19 @@ type Controller struct {
 	methods       []Method
 	mu            sync.Mutex
 	lastRun       map[string]time.Time
+	cm            *pretty.ChangeMonitor
+	// lastSurgeRevert is when orphaned surges were last reverted
+	lastSurgeRevert time.Time
 }
 
 // pollingPeriod that we inspect cluster to look for opportunities to disrupt
 const pollingPeriod = 10 * time.Second
 
+// orphanedSurgePeriod is how often surges whose command was lost are reverted. Commands are only lost when Karpenter
+// restarts, so the surges are reverted on the first loop and then only checked occasionally, since it lists every
+// Deployment in the cluster.
+const orphanedSurgePeriod = 10 * time.Minute
+
 type ControllerOptions struct {
 	methods []Method
 }
@@ -95,6 +104,7 @@ func NewController(clk clock.Clock, kubeClient client.Client, provisioner *provi
 		clusterCost:   clusterCost,
 		lastRun:       map[string]time.Time{},
 		methods:       o.methods,
+		cm:            pretty.NewChangeMonitor(),
 	}
 }
 
@@ -162,6 +172,13 @@ func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
 		}
 		return reconciler.Result{}, serrors.Wrap(fmt.Errorf("removing condition from nodeclaims, %w", err), "condition", v1.ConditionTypeDisruptionReason)
 	}
+	// Surged Deployments are scaled back down by the command that surged them. Revert the surges whose command was lost.
+	if c.lastSurgeRevert.IsZero() || c.clock.Since(c.lastSurgeRevert) >= orphanedSurgePeriod {
+		if err := c.queue.RevertOrphanedSurges(ctx); err != nil {
+			return reconciler.Result{}, fmt.Errorf("reverting orphaned surges, %w", err)
+		}
+		c.lastSurgeRevert = c.clock.Now()
+	}
 
 	// Attempt different disruption methods. We'll only let one method perform an action
 	for _, m := range c.methods {
@@ -187,54 +204,121 @@ func (c *Controller) disrupt(ctx context.Context, disruption Method) (bool, erro
 		metrics.ReasonLabel:    strings.ToLower(string(disruption.Reason())),
 		ConsolidationTypeLabel: disruption.ConsolidationType(),
 	})()
+	// Commands that are computed but not started, e.g. because they failed validation, won't be executed
+	defer c.queue.plans.abandonPending()
+	cmds, err := c.ComputeCommands(ctx, disruption)
+	if err != nil {
+		return false, err
+	}
+	if len(cmds) == 0 {
+		return false, nil
+	}
+
+	errs := make([]error, len(cmds))
+	started := make([]bool, len(cmds))
+	workqueue.ParallelizeUntil(ctx, len(cmds), len(cmds), func(i int) {
+		cmd := cmds[i]
+
+		// Assign common fields. Commands that were validated were assigned their ID when their plan was published.
+		cmd.CreationTimestamp = c.clock.Now()
+		if cmd.ID == uuid.Nil {
+			cmd.ID = uuid.New()
+		}
+		cmd.Method = disruption
+
+		cmd, dryRunCmd := partitionDryRun(ctx, cmd)
+		if len(dryRunCmd.Candidates) > 0 {
+			c.reportDryRun(ctx, dryRunCmd)
+		}
+		if len(cmd.Candidates) == 0 {
+			return
+		}
+		// Attempt to disrupt
+		if err := c.queue.StartCommand(ctx, &cmd); err != nil {
+			errs[i] = fmt.Errorf("disrupting candidates, %w", err)
+			return
+		}
+		started[i] = true
+	})
+	if err = multierr.Combine(errs...); err != nil {
+		return false, fmt.Errorf("disrupting candidates, %w", err)
+	}
+	// Dry-run commands don't disrupt anything, so only consider the method successful if it started a command.
+	// Otherwise, a method that keeps producing dry-run commands would prevent the remaining methods from running.
+	return lo.Contains(started, true), nil
+}
+
+// ComputeCommands determines the commands that the disruption method would issue against the current cluster state,
+// limited to the candidates' maintenance windows and disruption budgets. The commands aren't started.
+func (c *Controller) ComputeCommands(ctx context.Context, disruption Method) ([]Command, error) {
 	candidates, nodePoolTotals, err := GetCandidatesWithTotals(ctx, c.cluster, c.kubeClient, c.recorder, c.clock, c.cloudProvider, disruption.ShouldDisrupt, disruption.Class(), c.queue, c.clusterCost)
 	if err != nil {
-		return false, fmt.Errorf("determining candidates, %w", err)
+		return nil, fmt.Errorf("determining candidates, %w", err)
 	}
+	candidates = filterByMaintenanceWindows(ctx, c.clock, candidates)
 	EligibleNodes.Set(float64(len(candidates)), map[string]string{
 		metrics.ReasonLabel: strings.ToLower(string(disruption.Reason())),
 	})
 
 	// If there are no candidates, move to the next disruption
 	if len(candidates) == 0 {
-		return false, nil
+		return nil, nil
 	}
 	// Pass precomputed NodePool totals to consolidation methods for balanced scoring
 	if setter, ok := disruption.(NodePoolTotalsSetter); ok {
 		setter.SetNodePoolTotals(nodePoolTotals)
 	}
-	disruptionBudgetMapping, err := BuildDisruptionBudgetMapping(ctx, c.cluster, c.clock, c.kubeClient, c.cloudProvider, c.recorder, disruption.Reason())
+	budgets, err := BuildDisruptionBudgets(ctx, c.cluster, c.clock, c.kubeClient, c.cloudProvider, c.recorder, disruption.Reason())
 	if err != nil {
-		return false, fmt.Errorf("building disruption budgets, %w", err)
+		return nil, fmt.Errorf("building disruption budgets, %w", err)
 	}
+	disruptionBudgetMapping := lo.MapValues(budgets, func(b *DisruptionBudget, _ string) int { return b.Nodes })
 	// Determine the disruption action
 	cmds, err := disruption.ComputeCommands(ctx, disruptionBudgetMapping, candidates...)
 	if err != nil {
-		return false, fmt.Errorf("computing disruption decision, %w", err)
+		return nil, fmt.Errorf("computing disruption decision, %w", err)
 	}
 	cmds = lo.Filter(cmds, func(c Command, _ int) bool { return c.Decision() != NoOpDecision })
-	if len(cmds) == 0 {
-		return false, nil
-	}
-
-	errs := make([]error, len(cmds))
-	workqueue.ParallelizeUntil(ctx, len(cmds), len(cmds), func(i int) {
-		cmd := cmds[i]
-
-		// Assign common fields
-		cmd.CreationTimestamp = c.clock.Now()
-		cmd.ID = uuid.New()
-		cmd.Method = disruption
+	// Commands that launch replacements can take up to the queue's retry duration to finish, so only start them if
+	// the maintenance windows of their candidates stay open at least that long.
+	cmds = limitToMaintenanceWindows(ctx, c.clock, cmds, c.queue.GetMaxRetryDuration())
+	return limitToBudgets(budgets, cmds), nil
+}
 
-		// Attempt to disrupt
-		if err := c.queue.StartCommand(ctx, &cmd); err != nil {
-			errs[i] = fmt.Errorf("disrupting candidates, %w", err)
-		}
+// partitionDryRun splits a command into the part that should be executed and the part that should only be reported
+// because its NodePools are in dry-run mode. Deleting a subset of the candidates of a valid delete command is still
+// valid, but replacements are computed for the command as a whole, so a command that launches replacements is
+// reported entirely if any of its NodePools is in dry-run mode.
+func partitionDryRun(ctx context.Context, cmd Command) (Command, Command) {
+	if options.FromContext(ctx).DisruptionDryRun {
+		return Command{}, cmd
+	}
+	dryRun, execute := lo.FilterReject(cmd.Candidates, func(c *Candidate, _ int) bool {
+		return c.NodePool.Annotations[v1.DisruptionDryRunAnnotationKey] == "true"
 	})
-	if err = multierr.Combine(errs...); err != nil {
-		return false, fmt.Errorf("disrupting candidates, %w", err)
+	if len(dryRun) == 0 {
+		return cmd, Command{}
+	}
+	if len(execute) == 0 || len(cmd.Replacements) > 0 {
+		return Command{}, cmd
+	}
+	executeCmd, dryRunCmd := cmd, cmd
+	executeCmd.Candidates, executeCmd.PoolDisruptionCosts = execute, computePoolDisruptionCosts(execute)
+	dryRunCmd.Candidates, dryRunCmd.PoolDisruptionCosts = dryRun, computePoolDisruptionCosts(dryRun)
+	return executeCmd, dryRunCmd
+}
+
+func (c *Controller) reportDryRun(ctx context.Context, cmd Command) {
+	cmd.EmitDryRunEvents(c.recorder)
+	// The same command is usually computed on every loop, so only log it when the decision changes
+	if !c.cm.HasChanged(fmt.Sprintf("dry-run/%s/%s", cmd.Reason(), strings.Join(cmd.SourceNodeNames(), ",")), []any{cmd.Decision(), len(cmd.Replacements), cmd.EstimatedSavings()}) {
+		return
 	}
-	return true, nil
+	log.FromContext(ctx).WithValues(append([]any{
+		"command", cmd.String(),
+		"reason", cmd.Reason(),
+		"estimated-savings", cmd.EstimatedSavings(),
+	}, cmd.LogValues()...)...).Info("skipping disruption in dry-run mode")
 }
 
 func (c *Controller) recordRun(s string) {
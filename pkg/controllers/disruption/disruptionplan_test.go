@@ -0,0 +1,272 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption_test
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var _ = Describe("DisruptionPlan", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+	var planController *disruption.PlanController
+
+	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{DisruptionPlan: lo.ToPtr(true)}}))
+		planController = disruption.NewPlanController(env.Clock, env.Client, queue)
+		nodePool = test.NodePool()
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+					v1.CapacityTypeLabelKey:        cloudProvider.InstanceTypes[0].Offerings.Cheapest().Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       cloudProvider.InstanceTypes[0].Offerings.Cheapest().Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+			Status: v1.NodeClaimStatus{
+				ProviderID:  test.RandomProviderID(),
+				Allocatable: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: resource.MustParse("32")},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+	})
+
+	replaceCommand := func() *disruption.Command {
+		nct := scheduling.NewNodeClaimTemplate(nodePool)
+		nct.InstanceTypeOptions = append([]*cloudprovider.InstanceType{}, cloudProvider.InstanceTypes...)
+		return &disruption.Command{
+			Method:            disruption.NewDrift(env.Client, cluster, prov, recorder, env.Clock),
+			CreationTimestamp: env.Clock.Now(),
+			ID:                uuid.New(),
+			Results:           scheduling.Results{},
+			Candidates:        []*disruption.Candidate{{StateNode: ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim), NodePool: nodePool}},
+			Replacements:      []*disruption.Replacement{{NodeClaim: &scheduling.NodeClaim{NodeClaimTemplate: *nct}}},
+		}
+	}
+	expectPlan := func(cmd *disruption.Command) *v1alpha1.DisruptionPlan {
+		GinkgoHelper()
+		return ExpectExists(ctx, env.Client, &v1alpha1.DisruptionPlan{ObjectMeta: metav1.ObjectMeta{Name: cmd.ID.String()}})
+	}
+	initializeReplacement := func(cmd *disruption.Command) {
+		GinkgoHelper()
+		replacementNodeClaim := &v1.NodeClaim{}
+		Expect(env.Client.Get(ctx, types.NamespacedName{Name: cmd.Replacements[0].Name}, replacementNodeClaim)).To(Succeed())
+		replacementNodeClaim, replacementNode := ExpectNodeClaimDeployedAndStateUpdated(ctx, env.Client, cluster, cloudProvider, replacementNodeClaim)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController,
+			[]*corev1.Node{replacementNode}, []*v1.NodeClaim{replacementNodeClaim})
+	}
+
+	It("should not publish plans when the feature gate is disabled", func() {
+		ctx = options.ToContext(ctx, test.Options())
+		Expect(queue.StartCommand(ctx, replaceCommand())).To(Succeed())
+		ExpectSingletonReconciled(ctx, planController)
+		plans := &v1alpha1.DisruptionPlanList{}
+		Expect(env.Client.List(ctx, plans)).To(Succeed())
+		Expect(plans.Items).To(BeEmpty())
+	})
+	It("should publish a plan when a command starts", func() {
+		cmd := replaceCommand()
+		Expect(queue.StartCommand(ctx, cmd)).To(Succeed())
+		// Plans are written by the plan controller rather than while the command starts
+		plans := &v1alpha1.DisruptionPlanList{}
+		Expect(env.Client.List(ctx, plans)).To(Succeed())
+		Expect(plans.Items).To(BeEmpty())
+		ExpectSingletonReconciled(ctx, planController)
+
+		plan := expectPlan(cmd)
+		Expect(plan.Spec.Reason).To(Equal(string(v1.DisruptionReasonDrifted)))
+		Expect(plan.Spec.Decision).To(Equal(string(disruption.ReplaceDecision)))
+		Expect(plan.Spec.NodePools).To(ConsistOf(nodePool.Name))
+		Expect(plan.Spec.Candidates).To(HaveLen(1))
+		Expect(plan.Spec.Candidates[0].NodeClaim).To(Equal(nodeClaim.Name))
+		Expect(plan.Spec.Candidates[0].Node).To(Equal(node.Name))
+		Expect(plan.Spec.Candidates[0].InstanceType).To(Equal(cloudProvider.InstanceTypes[0].Name))
+		Expect(plan.Spec.Replacements).To(HaveLen(1))
+		Expect(plan.Spec.Replacements[0].NodeClaim).To(Equal(cmd.Replacements[0].Name))
+		Expect(plan.Spec.Replacements[0].InstanceTypes).To(HaveLen(5))
+		Expect(plan.Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhaseWaitingForReplacements))
+		Expect(plan.Status.StartTime).ToNot(BeNil())
+	})
+	It("should move the plan to Draining once the candidates are deleted", func() {
+		cmd := replaceCommand()
+		Expect(queue.StartCommand(ctx, cmd)).To(Succeed())
+		ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+		ExpectSingletonReconciled(ctx, planController)
+		Expect(expectPlan(cmd).Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhaseWaitingForReplacements))
+
+		initializeReplacement(cmd)
+		ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+		ExpectSingletonReconciled(ctx, planController)
+		Expect(expectPlan(cmd).Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhaseDraining))
+	})
+	It("should mark the plan as Failed with the failure cause when the command times out", func() {
+		cmd := replaceCommand()
+		Expect(queue.StartCommand(ctx, cmd)).To(Succeed())
+		env.Clock.Step(11 * time.Minute)
+		ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+		ExpectSingletonReconciled(ctx, planController)
+
+		plan := expectPlan(cmd)
+		Expect(plan.Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhaseFailed))
+		Expect(plan.Status.FailureReason).To(ContainSubstring("command reached timeout"))
+		Expect(plan.Status.CompletionTime).ToNot(BeNil())
+	})
+	It("should mark the plan as Succeeded once the candidates are gone and garbage collect it later", func() {
+		nodeClaim.Finalizers = []string{v1.TerminationFinalizer}
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		cmd := replaceCommand()
+		Expect(queue.StartCommand(ctx, cmd)).To(Succeed())
+		initializeReplacement(cmd)
+		ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+		Expect(queue.HasCommand(cmd.ID.String())).To(BeFalse())
+
+		// The candidate is still draining
+		env.Clock.Step(time.Minute)
+		ExpectSingletonReconciled(ctx, planController)
+		Expect(expectPlan(cmd).Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhaseDraining))
+
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectSingletonReconciled(ctx, planController)
+		plan := expectPlan(cmd)
+		Expect(plan.Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhaseSucceeded))
+		Expect(plan.Status.CompletionTime).ToNot(BeNil())
+
+		env.Clock.Step(2 * time.Hour)
+		ExpectSingletonReconciled(ctx, planController)
+		ExpectNotFound(ctx, env.Client, plan)
+	})
+	It("should mark the plan as Failed when its command is no longer executing", func() {
+		cmd := replaceCommand()
+		Expect(queue.StartCommand(ctx, cmd)).To(Succeed())
+		ExpectSingletonReconciled(ctx, planController)
+		// Simulate a restart dropping the command from the queue
+		*queue = lo.FromPtr(disruption.NewQueue(env.Client, recorder, cluster, env.Clock, prov))
+
+		env.Clock.Step(time.Minute)
+		ExpectSingletonReconciled(ctx, planController)
+		plan := expectPlan(cmd)
+		Expect(plan.Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhaseFailed))
+		Expect(plan.Status.FailureReason).To(Equal("command is no longer being executed"))
+	})
+	It("should not update plans for commands that are still executing", func() {
+		cmd := replaceCommand()
+		Expect(queue.StartCommand(ctx, cmd)).To(Succeed())
+
+		env.Clock.Step(time.Minute)
+		ExpectSingletonReconciled(ctx, planController)
+		Expect(expectPlan(cmd).Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhaseWaitingForReplacements))
+	})
+	Context("Pending", func() {
+		var disruptionController *disruption.Controller
+
+		BeforeEach(func() {
+			nodePool.Spec.Disruption.ConsolidateAfter = v1.MustParseNillableDuration("0s")
+			nodePool.Spec.Disruption.ConsolidationPolicy = v1.ConsolidationPolicyWhenEmpty
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			disruptionController = disruption.NewController(env.Clock, env.Client, prov, cloudProvider, recorder, cluster, queue, clusterCost, disruption.WithMethods(NewMethodsWithRealValidator()...))
+		})
+
+		// disruptWhileValidating runs the disruption controller and calls duringValidation with the command's plan
+		// while the controller waits to validate the command
+		disruptWhileValidating := func(duringValidation func(plan *v1alpha1.DisruptionPlan)) {
+			GinkgoHelper()
+			finished := atomic.Bool{}
+			ExpectParallelized(
+				func() {
+					defer finished.Store(true)
+					ExpectSingletonReconciled(ctx, disruptionController)
+				},
+				func() {
+					Eventually(env.Clock.HasWaiters, time.Second*10).Should(BeTrue())
+					ExpectSingletonReconciled(ctx, planController)
+					plans := &v1alpha1.DisruptionPlanList{}
+					Expect(env.Client.List(ctx, plans)).To(Succeed())
+					Expect(plans.Items).To(HaveLen(1))
+					duringValidation(&plans.Items[0])
+					env.Clock.Step(31 * time.Second)
+					Eventually(finished.Load, 10*time.Second).Should(BeTrue())
+				},
+			)
+		}
+
+		It("should publish a plan while the command is waiting to be validated", func() {
+			var pending *v1alpha1.DisruptionPlan
+			disruptWhileValidating(func(plan *v1alpha1.DisruptionPlan) {
+				pending = plan
+				Expect(plan.Spec.Reason).To(Equal(string(v1.DisruptionReasonEmpty)))
+				Expect(plan.Spec.Candidates).To(HaveLen(1))
+				Expect(plan.Spec.Candidates[0].NodeClaim).To(Equal(nodeClaim.Name))
+				Expect(plan.Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhasePending))
+				Expect(plan.Status.StartTime).To(BeNil())
+			})
+			Expect(queue.HasCommand(pending.Name)).To(BeTrue())
+
+			ExpectSingletonReconciled(ctx, planController)
+			plan := ExpectExists(ctx, env.Client, pending)
+			Expect(plan.Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhaseWaitingForReplacements))
+			Expect(plan.Status.StartTime).ToNot(BeNil())
+		})
+		It("should delete the plan when the command fails validation", func() {
+			var pending *v1alpha1.DisruptionPlan
+			disruptWhileValidating(func(plan *v1alpha1.DisruptionPlan) {
+				pending = plan
+				node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DoNotDisruptAnnotationKey: "true"})
+				ExpectApplied(ctx, env.Client, node)
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+			})
+			Expect(queue.HasCommand(pending.Name)).To(BeFalse())
+
+			ExpectSingletonReconciled(ctx, planController)
+			ExpectNotFound(ctx, env.Client, pending)
+		})
+		It("should delete pending plans left behind by a restart", func() {
+			var pending *v1alpha1.DisruptionPlan
+			disruptWhileValidating(func(plan *v1alpha1.DisruptionPlan) { pending = plan })
+			Expect(ExpectExists(ctx, env.Client, pending).Status.Phase).To(Equal(v1alpha1.DisruptionPlanPhasePending))
+
+			// A restarted controller doesn't know about the pending command
+			restartedPlanController := disruption.NewPlanController(env.Clock, env.Client, disruption.NewQueue(env.Client, recorder, cluster, env.Clock, prov))
+			ExpectSingletonReconciled(ctx, restartedPlanController)
+			ExpectNotFound(ctx, env.Client, pending)
+		})
+	})
+})
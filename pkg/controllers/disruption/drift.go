@@ -21,6 +21,7 @@ import (
 	"errors"
 	"slices"
 	"sort"
+	"time"
 
 	"github.com/samber/lo"
 	"k8s.io/utils/clock"
@@ -29,10 +30,13 @@ import (
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	pscheduling "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 )
 
 // Drift is a subreconciler that deletes drifted candidates.
@@ -61,10 +65,7 @@ func (d *Drift) ShouldDisrupt(ctx context.Context, c *Candidate) bool {
 
 // ComputeCommand generates a disruption command given candidates
 func (d *Drift) ComputeCommands(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) ([]Command, error) {
-	sort.Slice(candidates, func(i int, j int) bool {
-		return candidates[i].NodeClaim.StatusConditions().Get(string(d.Reason())).LastTransitionTime.Time.Before(
-			candidates[j].NodeClaim.StatusConditions().Get(string(d.Reason())).LastTransitionTime.Time)
-	})
+	d.sortCandidates(ctx, candidates)
 
 	emptyCandidates, nonEmptyCandidates := lo.FilterReject(candidates, func(c *Candidate, _ int) bool {
 		return len(c.reschedulablePods) == 0
@@ -95,6 +96,10 @@ func (d *Drift) ComputeCommands(ctx context.Context, disruptionBudgetMapping map
 			continue
 		}
 
+		if options.FromContext(ctx).DriftCheaperReplacements {
+			preferCheaperReplacement(candidate, results)
+		}
+
 		cmd := Command{
 			Candidates:          []*Candidate{candidate},
 			Replacements:        replacementsFromNodeClaims(results.NewNodeClaims...),
@@ -107,6 +112,41 @@ func (d *Drift) ComputeCommands(ctx context.Context, disruptionBudgetMapping map
 	return []Command{}, nil
 }
 
+// sortCandidates orders the candidates by the configured drift ordering, falling back to the candidates that drifted
+// first to break ties
+func (d *Drift) sortCandidates(ctx context.Context, candidates []*Candidate) {
+	driftedAt := func(c *Candidate) time.Time {
+		return c.NodeClaim.StatusConditions().Get(string(d.Reason())).LastTransitionTime.Time
+	}
+	ordering := options.FromContext(ctx).DriftOrdering
+	sort.SliceStable(candidates, func(i int, j int) bool {
+		if ordering == options.DriftOrderingDisruptionCost && candidates[i].RescheduleDisruptionCost != candidates[j].RescheduleDisruptionCost {
+			return candidates[i].RescheduleDisruptionCost < candidates[j].RescheduleDisruptionCost
+		}
+		return driftedAt(candidates[i]).Before(driftedAt(candidates[j]))
+	})
+}
+
+// preferCheaperReplacement restricts a drift replacement to the instance types that are cheaper than the candidate.
+// Unlike consolidation, drift has to replace the candidate even if it can't save money, so the replacement is left
+// alone if there are no cheaper instance types or too few of them to satisfy minValues.
+func preferCheaperReplacement(candidate *Candidate, results pscheduling.Results) {
+	if len(results.NewNodeClaims) != 1 || candidate.Price == 0 {
+		return
+	}
+	nodeClaim := results.NewNodeClaims[0]
+	cheaper := lo.Filter(nodeClaim.InstanceTypeOptions.OrderByPrice(nodeClaim.Requirements), func(it *cloudprovider.InstanceType, _ int) bool {
+		return it.Offerings.Available().WorstLaunchPrice(nodeClaim.Requirements) < candidate.Price
+	})
+	if len(cheaper) == 0 {
+		return
+	}
+	if _, _, err := cheaper.SatisfiesMinValues(nodeClaim.Requirements); err != nil {
+		return
+	}
+	nodeClaim.InstanceTypeOptions = cheaper
+}
+
 func (d *Drift) Reason() v1.DisruptionReason {
 	return v1.DisruptionReasonDrifted
 }
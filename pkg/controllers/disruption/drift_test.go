@@ -17,6 +17,7 @@ limitations under the License.
 package disruption_test
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
@@ -35,6 +36,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
@@ -104,6 +106,75 @@ var _ = Describe("Drift", func() {
 			ExpectMetricGaugeValue(disruption.EligibleNodes, 1, eligibleNodesLabels)
 		})
 	})
+	Context("Maintenance Windows", func() {
+		var eligibleNodesLabels = map[string]string{
+			metrics.ReasonLabel: "drifted",
+		}
+		// windowAt returns a daily maintenance window that opens at the given time
+		windowAt := func(t time.Time, duration time.Duration) *v1.MaintenanceWindows {
+			t = t.UTC()
+			return &v1.MaintenanceWindows{Windows: []v1.MaintenanceWindow{{
+				Schedule: fmt.Sprintf("%d %d * * *", t.Minute(), t.Hour()),
+				Duration: metav1.Duration{Duration: duration},
+			}}}
+		}
+		BeforeEach(func() {
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		})
+		It("should not consider nodes for drift outside of maintenance windows", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = windowAt(env.Clock.Now().Add(3*time.Hour), time.Hour)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectMetricGaugeValue(disruption.EligibleNodes, 0, eligibleNodesLabels)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeTrue())
+		})
+		It("should not replace drifted nodes when the window closes before replacements could initialize", func() {
+			// The window closes in 5 minutes, but commands that launch replacements can take up to the queue's retry duration
+			nodePool.Spec.Disruption.MaintenanceWindows = windowAt(env.Clock.Now().Add(-55*time.Minute), time.Hour)
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         new(true),
+							BlockOwnerDeletion: new(true),
+						},
+					}}})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectMetricGaugeValue(disruption.EligibleNodes, 1, eligibleNodesLabels)
+			Expect(queue.GetCommands()).To(BeEmpty())
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeTrue())
+		})
+		It("should delete empty drifted nodes when the window closes soon", func() {
+			// Deleting an empty node doesn't launch replacements, so it only needs the window to be open
+			nodePool.Spec.Disruption.MaintenanceWindows = windowAt(env.Clock.Now().Add(-55*time.Minute), time.Hour)
+			nodePool.Spec.Disruption.ConsolidateAfter = v1.MustParseNillableDuration("Never")
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
+		It("should consider nodes for drift during an open maintenance window", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = windowAt(env.Clock.Now(), 2*time.Hour)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectMetricGaugeValue(disruption.EligibleNodes, 1, eligibleNodesLabels)
+		})
+	})
 	Context("Budgets", func() {
 		var numNodes = 10
 		var nodeClaims []*v1.NodeClaim
@@ -1123,6 +1194,98 @@ var _ = Describe("Drift", func() {
 			ExpectExists(ctx, env.Client, nodeClaim)
 			ExpectExists(ctx, env.Client, node)
 		})
+		It("should drift the node that is cheapest to disrupt first when ordering by disruption cost", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DriftOrdering: lo.ToPtr(options.DriftOrderingDisruptionCost)}))
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			pods := test.Pods(3, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         new(true),
+							BlockOwnerDeletion: new(true),
+						},
+					},
+				},
+			})
+			nodeClaim2, node2 := test.NodeClaimAndNode(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					ProviderID:  test.RandomProviderID(),
+					Allocatable: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: resource.MustParse("32")},
+				},
+			})
+			// nodeClaim2 drifted first but has more pods to reschedule
+			nodeClaim2.Status.Conditions = append(nodeClaim2.Status.Conditions, status.Condition{
+				Type:               v1.ConditionTypeDrifted,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1.ConditionTypeDrifted,
+				Message:            v1.ConditionTypeDrifted,
+				LastTransitionTime: metav1.Time{Time: time.Now().Add(-time.Hour)},
+			})
+			ExpectApplied(ctx, env.Client, pods[0], pods[1], pods[2], nodeClaim, node, nodeClaim2, node2, nodePool)
+			ExpectManualBinding(ctx, env.Client, pods[0], node)
+			ExpectManualBinding(ctx, env.Client, pods[1], node2)
+			ExpectManualBinding(ctx, env.Client, pods[2], node2)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Candidates).To(HaveLen(1))
+			Expect(cmds[0].Candidates[0].NodeClaim.Name).To(Equal(nodeClaim.Name))
+		})
+		DescribeTable("should restrict replacements to cheaper instance types when enabled",
+			func(cheaperReplacements bool) {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DriftCheaperReplacements: lo.ToPtr(cheaperReplacements)}))
+				rs := test.ReplicaSet()
+				ExpectApplied(ctx, env.Client, rs)
+				pod := test.Pod(test.PodOptions{
+					ObjectMeta: metav1.ObjectMeta{
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion:         "apps/v1",
+								Kind:               "ReplicaSet",
+								Name:               rs.Name,
+								UID:                rs.UID,
+								Controller:         new(true),
+								BlockOwnerDeletion: new(true),
+							},
+						},
+					},
+				})
+				ExpectApplied(ctx, env.Client, pod, nodeClaim, node, nodePool)
+				ExpectManualBinding(ctx, env.Client, pod, node)
+
+				// inform cluster state about nodes and nodeclaims
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+				ExpectSingletonReconciled(ctx, disruptionController)
+
+				cmds := queue.GetCommands()
+				Expect(cmds).To(HaveLen(1))
+				Expect(cmds[0].Replacements).To(HaveLen(1))
+				replacement := cmds[0].Replacements[0]
+				cheaper := lo.EveryBy(replacement.InstanceTypeOptions, func(it *cloudprovider.InstanceType) bool {
+					return it.Offerings.Available().WorstLaunchPrice(replacement.Requirements) < cmds[0].Candidates[0].Price
+				})
+				Expect(cheaper).To(Equal(cheaperReplacements))
+			},
+			Entry("enabled", true),
+			Entry("disabled", false),
+		)
 	})
 
 	Context("Static NodePool", func() {
@@ -0,0 +1,121 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var _ = Describe("Dry Run", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Disruption: v1.Disruption{
+					ConsolidateAfter:    v1.MustParseNillableDuration("0s"),
+					ConsolidationPolicy: v1.ConsolidationPolicyWhenEmpty,
+					Budgets: []v1.Budget{{
+						Nodes: "100%",
+					}},
+				},
+			},
+		})
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: leastExpensiveInstance.Name,
+					v1.CapacityTypeLabelKey:        leastExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       leastExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+			Status: v1.NodeClaimStatus{
+				Allocatable: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU:  resource.MustParse("32"),
+					corev1.ResourcePods: resource.MustParse("100"),
+				},
+			},
+		})
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
+	})
+	It("should report but not start commands when dry-run is enabled cluster-wide", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionDryRun: lo.ToPtr(true)}))
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		ExpectSingletonReconciled(ctx, disruptionController)
+
+		Expect(queue.GetCommands()).To(BeEmpty())
+		Expect(recorder.Calls(events.DisruptionDryRun)).To(Equal(2))
+		node = ExpectExists(ctx, env.Client, node)
+		Expect(node.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+	It("should report but not start commands for a NodePool in dry-run mode", func() {
+		nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{v1.DisruptionDryRunAnnotationKey: "true"})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		ExpectSingletonReconciled(ctx, disruptionController)
+
+		Expect(queue.GetCommands()).To(BeEmpty())
+		Expect(recorder.Calls(events.DisruptionDryRun)).To(Equal(2))
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+	It("should start commands for NodePools that aren't in dry-run mode", func() {
+		dryRunNodePool := test.NodePool(v1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1.DisruptionDryRunAnnotationKey: "true"},
+			},
+			Spec: nodePool.Spec,
+		})
+		dryRunNodeClaim, dryRunNode := test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: lo.Assign(nodeClaim.Labels, map[string]string{v1.NodePoolLabelKey: dryRunNodePool.Name}),
+			},
+			Status: nodeClaim.Status,
+		})
+		dryRunNodeClaim.StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
+		ExpectApplied(ctx, env.Client, nodePool, dryRunNodePool, nodeClaim, node, dryRunNodeClaim, dryRunNode)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node, dryRunNode}, []*v1.NodeClaim{nodeClaim, dryRunNodeClaim})
+		ExpectSingletonReconciled(ctx, disruptionController)
+
+		cmds := queue.GetCommands()
+		Expect(cmds).To(HaveLen(1))
+		Expect(cmds[0].Candidates).To(HaveLen(1))
+		Expect(cmds[0].Candidates[0].NodeClaim.Name).To(Equal(nodeClaim.Name))
+		Expect(recorder.Calls(events.DisruptionDryRun)).To(Equal(2))
+	})
+})
@@ -67,6 +67,28 @@ func Terminating(node *corev1.Node, nodeClaim *v1.NodeClaim, reason string) []ev
 	}
 }
 
+// DryRun is an event that informs the user that a NodeClaim/Node combination would have been disrupted, but
+// wasn't because disruption is in dry-run mode
+func DryRun(node *corev1.Node, nodeClaim *v1.NodeClaim, command string, reason string, savings float64) []events.Event {
+	message := fmt.Sprintf("Dry-run, would disrupt: %s (savings: $%.2f)", command, savings)
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           corev1.EventTypeNormal,
+			Reason:         events.DisruptionDryRun,
+			Message:        message,
+			DedupeValues:   []string{string(node.UID), reason},
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           corev1.EventTypeNormal,
+			Reason:         events.DisruptionDryRun,
+			Message:        message,
+			DedupeValues:   []string{string(nodeClaim.UID), reason},
+		},
+	}
+}
+
 // Unconsolidatable is an event that informs the user that a NodeClaim/Node combination cannot be consolidated
 // due to the state of the NodeClaim/Node or due to some state of the pods that are scheduled to the NodeClaim/Node
 func Unconsolidatable(node *corev1.Node, nodeClaim *v1.NodeClaim, msg string) []events.Event {
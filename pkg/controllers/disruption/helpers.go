@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
@@ -100,6 +101,7 @@ func SimulateScheduling(ctx context.Context, kubeClient client.Client, cluster *
 		return scheduling.Results{}, fmt.Errorf("failed to get pods from deleting nodes, %w", err)
 	}
 	pods = append(pods, deletingNodePods...)
+	pods = provisioner.ApplyPredictions(ctx, pods)
 
 	var opts []scheduling.Options
 	if options.FromContext(ctx).PreferencePolicy == options.PreferencePolicyIgnore {
@@ -255,50 +257,46 @@ func BuildNodePoolMap(ctx context.Context, kubeClient client.Client, cloudProvid
 	return nodePoolMap, nodePoolToInstanceTypesMap, nil
 }
 
-// BuildDisruptionBudgets prepares our disruption budget mapping. The disruption budget maps each disruption reason to the number of allowed disruptions.
+// BuildDisruptionBudgetMapping prepares our disruption budget mapping. The disruption budget maps each disruption reason to the number of allowed disruptions.
 // We calculate allowed disruptions by taking the max disruptions allowed by disruption reason and subtracting the number of nodes that are NotReady and already being deleted by that disruption reason.
-//
-//nolint:gocyclo
+// NodePools whose pod or resource budgets are exhausted aren't allowed any disruptions.
 func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder, reason v1.DisruptionReason) (map[string]int, error) {
-	disruptionBudgetMapping := map[string]int{}
-	numNodes := map[string]int{}   // map[nodepool] -> node count in nodepool
-	disrupting := map[string]int{} // map[nodepool] -> nodes undergoing disruption
-	for _, node := range cluster.DeepCopyNodes() {
-		// We only consider nodes that we own and are initialized towards the total.
-		// If a node is launched/registered, but not initialized, pods aren't scheduled
-		// to the node, and these are treated as unhealthy until they're cleaned up.
-		// This prevents odd roundup cases with percentages where replacement nodes that
-		// aren't initialized could be counted towards the total, resulting in more disruptions
-		// to active nodes than desired, where Karpenter should wait for these nodes to be
-		// healthy before continuing.
-		if !node.Managed() || !node.Initialized() {
-			continue
-		}
+	budgets, err := BuildDisruptionBudgets(ctx, cluster, clk, kubeClient, cloudProvider, recorder, reason)
+	return lo.MapValues(budgets, func(b *DisruptionBudget, _ string) int { return b.Nodes }), err
+}
 
-		// Additionally, don't consider nodeclaims that have the terminating condition. A nodeclaim should have
-		// the Terminating condition only when the node is drained and cloudprovider.Delete() was successful
-		// on the underlying cloud provider machine.
-		if node.NodeClaim.StatusConditions().Get(v1.ConditionTypeInstanceTerminating).IsTrue() {
+// BuildDisruptionBudgets calculates the remaining node, pod, and resource disruption budgets of each NodePool for the disruption reason.
+func BuildDisruptionBudgets(ctx context.Context, cluster *state.Cluster, clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder, reason v1.DisruptionReason) (map[string]*DisruptionBudget, error) {
+	budgets := map[string]*DisruptionBudget{}
+	numNodes := map[string]int{}            // map[nodepool] -> node count in nodepool
+	disrupting := map[string]int{}          // map[nodepool] -> nodes undergoing disruption
+	usage := map[string][]disruptionUsage{} // map[nodepool] -> pods and resources undergoing disruption
+	for _, node := range cluster.DeepCopyNodes() {
+		nodePool, ok := budgetedNodePool(node)
+		if !ok {
 			continue
 		}
-
-		nodePool := node.Labels()[v1.NodePoolLabelKey]
 		numNodes[nodePool]++
-
-		// If the node satisfies one of the following, we subtract it from the allowed disruptions.
-		// 1. Has a NotReady conditiion
-		// 2. Is marked as disrupting
-		if cond := nodeutils.GetCondition(node.Node, corev1.NodeReady); cond.Status != corev1.ConditionTrue || node.MarkedForDeletion() {
+		if isDisrupting(node) {
 			disrupting[nodePool]++
+			usage[nodePool] = append(usage[nodePool], usageOf(node))
 		}
 	}
 	nodePools, err := nodepoolutils.ListManaged(ctx, kubeClient, cloudProvider)
 	if err != nil {
-		return disruptionBudgetMapping, fmt.Errorf("listing node pools, %w", err)
+		return budgets, fmt.Errorf("listing node pools, %w", err)
 	}
 	for _, nodePool := range nodePools {
 		allowedDisruptions := nodePool.MustGetAllowedDisruptions(clk, numNodes[nodePool.Name], reason)
-		disruptionBudgetMapping[nodePool.Name] = lo.Max([]int{allowedDisruptions - disrupting[nodePool.Name], 0})
+		budget := newDisruptionBudget(nodePool, clk, reason)
+		budget.Nodes = lo.Max([]int{allowedDisruptions - disrupting[nodePool.Name], 0})
+		for _, u := range usage[nodePool.Name] {
+			budget.consume(u)
+		}
+		if budget.exhausted() {
+			budget.Nodes = 0
+		}
+		budgets[nodePool.Name] = budget
 		NodePoolAllowedDisruptions.Set(float64(allowedDisruptions), map[string]string{
 			metrics.NodePoolLabel: nodePool.Name, metrics.ReasonLabel: string(reason),
 		})
@@ -309,7 +307,74 @@ func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, c
 			recorder.Publish(disruptionevents.NodePoolBlockedForDisruptionReason(nodePool, reason))
 		}
 	}
-	return disruptionBudgetMapping, nil
+	return budgets, nil
+}
+
+// filterByMaintenanceWindows removes candidates whose NodePool doesn't have a maintenance window open. Candidates of
+// NodePools with invalid maintenance windows are removed, since we don't know when the user wants their nodes to be
+// disrupted.
+func filterByMaintenanceWindows(ctx context.Context, clk clock.Clock, candidates []*Candidate) []*Candidate {
+	allowed := map[string]bool{}
+	return lo.Filter(candidates, func(c *Candidate, _ int) bool {
+		if ok, found := allowed[c.NodePool.Name]; found {
+			return ok
+		}
+		remaining, err := c.NodePool.MaintenanceWindowRemaining(clk)
+		if err != nil {
+			log.FromContext(ctx).WithValues("NodePool", klog.KObj(c.NodePool)).Error(err, "failed evaluating maintenance windows")
+		} else if remaining <= 0 {
+			log.FromContext(ctx).WithValues("NodePool", klog.KObj(c.NodePool)).V(1).Info("skipping disruption outside of maintenance window")
+		}
+		allowed[c.NodePool.Name] = err == nil && remaining > 0
+		return allowed[c.NodePool.Name]
+	})
+}
+
+// limitToMaintenanceWindows removes commands that launch replacements if the maintenance window of any of their
+// candidates' NodePools closes before the given duration. Delete commands finish as soon as the candidates are
+// tainted and marked for deletion, so they only need the window to be open, which the candidates were filtered on.
+func limitToMaintenanceWindows(ctx context.Context, clk clock.Clock, cmds []Command, required time.Duration) []Command {
+	return lo.Filter(cmds, func(cmd Command, _ int) bool {
+		if len(cmd.Replacements) == 0 {
+			return true
+		}
+		for _, nodePool := range lo.UniqBy(lo.Map(cmd.Candidates, func(c *Candidate, _ int) *v1.NodePool { return c.NodePool }), func(np *v1.NodePool) string { return np.Name }) {
+			if remaining, err := nodePool.MaintenanceWindowRemaining(clk); err != nil || remaining < required {
+				log.FromContext(ctx).WithValues("NodePool", klog.KObj(nodePool), "remaining", remaining).V(1).Info("skipping replacement, maintenance window closes before replacements could initialize")
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// budgetedNodePool returns the NodePool whose disruption budgets the node counts towards, if any
+func budgetedNodePool(node *state.StateNode) (string, bool) {
+	// We only consider nodes that we own and are initialized towards the total.
+	// If a node is launched/registered, but not initialized, pods aren't scheduled
+	// to the node, and these are treated as unhealthy until they're cleaned up.
+	// This prevents odd roundup cases with percentages where replacement nodes that
+	// aren't initialized could be counted towards the total, resulting in more disruptions
+	// to active nodes than desired, where Karpenter should wait for these nodes to be
+	// healthy before continuing.
+	if !node.Managed() || !node.Initialized() {
+		return "", false
+	}
+	// Additionally, don't consider nodeclaims that have the terminating condition. A nodeclaim should have
+	// the Terminating condition only when the node is drained and cloudprovider.Delete() was successful
+	// on the underlying cloud provider machine.
+	if node.NodeClaim.StatusConditions().Get(v1.ConditionTypeInstanceTerminating).IsTrue() {
+		return "", false
+	}
+	return node.Labels()[v1.NodePoolLabelKey], true
+}
+
+// isDisrupting returns true if the node consumes its NodePool's disruption budgets. This is the case if the node
+// 1. Has a NotReady condition
+// 2. Is marked as disrupting
+func isDisrupting(node *state.StateNode) bool {
+	cond := nodeutils.GetCondition(node.Node, corev1.NodeReady)
+	return cond.Status != corev1.ConditionTrue || node.MarkedForDeletion()
 }
 
 // mapCandidates maps the list of proposed candidates with the current state
@@ -0,0 +1,367 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/awslabs/operatorpkg/reconciler"
+	"github.com/awslabs/operatorpkg/singleton"
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+const (
+	planPublishPeriod = 1 * time.Second
+	// planRetention is how long a DisruptionPlan is kept after it reaches a terminal phase
+	planRetention = 1 * time.Hour
+	// maxPlanInstanceTypes is the number of replacement instance types recorded on a DisruptionPlan
+	maxPlanInstanceTypes = 5
+)
+
+// NewDisruptionPlan snapshots a command as a DisruptionPlan
+func NewDisruptionPlan(cmd *Command) *v1alpha1.DisruptionPlan {
+	return &v1alpha1.DisruptionPlan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: cmd.ID.String(),
+		},
+		Spec: v1alpha1.DisruptionPlanSpec{
+			Reason:   string(cmd.Reason()),
+			Decision: string(cmd.Decision()),
+			NodePools: lo.Uniq(lo.FilterMap(cmd.Candidates, func(c *Candidate, _ int) (string, bool) {
+				if c.NodePool == nil {
+					return "", false
+				}
+				return c.NodePool.Name, true
+			})),
+			Candidates: lo.Map(cmd.Candidates, func(c *Candidate, _ int) v1alpha1.DisruptionPlanCandidate {
+				return v1alpha1.DisruptionPlanCandidate{
+					NodeClaim:    c.NodeClaim.Name,
+					Node:         c.Name(),
+					NodePool:     c.NodeClaim.Labels[v1.NodePoolLabelKey],
+					InstanceType: c.Labels()[corev1.LabelInstanceTypeStable],
+					CapacityType: c.Labels()[v1.CapacityTypeLabelKey],
+					Pods:         int32(len(c.reschedulablePods)), //nolint:gosec
+				}
+			}),
+			Replacements: lo.Map(cmd.Replacements, func(r *Replacement, _ int) v1alpha1.DisruptionPlanReplacement {
+				return v1alpha1.DisruptionPlanReplacement{
+					NodeClaim:    r.Name,
+					CapacityType: r.capacityType(),
+					InstanceTypes: lo.Map(lo.Slice(r.InstanceTypeOptions, 0, maxPlanInstanceTypes), func(it *cloudprovider.InstanceType, _ int) string {
+						return it.Name
+					}),
+				}
+			}),
+			EstimatedSavings: fmt.Sprintf("%.4f", cmd.EstimatedSavings()),
+		},
+	}
+}
+
+// planPublisher holds the DisruptionPlans that have changed since the PlanController last wrote them. The disruption
+// controller and queue only record plans in memory, so API latency and errors don't slow down disruption.
+type planPublisher struct {
+	sync.Mutex
+	// unpublished are the plans to write, keyed by name. A nil plan is deleted.
+	unpublished map[string]*v1alpha1.DisruptionPlan
+	// pending are the names of the plans for commands that are being validated and haven't started yet
+	pending sets.Set[string]
+}
+
+func newPlanPublisher() *planPublisher {
+	return &planPublisher{
+		unpublished: map[string]*v1alpha1.DisruptionPlan{},
+		pending:     sets.New[string](),
+	}
+}
+
+func (p *planPublisher) record(plan *v1alpha1.DisruptionPlan) {
+	p.Lock()
+	defer p.Unlock()
+	p.pending.Delete(plan.Name)
+	// Keep the start time of a plan that was started but hasn't been written yet
+	if prev := p.unpublished[plan.Name]; prev != nil && plan.Status.StartTime == nil {
+		plan.Status.StartTime = prev.Status.StartTime
+	}
+	p.unpublished[plan.Name] = plan
+}
+
+func (p *planPublisher) recordPending(plan *v1alpha1.DisruptionPlan) {
+	p.Lock()
+	defer p.Unlock()
+	p.pending.Insert(plan.Name)
+	p.unpublished[plan.Name] = plan
+}
+
+// abandonPending deletes the plans of the commands that are still pending
+func (p *planPublisher) abandonPending() {
+	p.Lock()
+	defer p.Unlock()
+	for name := range p.pending {
+		p.unpublished[name] = nil
+	}
+	p.pending.Clear()
+}
+
+// requeue records a plan that failed to be written again, unless it has been superseded since
+func (p *planPublisher) requeue(name string, plan *v1alpha1.DisruptionPlan) {
+	p.Lock()
+	defer p.Unlock()
+	if _, ok := p.unpublished[name]; !ok {
+		p.unpublished[name] = plan
+	}
+}
+
+func (p *planPublisher) drain() map[string]*v1alpha1.DisruptionPlan {
+	p.Lock()
+	defer p.Unlock()
+	unpublished := p.unpublished
+	p.unpublished = map[string]*v1alpha1.DisruptionPlan{}
+	return unpublished
+}
+
+func (p *planPublisher) isPending(name string) bool {
+	p.Lock()
+	defer p.Unlock()
+	return p.pending.Has(name)
+}
+
+// publishPendingPlan publishes a DisruptionPlan for a command that has been computed but is waiting to be validated
+func (q *Queue) publishPendingPlan(ctx context.Context, cmd Command, reason v1.DisruptionReason) {
+	if !options.FromContext(ctx).FeatureGates.DisruptionPlan {
+		return
+	}
+	plan := NewDisruptionPlan(&cmd)
+	plan.Spec.Reason = string(reason)
+	plan.Status.Phase = v1alpha1.DisruptionPlanPhasePending
+	q.plans.recordPending(plan)
+}
+
+// publishPlan moves the command's DisruptionPlan to the given phase if it isn't already there
+func (q *Queue) publishPlan(ctx context.Context, cmd *Command, phase v1alpha1.DisruptionPlanPhase, failureReason string) {
+	if !options.FromContext(ctx).FeatureGates.DisruptionPlan || cmd.planPhase == phase {
+		return
+	}
+	plan := NewDisruptionPlan(cmd)
+	plan.Status = v1alpha1.DisruptionPlanStatus{Phase: phase, FailureReason: failureReason}
+	if phase == v1alpha1.DisruptionPlanPhaseWaitingForReplacements {
+		plan.Status.StartTime = lo.ToPtr(metav1.NewTime(q.clock.Now()))
+	}
+	if phase.IsTerminal() {
+		plan.Status.CompletionTime = lo.ToPtr(metav1.NewTime(q.clock.Now()))
+	}
+	q.plans.record(plan)
+	cmd.planPhase = phase
+}
+
+func patchPlanStatus(ctx context.Context, kubeClient client.Client, clk clock.Clock, name string, phase v1alpha1.DisruptionPlanPhase, failureReason string) error {
+	plan := &v1alpha1.DisruptionPlan{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Name: name}, plan); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	stored := plan.DeepCopy()
+	plan.Status.Phase = phase
+	plan.Status.FailureReason = failureReason
+	if phase.IsTerminal() {
+		plan.Status.CompletionTime = lo.ToPtr(metav1.NewTime(clk.Now()))
+	}
+	return client.IgnoreNotFound(kubeClient.Status().Patch(ctx, plan, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})))
+}
+
+// PlanController writes the DisruptionPlans recorded by the disruption controller and queue, and tracks them after
+// their commands leave the disruption queue. The queue only follows a command until its candidates are deleted, so
+// this controller follows the candidates through draining and termination and garbage collects plans once they have
+// been complete for planRetention.
+type PlanController struct {
+	clock      clock.Clock
+	kubeClient client.Client
+	queue      *Queue
+}
+
+// NewPlanController constructs a controller that keeps DisruptionPlans up to date
+func NewPlanController(clk clock.Clock, kubeClient client.Client, queue *Queue) *PlanController {
+	return &PlanController{
+		clock:      clk,
+		kubeClient: kubeClient,
+		queue:      queue,
+	}
+}
+
+func (c *PlanController) Reconcile(ctx context.Context) (reconciler.Result, error) {
+	ctx = injection.WithControllerName(ctx, c.Name())
+
+	// Pending plans only exist for the duration of a command's validation, so the recorded plans are written
+	// more often than the plans of completed commands are observed
+	published, err := c.publish(ctx)
+	if err != nil {
+		return reconciler.Result{}, err
+	}
+	plans := &v1alpha1.DisruptionPlanList{}
+	if err := c.kubeClient.List(ctx, plans); err != nil {
+		return reconciler.Result{}, fmt.Errorf("listing disruption plans, %w", err)
+	}
+	for i := range plans.Items {
+		plan := &plans.Items[i]
+		if plan.Status.Phase.IsTerminal() {
+			if plan.Status.CompletionTime == nil || c.clock.Since(plan.Status.CompletionTime.Time) > planRetention {
+				if err := c.kubeClient.Delete(ctx, plan); client.IgnoreNotFound(err) != nil {
+					return reconciler.Result{}, fmt.Errorf("deleting disruption plan, %w", err)
+				}
+			}
+			continue
+		}
+		// The disruption controller and queue own the plan while the command is pending or executing. The cache may
+		// not have caught up with the plans that were just written, so they are observed on the next reconcile.
+		// Check for pending commands first since a pending command is added to the queue when it starts.
+		if published.Has(plan.Name) || c.queue.plans.isPending(plan.Name) || c.queue.HasCommand(plan.Name) {
+			continue
+		}
+		// The command was lost before it started, e.g. because Karpenter restarted while validating it
+		if plan.Status.Phase == v1alpha1.DisruptionPlanPhasePending {
+			if err := c.kubeClient.Delete(ctx, plan); client.IgnoreNotFound(err) != nil {
+				return reconciler.Result{}, fmt.Errorf("deleting disruption plan, %w", err)
+			}
+			continue
+		}
+		phase, failureReason, err := c.observePhase(ctx, plan)
+		if err != nil {
+			return reconciler.Result{}, err
+		}
+		if phase == plan.Status.Phase {
+			continue
+		}
+		if err := patchPlanStatus(ctx, c.kubeClient, c.clock, plan.Name, phase, failureReason); err != nil {
+			return reconciler.Result{}, fmt.Errorf("updating disruption plan, %w", err)
+		}
+	}
+	return reconciler.Result{RequeueAfter: planPublishPeriod}, nil
+}
+
+// publish writes the plans recorded since the last reconcile and returns their names. Plans that fail to be written
+// are retried on the next reconcile.
+func (c *PlanController) publish(ctx context.Context) (sets.Set[string], error) {
+	unpublished := c.queue.plans.drain()
+	var errs []error
+	for name, plan := range unpublished {
+		if err := c.write(ctx, name, plan); err != nil {
+			c.queue.plans.requeue(name, plan)
+			errs = append(errs, err)
+		}
+	}
+	return sets.KeySet(unpublished), multierr.Combine(errs...)
+}
+
+func (c *PlanController) write(ctx context.Context, name string, desired *v1alpha1.DisruptionPlan) error {
+	if desired == nil {
+		if err := c.kubeClient.Delete(ctx, &v1alpha1.DisruptionPlan{ObjectMeta: metav1.ObjectMeta{Name: name}}); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting disruption plan, %w", err)
+		}
+		return nil
+	}
+	plan := &v1alpha1.DisruptionPlan{}
+	if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: name}, plan); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("getting disruption plan, %w", err)
+		}
+		plan = desired.DeepCopy()
+		if err := c.kubeClient.Create(ctx, plan); err != nil {
+			return fmt.Errorf("creating disruption plan, %w", err)
+		}
+		plan.Status = desired.Status
+		if err := c.kubeClient.Status().Update(ctx, plan); err != nil {
+			return fmt.Errorf("updating disruption plan, %w", err)
+		}
+		return nil
+	}
+	stored := plan.DeepCopy()
+	plan.Spec = desired.Spec
+	if !equality.Semantic.DeepEqual(plan.Spec, stored.Spec) {
+		if err := c.kubeClient.Patch(ctx, plan, client.MergeFrom(stored)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("patching disruption plan, %w", err)
+		}
+	}
+	stored = plan.DeepCopy()
+	plan.Status.Phase = desired.Status.Phase
+	plan.Status.FailureReason = desired.Status.FailureReason
+	if desired.Status.StartTime != nil {
+		plan.Status.StartTime = desired.Status.StartTime
+	}
+	if desired.Status.CompletionTime != nil {
+		plan.Status.CompletionTime = desired.Status.CompletionTime
+	}
+	if !equality.Semantic.DeepEqual(plan.Status, stored.Status) {
+		if err := c.kubeClient.Status().Patch(ctx, plan, client.MergeFrom(stored)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("patching disruption plan status, %w", err)
+		}
+	}
+	return nil
+}
+
+// observePhase derives the phase of a plan that is no longer in the queue from the state of its candidates
+func (c *PlanController) observePhase(ctx context.Context, plan *v1alpha1.DisruptionPlan) (v1alpha1.DisruptionPlanPhase, string, error) {
+	var nodeClaims []*v1.NodeClaim
+	for _, candidate := range plan.Spec.Candidates {
+		nodeClaim := &v1.NodeClaim{}
+		if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: candidate.NodeClaim}, nodeClaim); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return "", "", fmt.Errorf("getting nodeclaim, %w", err)
+			}
+			continue
+		}
+		nodeClaims = append(nodeClaims, nodeClaim)
+	}
+	switch {
+	case len(nodeClaims) == 0:
+		return v1alpha1.DisruptionPlanPhaseSucceeded, "", nil
+	// If the queue dropped the command without deleting the candidates (e.g. Karpenter restarted), it will never finish
+	case lo.ContainsBy(nodeClaims, func(n *v1.NodeClaim) bool { return n.DeletionTimestamp.IsZero() }):
+		return v1alpha1.DisruptionPlanPhaseFailed, "command is no longer being executed", nil
+	case lo.EveryBy(nodeClaims, func(n *v1.NodeClaim) bool { return n.StatusConditions().Get(v1.ConditionTypeDrained).IsTrue() }):
+		return v1alpha1.DisruptionPlanPhaseTerminating, "", nil
+	default:
+		return v1alpha1.DisruptionPlanPhaseDraining, "", nil
+	}
+}
+
+func (c *PlanController) Name() string {
+	return "disruption.plan"
+}
+
+func (c *PlanController) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named(c.Name()).
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(c))
+}
@@ -49,6 +49,7 @@ import (
 	operatorlogging "sigs.k8s.io/karpenter/pkg/operator/logging"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
@@ -102,6 +103,11 @@ type Queue struct {
 	cluster             *state.Cluster
 	clock               clock.Clock
 	provisioner         *provisioning.Provisioner
+
+	budgetMu     sync.Mutex
+	reservations map[string]reservation // providerID -> budget reserved by a command that is being started
+
+	plans *planPublisher
 }
 
 // NewQueue creates a queue that will asynchronously orchestrate disruption commands
@@ -118,6 +124,8 @@ func NewQueue(kubeClient client.Client, recorder events.Recorder, cluster *state
 		cluster:             cluster,
 		clock:               clock,
 		provisioner:         provisioner,
+		reservations:        map[string]reservation{},
+		plans:               newPlanPublisher(),
 	}
 	return queue
 }
@@ -171,11 +179,14 @@ func (q *Queue) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconci
 		stateNodes := lo.Map(cmd.Candidates, func(c *Candidate, _ int) *state.StateNode { return c.StateNode })
 		multiErr := multierr.Combine(err, state.RequireNoScheduleTaint(ctx, q.kubeClient, false, stateNodes...))
 		multiErr = multierr.Combine(multiErr, state.ClearNodeClaimsCondition(ctx, q.kubeClient, q.clock, v1.ConditionTypeDisruptionReason, stateNodes...))
+		multiErr = multierr.Combine(multiErr, q.revertSurges(ctx, cmd))
 		// Log the error
 		log.FromContext(ctx).Error(multiErr, "failed terminating nodes while executing a disruption command")
+		q.publishPlan(ctx, cmd, v1alpha1.DisruptionPlanPhaseFailed, err.Error())
 	} else {
 		log.FromContext(ctx).V(1).Info("command succeeded")
 		cmd.Succeeded = true
+		q.publishPlan(ctx, cmd, v1alpha1.DisruptionPlanPhaseDraining, "")
 	}
 	q.CompleteCommand(cmd)
 	return reconcile.Result{}, nil
@@ -227,6 +238,10 @@ func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) (err error) {
 	if err := multierr.Combine(waitErrs...); err != nil {
 		return fmt.Errorf("waiting for replacement initialization, %w", err)
 	}
+	// Surge the workloads that opted into surge drain, so that their replacement pods are Ready before the candidates are deleted
+	if err := q.surge(ctx, cmd); err != nil {
+		return fmt.Errorf("waiting for surge, %w", err)
+	}
 
 	// All replacements have been provisioned.
 	// All we need to do now is get a successful delete call for each node claim,
@@ -326,6 +341,11 @@ func (q *Queue) StartCommand(ctx context.Context, cmd *Command) error {
 	if q.HasAny(providerIDs...) {
 		return fmt.Errorf("candidate is being disrupted")
 	}
+	if err := q.reserveBudgets(cmd); err != nil {
+		return serrors.Wrap(err, "command-id", cmd.ID)
+	}
+	// Once the candidates are marked for deletion, cluster state accounts for them in the budgets
+	defer q.releaseBudgets(providerIDs...)
 
 	log.FromContext(ctx).WithValues(append([]any{
 		"command", cmd.String(),
@@ -370,6 +390,9 @@ func (q *Queue) StartCommand(ctx context.Context, cmd *Command) error {
 	for _, c := range cmd.Candidates {
 		q.ProviderIDToCommand[c.ProviderID()] = cmd
 	}
+	// The plan is recorded under the queue's lock so that the PlanController sees the command as either pending or
+	// executing, and so that the queue can't record a later phase first
+	q.publishPlan(ctx, cmd, v1alpha1.DisruptionPlanPhaseWaitingForReplacements, "")
 	// IMPORTANT
 	// We are adding the first nodeclaim in the list of candidates into the reconciliation queue
 	// This invariant SHOULD NOT be relied on anywhere else besides within this file.
@@ -410,6 +433,14 @@ func (q *Queue) HasAny(ids ...string) bool {
 	return false
 }
 
+// HasCommand checks to see if the command with the given ID is currently executing.
+func (q *Queue) HasCommand(id string) bool {
+	q.RLock()
+	defer q.RUnlock()
+
+	return lo.ContainsBy(lo.Values(q.ProviderIDToCommand), func(cmd *Command) bool { return cmd.ID.String() == id })
+}
+
 // For TESTING ONLY
 // This function is not thread safe as it returns pointers to commands.
 // If you edit these commands returned, you can create race conditions.
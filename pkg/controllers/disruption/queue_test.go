@@ -17,6 +17,7 @@ limitations under the License.
 package disruption_test
 
 import (
+	"math"
 	"strconv"
 	"time"
 
@@ -29,6 +30,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,6 +43,7 @@ import (
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/utils/pdb"
 )
 
 var (
@@ -416,6 +421,34 @@ var _ = Describe("Queue", func() {
 			// And expect the nodeClaim and node to be deleted
 			ExpectNotFound(ctx, env.Client, nodeClaim2, node2)
 		})
+		Context("Budgets", func() {
+			deleteCommand := func(nodeClaim *v1.NodeClaim) *disruption.Command {
+				return &disruption.Command{
+					Method:            disruption.NewDrift(env.Client, cluster, prov, recorder, env.Clock),
+					CreationTimestamp: env.Clock.Now(),
+					ID:                uuid.New(),
+					Results:           scheduling.Results{},
+					Candidates:        []*disruption.Candidate{{StateNode: ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim), NodePool: nodePool}},
+				}
+			}
+			It("should not start commands that exceed the resource budget", func() {
+				nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "100%", Resources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("32")}}}
+				ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodeClaim2, node2, nodePool)
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node1, node2}, []*v1.NodeClaim{nodeClaim1, nodeClaim2})
+
+				Expect(queue.StartCommand(ctx, deleteCommand(nodeClaim1))).To(Succeed())
+				Expect(queue.StartCommand(ctx, deleteCommand(nodeClaim2))).To(MatchError(ContainSubstring("disruption budget")))
+				Expect(queue.HasAny(nodeClaim2.Status.ProviderID)).To(BeFalse())
+			})
+			It("should start commands within the pod budget", func() {
+				nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "100%", Pods: new(int32(10))}}
+				ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodeClaim2, node2, nodePool)
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node1, node2}, []*v1.NodeClaim{nodeClaim1, nodeClaim2})
+
+				Expect(queue.StartCommand(ctx, deleteCommand(nodeClaim1))).To(Succeed())
+				Expect(queue.StartCommand(ctx, deleteCommand(nodeClaim2))).To(Succeed())
+			})
+		})
 		Context("CalculateRetryDuration", func() {
 			DescribeTable("should calculate correct timeout based on queue length",
 				func(numCommands int, expectedDuration time.Duration) {
@@ -437,4 +470,177 @@ var _ = Describe("Queue", func() {
 			)
 		})
 	})
+	Context("Surge Drain", func() {
+		var deployment *appsv1.Deployment
+		var pod *corev1.Pod
+		BeforeEach(func() {
+			node1.Spec.Taints = nil
+			deployment = test.Deployment(test.DeploymentOptions{Replicas: 1})
+			ExpectApplied(ctx, env.Client, deployment)
+			rs := test.ReplicaSet()
+			rs.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion:         "apps/v1",
+				Kind:               "Deployment",
+				Name:               deployment.Name,
+				UID:                deployment.UID,
+				Controller:         new(true),
+				BlockOwnerDeletion: new(true),
+			}}
+			ExpectApplied(ctx, env.Client, rs)
+			pod = test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "apps/v1",
+				Kind:               "ReplicaSet",
+				Name:               rs.Name,
+				UID:                rs.UID,
+				Controller:         new(true),
+				BlockOwnerDeletion: new(true),
+			}}}})
+		})
+		// startCommand starts a delete command for nodeClaim1 and reconciles it once
+		startCommand := func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, pod)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
+			ExpectManualBinding(ctx, env.Client, pod, node1)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node1))
+
+			nodePoolMap, nodePoolToInstanceTypesMap, err := disruption.BuildNodePoolMap(ctx, env.Client, cloudProvider)
+			Expect(err).To(Succeed())
+			pdbs, err := pdb.NewLimits(ctx, env.Client)
+			Expect(err).To(Succeed())
+			candidate, err := disruption.NewCandidate(ctx, env.Client, recorder, env.Clock, ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1), pdbs, nodePoolMap, nodePoolToInstanceTypesMap, queue, disruption.GracefulDisruptionClass)
+			Expect(err).To(Succeed())
+
+			Expect(queue.StartCommand(ctx, &disruption.Command{
+				Method:            disruption.NewDrift(env.Client, cluster, prov, recorder, env.Clock),
+				CreationTimestamp: env.Clock.Now(),
+				ID:                uuid.New(),
+				Results:           scheduling.Results{},
+				Candidates:        []*disruption.Candidate{candidate},
+			})).To(Succeed())
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim1)
+		}
+		// expectSurgedReplicasReady marks the deployment's replicas as ready, like the deployment controller would
+		expectSurgedReplicasReady := func() {
+			deployment = ExpectExists(ctx, env.Client, deployment)
+			deployment.Status.ObservedGeneration = deployment.Generation
+			deployment.Status.Replicas = lo.FromPtr(deployment.Spec.Replicas)
+			deployment.Status.ReadyReplicas = lo.FromPtr(deployment.Spec.Replicas)
+			ExpectApplied(ctx, env.Client, deployment)
+		}
+		It("should scale up single replica deployments before deleting the candidate", func() {
+			nodePool.Spec.Disruption.SurgeDrainPolicy = v1.SurgeDrainPolicyWhenSingleReplica
+			startCommand()
+
+			deployment = ExpectExists(ctx, env.Client, deployment)
+			Expect(lo.FromPtr(deployment.Spec.Replicas)).To(BeNumerically("==", 2))
+			Expect(deployment.Annotations).To(HaveKey(v1.SurgeDrainStateAnnotationKey))
+			pod = ExpectExists(ctx, env.Client, pod)
+			Expect(pod.Annotations).To(HaveKeyWithValue(corev1.PodDeletionCost, strconv.Itoa(math.MinInt32)))
+			// The candidate isn't deleted until the surged replica is ready
+			Expect(queue.HasAny(nodeClaim1.Status.ProviderID)).To(BeTrue())
+			Expect(ExpectExists(ctx, env.Client, nodeClaim1).DeletionTimestamp.IsZero()).To(BeTrue())
+
+			expectSurgedReplicasReady()
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim1)
+			deployment = ExpectExists(ctx, env.Client, deployment)
+			Expect(lo.FromPtr(deployment.Spec.Replicas)).To(BeNumerically("==", 1))
+			Expect(deployment.Annotations).ToNot(HaveKey(v1.SurgeDrainStateAnnotationKey))
+			Expect(queue.HasAny(nodeClaim1.Status.ProviderID)).To(BeFalse())
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim1)
+			ExpectNotFound(ctx, env.Client, nodeClaim1, node1)
+		})
+		It("should not scale up deployments with multiple replicas", func() {
+			nodePool.Spec.Disruption.SurgeDrainPolicy = v1.SurgeDrainPolicyWhenSingleReplica
+			deployment.Spec.Replicas = new(int32(3))
+			ExpectApplied(ctx, env.Client, deployment)
+			startCommand()
+
+			deployment = ExpectExists(ctx, env.Client, deployment)
+			Expect(lo.FromPtr(deployment.Spec.Replicas)).To(BeNumerically("==", 3))
+			Expect(ExpectExists(ctx, env.Client, pod).Annotations).ToNot(HaveKey(corev1.PodDeletionCost))
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim1)
+			ExpectNotFound(ctx, env.Client, nodeClaim1, node1)
+		})
+		It("should not scale up deployments that are scaled by a horizontal pod autoscaler", func() {
+			nodePool.Spec.Disruption.SurgeDrainPolicy = v1.SurgeDrainPolicyWhenSingleReplica
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: test.ObjectMeta(metav1.ObjectMeta{Namespace: deployment.Namespace}),
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: deployment.Name},
+					MaxReplicas:    3,
+				},
+			}
+			ExpectApplied(ctx, env.Client, hpa)
+			startCommand()
+
+			Expect(lo.FromPtr(ExpectExists(ctx, env.Client, deployment).Spec.Replicas)).To(BeNumerically("==", 1))
+			Expect(ExpectExists(ctx, env.Client, pod).Annotations).ToNot(HaveKey(corev1.PodDeletionCost))
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim1)
+			ExpectNotFound(ctx, env.Client, nodeClaim1, node1)
+			ExpectDeleted(ctx, env.Client, hpa)
+		})
+		It("should keep the replicas if they were changed while the deployment was surged", func() {
+			nodePool.Spec.Disruption.SurgeDrainPolicy = v1.SurgeDrainPolicyWhenSingleReplica
+			startCommand()
+
+			// Another actor scales the deployment while it's surged
+			deployment = ExpectExists(ctx, env.Client, deployment)
+			deployment.Spec.Replicas = new(int32(5))
+			ExpectApplied(ctx, env.Client, deployment)
+
+			expectSurgedReplicasReady()
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim1)
+			deployment = ExpectExists(ctx, env.Client, deployment)
+			Expect(lo.FromPtr(deployment.Spec.Replicas)).To(BeNumerically("==", 5))
+			Expect(deployment.Annotations).ToNot(HaveKey(v1.SurgeDrainStateAnnotationKey))
+		})
+		It("should revert surges whose command was lost", func() {
+			nodePool.Spec.Disruption.SurgeDrainPolicy = v1.SurgeDrainPolicyWhenSingleReplica
+			pod.Annotations = lo.Assign(pod.Annotations, map[string]string{corev1.PodDeletionCost: "10"})
+			startCommand()
+			Expect(lo.FromPtr(ExpectExists(ctx, env.Client, deployment).Spec.Replicas)).To(BeNumerically("==", 2))
+
+			// The surge isn't reverted while its command is in the queue
+			Expect(queue.RevertOrphanedSurges(ctx)).To(Succeed())
+			Expect(lo.FromPtr(ExpectExists(ctx, env.Client, deployment).Spec.Replicas)).To(BeNumerically("==", 2))
+
+			// A new queue doesn't have the command, e.g. after Karpenter restarts
+			Expect(disruption.NewQueue(env.Client, recorder, cluster, env.Clock, prov).RevertOrphanedSurges(ctx)).To(Succeed())
+			deployment = ExpectExists(ctx, env.Client, deployment)
+			Expect(lo.FromPtr(deployment.Spec.Replicas)).To(BeNumerically("==", 1))
+			Expect(deployment.Annotations).ToNot(HaveKey(v1.SurgeDrainStateAnnotationKey))
+			Expect(ExpectExists(ctx, env.Client, pod).Annotations).To(HaveKeyWithValue(corev1.PodDeletionCost, "10"))
+		})
+		It("should scale up deployments of pods with the surge drain annotation", func() {
+			deployment.Spec.Replicas = new(int32(3))
+			ExpectApplied(ctx, env.Client, deployment)
+			pod.Annotations = lo.Assign(pod.Annotations, map[string]string{v1.SurgeDrainAnnotationKey: "true"})
+			startCommand()
+
+			deployment = ExpectExists(ctx, env.Client, deployment)
+			Expect(lo.FromPtr(deployment.Spec.Replicas)).To(BeNumerically("==", 4))
+
+			expectSurgedReplicasReady()
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim1)
+			deployment = ExpectExists(ctx, env.Client, deployment)
+			Expect(lo.FromPtr(deployment.Spec.Replicas)).To(BeNumerically("==", 3))
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim1)
+			ExpectNotFound(ctx, env.Client, nodeClaim1, node1)
+		})
+		It("should restore surged deployments when a command times out", func() {
+			nodePool.Spec.Disruption.SurgeDrainPolicy = v1.SurgeDrainPolicyWhenSingleReplica
+			pod.Annotations = lo.Assign(pod.Annotations, map[string]string{corev1.PodDeletionCost: "10"})
+			startCommand()
+			Expect(lo.FromPtr(ExpectExists(ctx, env.Client, deployment).Spec.Replicas)).To(BeNumerically("==", 2))
+
+			// Step the clock to trigger the timeout.
+			env.Clock.Step(11 * time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim1)
+
+			Expect(lo.FromPtr(ExpectExists(ctx, env.Client, deployment).Spec.Replicas)).To(BeNumerically("==", 1))
+			Expect(ExpectExists(ctx, env.Client, pod).Annotations).To(HaveKeyWithValue(corev1.PodDeletionCost, "10"))
+			Expect(queue.HasAny(nodeClaim1.Status.ProviderID)).To(BeFalse())
+			Expect(ExpectExists(ctx, env.Client, nodeClaim1).DeletionTimestamp.IsZero()).To(BeTrue())
+		})
+	})
 })
@@ -102,7 +102,8 @@ func (s *SingleNodeConsolidation) ComputeCommands(ctx context.Context, disruptio
 		if approved, _ := s.evaluator.ApproveCommand(ctx, cmd); !approved {
 			continue
 		}
-		if _, err = s.validator.Validate(ctx, cmd, commandValidationDelay); err != nil {
+		validCmd, err := s.validator.Validate(ctx, cmd, commandValidationDelay)
+		if err != nil {
 			if IsValidationError(err) {
 				reason := getValidationFailureReason(err)
 				cmd.EmitRejectedEvents(s.recorder, reason)
@@ -110,7 +111,7 @@ func (s *SingleNodeConsolidation) ComputeCommands(ctx context.Context, disruptio
 			}
 			return []Command{}, fmt.Errorf("validating consolidation, %w", err)
 		}
-		return []Command{cmd}, nil
+		return []Command{validCmd}, nil
 	}
 
 	if !constrainedByBudgets {
@@ -58,6 +58,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/state/prediction"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	disruptionutils "sigs.k8s.io/karpenter/pkg/utils/disruption"
@@ -104,7 +105,7 @@ var _ = BeforeSuite(func() {
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cloudProvider, cluster, clusterCost)
 	recorder = test.NewEventRecorder()
 	draController = deviceallocation.NewController(env.Client)
-	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, env.Clock, draController, virtualpods.NewVirtualPodCache(env.Client))
+	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, env.Clock, draController, virtualpods.NewVirtualPodCache(env.Client), prediction.NewStore())
 	queue = disruption.NewQueue(env.Client, recorder, cluster, env.Clock, prov)
 })
 
@@ -124,7 +125,7 @@ var _ = BeforeEach(func() {
 	// (which the controller accumulates across reconciles and never resets) doesn't leak between specs. This must
 	// happen before the disruptionController and queue below, which capture prov. Mirrors the provisioning suite.
 	draController = deviceallocation.NewController(env.Client)
-	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, env.Clock, draController, virtualpods.NewVirtualPodCache(env.Client))
+	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, env.Clock, draController, virtualpods.NewVirtualPodCache(env.Client), prediction.NewStore())
 
 	// Ensure that we reset the disruption controller's methods after each test run
 	disruptionController = disruption.NewController(env.Clock, env.Client, prov, cloudProvider, recorder, cluster, queue, clusterCost, disruption.WithMethods(NewMethodsWithNopValidator()...))
@@ -256,6 +257,55 @@ var _ = Describe("Simulate Scheduling", func() {
 		Expect(err).To(Succeed())
 		Expect(results.PodErrors[pod]).To(BeNil())
 	})
+	It("should not reschedule part of a pod group from a candidate", func() {
+		nodeClaims, nodes := test.NodeClaimsAndNodes(2, v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+					v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+			Status: v1.NodeClaimStatus{
+				Allocatable: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU:  resource.MustParse("3"),
+					corev1.ResourcePods: resource.MustParse("100"),
+				},
+			},
+		})
+		// Only the candidate has the label that one of the members selects, so that member can't reschedule
+		nodes[0].Labels["example.com/pinned"] = "true"
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodes[0], nodeClaims[1], nodes[1])
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+		member := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.PodGroupLabelKey: "training"}}})
+		pinnedMember := test.Pod(test.PodOptions{
+			ObjectMeta:   metav1.ObjectMeta{Labels: map[string]string{v1.PodGroupLabelKey: "training"}},
+			NodeSelector: map[string]string{"example.com/pinned": "true"},
+		})
+		ExpectApplied(ctx, env.Client, member, pinnedMember)
+		ExpectManualBinding(ctx, env.Client, member, nodes[0])
+		ExpectManualBinding(ctx, env.Client, pinnedMember, nodes[0])
+		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(nodes[0]))
+
+		nodePoolMap, nodePoolToInstanceTypesMap, err := disruption.BuildNodePoolMap(ctx, env.Client, cloudProvider)
+		Expect(err).To(Succeed())
+		pdbs, err := pdb.NewLimits(ctx, env.Client)
+		Expect(err).To(Succeed())
+		candidate, err := disruption.NewCandidate(ctx, env.Client, recorder, env.Clock, ExpectStateNodeExists(cluster, nodes[0]), pdbs, nodePoolMap, nodePoolToInstanceTypesMap, queue, disruption.GracefulDisruptionClass)
+		Expect(err).To(Succeed())
+
+		results, err := disruption.SimulateScheduling(ctx, env.Client, cluster, prov, env.Clock, recorder, nil, candidate)
+		Expect(err).To(Succeed())
+		Expect(results.PodErrors).To(HaveLen(2))
+		for p, err := range results.PodErrors {
+			Expect(pscheduling.IsPodGroupError(err)).To(Equal(p.UID == member.UID))
+		}
+		Expect(results.NewNodeClaims).To(BeEmpty())
+		for _, n := range results.ExistingNodes {
+			Expect(n.Pods).To(BeEmpty())
+		}
+	})
 	It("should allow multiple replace operations to happen successively", func() {
 		numNodes := 10
 		nodeClaims, nodes := test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
@@ -472,7 +522,7 @@ var _ = Describe("Simulate Scheduling", func() {
 		hangCreateClient := newHangCreateClient(env.Client)
 		defer hangCreateClient.Stop()
 
-		p := provisioning.NewProvisioner(hangCreateClient, recorder, cloudProvider, cluster, env.Clock, deviceallocation.NewController(hangCreateClient), virtualpods.NewVirtualPodCache(hangCreateClient))
+		p := provisioning.NewProvisioner(hangCreateClient, recorder, cloudProvider, cluster, env.Clock, deviceallocation.NewController(hangCreateClient), virtualpods.NewVirtualPodCache(hangCreateClient), prediction.NewStore())
 		q := disruption.NewQueue(hangCreateClient, recorder, cluster, env.Clock, p)
 		dc := disruption.NewController(env.Clock, hangCreateClient, p, cloudProvider, recorder, cluster, q, clusterCost)
 
@@ -850,12 +900,58 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 			Expect(budgets[nodePool.Name]).To(Equal(8))
 		}
 	})
+	It("should not allow disruptions once the pods being evicted reach the pod budget", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "100%", Pods: new(int32(3))}}
+		ExpectApplied(ctx, env.Client, nodePool)
+		for i := range 2 {
+			for range 2 {
+				pod := test.Pod()
+				ExpectApplied(ctx, env.Client, pod)
+				ExpectManualBinding(ctx, env.Client, pod, nodes[i])
+			}
+		}
+
+		cluster.MarkForDeletion(nodeClaims[0].Status.ProviderID)
+		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(nodes[0]))
+		budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, env.Clock, env.Client, cloudProvider, recorder, v1.DisruptionReasonDrifted)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name]).To(Equal(9))
+
+		cluster.MarkForDeletion(nodeClaims[1].Status.ProviderID)
+		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(nodes[1]))
+		budgets, err = disruption.BuildDisruptionBudgetMapping(ctx, cluster, env.Clock, env.Client, cloudProvider, recorder, v1.DisruptionReasonDrifted)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name]).To(Equal(0))
+	})
+	It("should not allow disruptions once the capacity being disrupted reaches the resource budget", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{{
+			Nodes:     "100%",
+			Resources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("64")},
+			Reasons:   []v1.DisruptionReason{v1.DisruptionReasonDrifted},
+		}}
+		ExpectApplied(ctx, env.Client, nodePool)
+
+		cluster.MarkForDeletion(nodeClaims[0].Status.ProviderID)
+		budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, env.Clock, env.Client, cloudProvider, recorder, v1.DisruptionReasonDrifted)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name]).To(Equal(9))
+
+		cluster.MarkForDeletion(nodeClaims[1].Status.ProviderID)
+		budgets, err = disruption.BuildDisruptionBudgetMapping(ctx, cluster, env.Clock, env.Client, cloudProvider, recorder, v1.DisruptionReasonDrifted)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name]).To(Equal(0))
+
+		// The resource budget only applies to drift
+		budgets, err = disruption.BuildDisruptionBudgetMapping(ctx, cluster, env.Clock, env.Client, cloudProvider, recorder, v1.DisruptionReasonEmpty)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name]).To(Equal(8))
+	})
 })
 
 var _ = Describe("Pod Eviction Cost", func() {
 	const standardPodCost = 1.0
 	It("should have a standard disruptionCost for a pod with no priority or disruptionCost specified", func() {
-		cost := disruptionutils.EvictionCost(ctx, &corev1.Pod{})
+		cost := disruptionutils.EvictionCost(ctx, &corev1.Pod{}, nil)
 		Expect(cost).To(BeNumerically("==", standardPodCost))
 	})
 	It("should have a higher disruptionCost for a pod with a positive deletion disruptionCost", func() {
@@ -863,7 +959,7 @@ var _ = Describe("Pod Eviction Cost", func() {
 			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
 				corev1.PodDeletionCost: "100",
 			}},
-		})
+		}, nil)
 		Expect(cost).To(BeNumerically(">", standardPodCost))
 	})
 	It("should have a lower disruptionCost for a pod with a positive deletion disruptionCost", func() {
@@ -871,7 +967,7 @@ var _ = Describe("Pod Eviction Cost", func() {
 			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
 				corev1.PodDeletionCost: "-100",
 			}},
-		})
+		}, nil)
 		Expect(cost).To(BeNumerically("<", standardPodCost))
 	})
 	It("should have higher costs for higher deletion costs", func() {
@@ -879,32 +975,85 @@ var _ = Describe("Pod Eviction Cost", func() {
 			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
 				corev1.PodDeletionCost: "101",
 			}},
-		})
+		}, nil)
 		cost2 := disruptionutils.EvictionCost(ctx, &corev1.Pod{
 			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
 				corev1.PodDeletionCost: "100",
 			}},
-		})
+		}, nil)
 		cost3 := disruptionutils.EvictionCost(ctx, &corev1.Pod{
 			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
 				corev1.PodDeletionCost: "99",
 			}},
-		})
+		}, nil)
 		Expect(cost1).To(BeNumerically(">", cost2))
 		Expect(cost2).To(BeNumerically(">", cost3))
 	})
 	It("should have a higher disruptionCost for a pod with a higher priority", func() {
 		cost := disruptionutils.EvictionCost(ctx, &corev1.Pod{
 			Spec: corev1.PodSpec{Priority: new(int32(1))},
-		})
+		}, nil)
 		Expect(cost).To(BeNumerically(">", standardPodCost))
 	})
 	It("should have a lower disruptionCost for a pod with a lower priority", func() {
 		cost := disruptionutils.EvictionCost(ctx, &corev1.Pod{
 			Spec: corev1.PodSpec{Priority: new(int32(-1))},
-		})
+		}, nil)
 		Expect(cost).To(BeNumerically("<", standardPodCost))
 	})
+	It("should use the disruption cost annotation instead of the priority", func() {
+		cost := disruptionutils.EvictionCost(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				v1.DisruptionCostAnnotationKey: "5",
+			}},
+			Spec: corev1.PodSpec{Priority: new(int32(-1))},
+		}, nil)
+		Expect(cost).To(BeNumerically("==", 5))
+	})
+	It("should clamp the disruption cost annotation", func() {
+		cost := disruptionutils.EvictionCost(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				v1.DisruptionCostAnnotationKey: "100",
+			}},
+		}, nil)
+		Expect(cost).To(BeNumerically("==", 10))
+	})
+	It("should ignore an invalid disruption cost annotation", func() {
+		cost := disruptionutils.EvictionCost(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				v1.DisruptionCostAnnotationKey: "expensive",
+			}},
+		}, nil)
+		Expect(cost).To(BeNumerically("==", standardPodCost))
+	})
+	It("should use the namespace's default disruption cost", func() {
+		namespaceCosts := disruptionutils.NamespaceCosts{"expensive": 3}
+		cost := disruptionutils.EvictionCost(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "expensive"}}, namespaceCosts)
+		Expect(cost).To(BeNumerically("==", 3))
+		cost = disruptionutils.EvictionCost(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}}, namespaceCosts)
+		Expect(cost).To(BeNumerically("==", standardPodCost))
+	})
+	It("should prefer the pod's disruption cost over the namespace's default", func() {
+		cost := disruptionutils.EvictionCost(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "expensive",
+				Annotations: map[string]string{v1.DisruptionCostAnnotationKey: "0"},
+			},
+		}, disruptionutils.NamespaceCosts{"expensive": 3})
+		Expect(cost).To(BeNumerically("==", 0))
+	})
+	It("should get the default disruption costs of the pods' namespaces", func() {
+		namespace := test.Namespace(test.NamespaceOptions{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{v1.DisruptionCostAnnotationKey: "2.5"},
+		}})
+		ExpectApplied(ctx, env.Client, namespace)
+		namespaceCosts, err := disruptionutils.GetNamespaceCosts(ctx, env.Client,
+			test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name}}),
+			test.Pod(),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespaceCosts).To(Equal(disruptionutils.NamespaceCosts{namespace.Name: 2.5}))
+	})
 })
 
 var _ = Describe("Candidate Filtering", func() {
@@ -0,0 +1,365 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/awslabs/operatorpkg/serrors"
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+// surgeDeletionCost is the pod deletion cost of a surged pod. It's the lowest possible cost, so that the ReplicaSet
+// removes the surged pod rather than its replacement when the Deployment is scaled back down.
+var surgeDeletionCost = strconv.Itoa(math.MinInt32)
+
+// Surge is a Deployment that's scaled up before its pods on the candidates are disrupted. Once the additional
+// replicas are Ready, the Deployment is scaled back down and its ReplicaSet removes the surged pods.
+type Surge struct {
+	Deployment types.NamespacedName
+	Pods       []*SurgedPod
+	// Replicas is the number of replicas the Deployment was scaled up to
+	Replicas int32
+	Started  bool
+	// Completed is set once the Deployment has been scaled back down
+	Completed bool
+}
+
+// SurgedPod is a pod that's replaced by a surge, along with its deletion cost before it was surged
+type SurgedPod struct {
+	Namespace    string  `json:"namespace"`
+	Name         string  `json:"name"`
+	DeletionCost *string `json:"deletionCost,omitempty"`
+}
+
+func (p *SurgedPod) key() types.NamespacedName {
+	return types.NamespacedName{Namespace: p.Namespace, Name: p.Name}
+}
+
+// surgeState is stored on a surged Deployment until it's scaled back down, so that the surge can be reverted if the
+// command that started it is lost
+type surgeState struct {
+	// ProviderIDs are the candidates of the command that started the surge
+	ProviderIDs []string `json:"providerIDs"`
+	// Replicas is the number of replicas the Deployment had before it was surged
+	Replicas int32        `json:"replicas"`
+	Pods     []*SurgedPod `json:"pods"`
+}
+
+// surgeStateFor returns the surge state stored on the Deployment, if it's surged
+func surgeStateFor(deployment *appsv1.Deployment) (*surgeState, bool, error) {
+	raw, ok := deployment.Annotations[v1.SurgeDrainStateAnnotationKey]
+	if !ok {
+		return nil, false, nil
+	}
+	state := &surgeState{}
+	if err := json.Unmarshal([]byte(raw), state); err != nil {
+		return nil, false, fmt.Errorf("parsing surge state, %w", err)
+	}
+	return state, true, nil
+}
+
+// surge scales up the Deployments of the candidates' pods that opted into surge drain, and scales them back down
+// once the additional replicas are Ready. It returns an error until every surge has completed.
+func (q *Queue) surge(ctx context.Context, cmd *Command) error {
+	if cmd.Surges == nil {
+		surges, err := q.surgesFor(ctx, cmd)
+		if err != nil {
+			return fmt.Errorf("resolving surges, %w", err)
+		}
+		cmd.Surges = surges
+	}
+	errs := make([]error, len(cmd.Surges))
+	for i, s := range cmd.Surges {
+		if s.Completed {
+			continue
+		}
+		if !s.Started {
+			if err := q.startSurge(ctx, cmd, s); err != nil {
+				errs[i] = serrors.Wrap(fmt.Errorf("starting surge, %w", err), "Deployment", klog.KRef(s.Deployment.Namespace, s.Deployment.Name))
+			}
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		if err := q.kubeClient.Get(ctx, s.Deployment, deployment); err != nil {
+			if errors.IsNotFound(err) {
+				s.Completed = true
+				continue
+			}
+			errs[i] = fmt.Errorf("getting deployment, %w", err)
+			continue
+		}
+		if deployment.Status.ObservedGeneration < deployment.Generation || deployment.Status.ReadyReplicas < s.Replicas {
+			errs[i] = serrors.Wrap(fmt.Errorf("surged replicas not ready"), "Deployment", klog.KRef(s.Deployment.Namespace, s.Deployment.Name), "ready-replicas", deployment.Status.ReadyReplicas, "replicas", s.Replicas)
+			continue
+		}
+		if err := q.restoreDeployment(ctx, s.Deployment); client.IgnoreNotFound(err) != nil {
+			errs[i] = fmt.Errorf("scaling down deployment, %w", err)
+			continue
+		}
+		log.FromContext(ctx).WithValues("Deployment", klog.KRef(s.Deployment.Namespace, s.Deployment.Name)).V(1).Info("completed surge")
+		s.Completed = true
+	}
+	return multierr.Combine(errs...)
+}
+
+// startSurge marks the surged pods with the lowest deletion cost and scales up their Deployment
+func (q *Queue) startSurge(ctx context.Context, cmd *Command, s *Surge) error {
+	for _, p := range s.Pods {
+		if err := q.patchDeletionCost(ctx, p.key(), lo.ToPtr(surgeDeletionCost)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("patching pod deletion cost, %w", err)
+		}
+	}
+	replicas, err := q.scaleUpDeployment(ctx, s.Deployment, &surgeState{
+		ProviderIDs: lo.Map(cmd.Candidates, func(c *Candidate, _ int) string { return c.ProviderID() }),
+		Pods:        s.Pods,
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.Completed = true
+			return nil
+		}
+		return fmt.Errorf("scaling up deployment, %w", err)
+	}
+	log.FromContext(ctx).WithValues("Deployment", klog.KRef(s.Deployment.Namespace, s.Deployment.Name), "replicas", replicas).Info("surging deployment")
+	s.Replicas = replicas
+	s.Started = true
+	return nil
+}
+
+// revertSurges restores the pods and Deployments of surges that haven't completed when a command fails
+func (q *Queue) revertSurges(ctx context.Context, cmd *Command) error {
+	var errs []error
+	for _, s := range cmd.Surges {
+		if !s.Started || s.Completed {
+			continue
+		}
+		if err := q.revertSurge(ctx, s.Deployment, s.Pods); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s.Completed = true
+	}
+	return multierr.Combine(errs...)
+}
+
+// RevertOrphanedSurges reverts the surges of Deployments whose command is no longer in the queue. Commands are only
+// tracked in memory, so a surge is orphaned if Karpenter restarts while the Deployment is surged.
+func (q *Queue) RevertOrphanedSurges(ctx context.Context) error {
+	deployments := &appsv1.DeploymentList{}
+	if err := q.kubeClient.List(ctx, deployments); err != nil {
+		return fmt.Errorf("listing deployments, %w", err)
+	}
+	var errs []error
+	for i := range deployments.Items {
+		state, ok, err := surgeStateFor(&deployments.Items[i])
+		if err != nil {
+			errs = append(errs, serrors.Wrap(err, "Deployment", klog.KObj(&deployments.Items[i])))
+			continue
+		}
+		if !ok || q.HasAny(state.ProviderIDs...) {
+			continue
+		}
+		if err := q.revertSurge(ctx, client.ObjectKeyFromObject(&deployments.Items[i]), state.Pods); err != nil {
+			errs = append(errs, serrors.Wrap(err, "Deployment", klog.KObj(&deployments.Items[i])))
+			continue
+		}
+		log.FromContext(ctx).WithValues("Deployment", klog.KObj(&deployments.Items[i])).Info("reverted orphaned surge")
+	}
+	return multierr.Combine(errs...)
+}
+
+// revertSurge restores the deletion costs of the surged pods and the replicas of their Deployment
+func (q *Queue) revertSurge(ctx context.Context, key types.NamespacedName, pods []*SurgedPod) error {
+	var errs []error
+	// Restore the deletion costs first, so that the ReplicaSet removes the additional replicas when it's scaled down
+	for _, p := range pods {
+		if err := q.patchDeletionCost(ctx, p.key(), p.DeletionCost); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("restoring pod deletion cost, %w", err))
+		}
+	}
+	if err := q.restoreDeployment(ctx, key); client.IgnoreNotFound(err) != nil {
+		errs = append(errs, fmt.Errorf("scaling down deployment, %w", err))
+	}
+	return multierr.Combine(errs...)
+}
+
+// surgesFor returns the surges for the candidates' pods that are owned by a Deployment and that opted into surge drain,
+// either through the pod's annotation or the NodePool's surge drain policy. Deployments that are scaled by a
+// HorizontalPodAutoscaler aren't surged, since the autoscaler owns their replicas.
+func (q *Queue) surgesFor(ctx context.Context, cmd *Command) ([]*Surge, error) {
+	surges := []*Surge{}
+	for _, c := range cmd.Candidates {
+		for _, pod := range c.reschedulablePods {
+			deployment, err := q.owningDeployment(ctx, pod)
+			if err != nil {
+				return nil, err
+			}
+			if deployment == nil {
+				continue
+			}
+			if pod.Annotations[v1.SurgeDrainAnnotationKey] != "true" &&
+				(c.NodePool.Spec.Disruption.SurgeDrainPolicy != v1.SurgeDrainPolicyWhenSingleReplica || lo.FromPtr(deployment.Spec.Replicas) != 1) {
+				continue
+			}
+			autoscaled, err := q.autoscaled(ctx, deployment)
+			if err != nil {
+				return nil, err
+			}
+			if autoscaled {
+				continue
+			}
+			key := client.ObjectKeyFromObject(deployment)
+			s, ok := lo.Find(surges, func(s *Surge) bool { return s.Deployment == key })
+			if !ok {
+				s = &Surge{Deployment: key}
+				surges = append(surges, s)
+			}
+			deletionCost, hasDeletionCost := pod.Annotations[corev1.PodDeletionCost]
+			s.Pods = append(s.Pods, &SurgedPod{
+				Namespace:    pod.Namespace,
+				Name:         pod.Name,
+				DeletionCost: lo.Ternary(hasDeletionCost, lo.ToPtr(deletionCost), nil),
+			})
+		}
+	}
+	return surges, nil
+}
+
+// owningDeployment returns the Deployment that controls the pod's ReplicaSet, or nil if the pod isn't owned by a Deployment
+func (q *Queue) owningDeployment(ctx context.Context, pod *corev1.Pod) (*appsv1.Deployment, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil || ref.Kind != "ReplicaSet" {
+		return nil, nil
+	}
+	rs := &appsv1.ReplicaSet{}
+	if err := q.kubeClient.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}, rs); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	ref = metav1.GetControllerOf(rs)
+	if ref == nil || ref.Kind != "Deployment" {
+		return nil, nil
+	}
+	deployment := &appsv1.Deployment{}
+	if err := q.kubeClient.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}, deployment); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return deployment, nil
+}
+
+// autoscaled returns true if a HorizontalPodAutoscaler scales the Deployment
+func (q *Queue) autoscaled(ctx context.Context, deployment *appsv1.Deployment) (bool, error) {
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := q.kubeClient.List(ctx, hpas, client.InNamespace(deployment.Namespace)); err != nil {
+		return false, fmt.Errorf("listing horizontal pod autoscalers, %w", err)
+	}
+	return lo.ContainsBy(hpas.Items, func(hpa autoscalingv2.HorizontalPodAutoscaler) bool {
+		return hpa.Spec.ScaleTargetRef.Kind == "Deployment" && hpa.Spec.ScaleTargetRef.Name == deployment.Name
+	}), nil
+}
+
+// scaleUpDeployment records the Deployment's replicas in the surge state and scales the Deployment up by the number of
+// surged pods. It returns the number of replicas the Deployment was scaled up to.
+func (q *Queue) scaleUpDeployment(ctx context.Context, key types.NamespacedName, state *surgeState) (int32, error) {
+	var replicas int32
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		deployment := &appsv1.Deployment{}
+		if err := q.kubeClient.Get(ctx, key, deployment); err != nil {
+			return err
+		}
+		current, ok, err := surgeStateFor(deployment)
+		if err != nil {
+			return err
+		}
+		if ok {
+			// The Deployment was already surged by this command, e.g. if the response to a previous patch was lost
+			if slices.Equal(current.ProviderIDs, state.ProviderIDs) {
+				replicas = current.Replicas + int32(len(current.Pods)) //nolint:gosec
+				return nil
+			}
+			return fmt.Errorf("deployment is surged by another command")
+		}
+		stored := deployment.DeepCopy()
+		state.Replicas = lo.FromPtrOr(deployment.Spec.Replicas, 1)
+		raw, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		replicas = state.Replicas + int32(len(state.Pods)) //nolint:gosec
+		deployment.Spec.Replicas = lo.ToPtr(replicas)
+		deployment.Annotations = lo.Assign(deployment.Annotations, map[string]string{v1.SurgeDrainStateAnnotationKey: string(raw)})
+		// We use client.MergeFromWithOptimisticLock so that concurrent changes to the replicas aren't overwritten
+		return q.kubeClient.Patch(ctx, deployment, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{}))
+	})
+	return replicas, err
+}
+
+// restoreDeployment scales the Deployment back to the replicas it had before it was surged and removes its surge state.
+// If the replicas were changed while the Deployment was surged, e.g. by a user scaling the Deployment, the change is
+// kept and only the surge state is removed.
+func (q *Queue) restoreDeployment(ctx context.Context, key types.NamespacedName) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		deployment := &appsv1.Deployment{}
+		if err := q.kubeClient.Get(ctx, key, deployment); err != nil {
+			return err
+		}
+		state, ok, err := surgeStateFor(deployment)
+		if err != nil || !ok {
+			return err
+		}
+		stored := deployment.DeepCopy()
+		if lo.FromPtrOr(deployment.Spec.Replicas, 1) == state.Replicas+int32(len(state.Pods)) { //nolint:gosec
+			deployment.Spec.Replicas = lo.ToPtr(state.Replicas)
+		}
+		delete(deployment.Annotations, v1.SurgeDrainStateAnnotationKey)
+		// We use client.MergeFromWithOptimisticLock so that concurrent changes to the replicas aren't overwritten
+		return q.kubeClient.Patch(ctx, deployment, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{}))
+	})
+}
+
+// patchDeletionCost sets the pod's deletion cost annotation, or removes it if the deletion cost is nil
+func (q *Queue) patchDeletionCost(ctx context.Context, key types.NamespacedName, deletionCost *string) error {
+	pod := &corev1.Pod{}
+	if err := q.kubeClient.Get(ctx, key, pod); err != nil {
+		return err
+	}
+	stored := pod.DeepCopy()
+	if deletionCost != nil {
+		pod.Annotations = lo.Assign(pod.Annotations, map[string]string{corev1.PodDeletionCost: *deletionCost})
+	} else {
+		delete(pod.Annotations, corev1.PodDeletionCost)
+	}
+	return q.kubeClient.Patch(ctx, pod, client.MergeFrom(stored))
+}
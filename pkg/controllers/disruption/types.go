@@ -35,6 +35,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	pscheduling "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
@@ -94,7 +95,8 @@ type Candidate struct {
 type ScoreResult struct {
 	SavingsFraction    float64
 	DisruptionFraction float64
-	K                  int32
+	// K is the scoring parameter of the NodePool the move was scored against, see Disruption.GetBalancedK.
+	K int32
 }
 
 // Score is savings/disruption, guarded for zero denominators and non-positive savings.
@@ -134,10 +136,10 @@ func resolveNodePrice(node *state.StateNode, instanceType *cloudprovider.Instanc
 // nodes need higher weight. See designs/balanced-consolidation.md.
 const PerNodeBaseDisruptionCost = 1.0
 
-func computeRescheduleDisruptionCost(ctx context.Context, reschedulablePods []*corev1.Pod) float64 {
+func computeRescheduleDisruptionCost(ctx context.Context, reschedulablePods []*corev1.Pod, namespaceCosts disruptionutils.NamespaceCosts) float64 {
 	cost := PerNodeBaseDisruptionCost
 	for _, p := range reschedulablePods {
-		cost += math.Max(0, disruptionutils.EvictionCost(ctx, p))
+		cost += math.Max(0, disruptionutils.EvictionCost(ctx, p, namespaceCosts))
 	}
 	return cost
 }
@@ -197,6 +199,10 @@ func NewCandidate(ctx context.Context, kubeClient client.Client, recorder events
 		}
 	}
 	reschedulable := lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return pod.IsReschedulable(p) })
+	namespaceCosts, err := disruptionutils.GetNamespaceCosts(ctx, kubeClient, pods...)
+	if err != nil {
+		return nil, fmt.Errorf("getting namespace disruption costs, %w", err)
+	}
 	return &Candidate{
 		StateNode:         node,
 		instanceType:      instanceType,
@@ -205,9 +211,9 @@ func NewCandidate(ctx context.Context, kubeClient client.Client, recorder events
 		zone:              node.Labels()[corev1.LabelTopologyZone],
 		reschedulablePods: reschedulable,
 		// We get the disruption cost from all pods in the candidate, not just the reschedulable pods
-		DisruptionCost:           disruptionutils.ReschedulingCost(ctx, pods) * disruptionutils.LifetimeRemaining(clk, nodePool, node.NodeClaim),
+		DisruptionCost:           disruptionutils.ReschedulingCost(ctx, pods, namespaceCosts) * disruptionutils.LifetimeRemaining(clk, nodePool, node.NodeClaim),
 		Price:                    resolveNodePrice(node, instanceType),
-		RescheduleDisruptionCost: computeRescheduleDisruptionCost(ctx, reschedulable),
+		RescheduleDisruptionCost: computeRescheduleDisruptionCost(ctx, reschedulable, namespaceCosts),
 	}, nil
 }
 
@@ -221,6 +227,16 @@ type Replacement struct {
 	Initialized bool
 }
 
+// capacityType returns the most preferred capacity type that the replacement may launch with
+func (r *Replacement) capacityType() string {
+	ct := r.Requirements.Get(v1.CapacityTypeLabelKey)
+	return lo.If(
+		ct.Has(v1.CapacityTypeReserved), v1.CapacityTypeReserved,
+	).ElseIf(
+		ct.Has(v1.CapacityTypeSpot), v1.CapacityTypeSpot,
+	).Else(v1.CapacityTypeOnDemand)
+}
+
 func replacementsFromNodeClaims(newNodeClaims ...*pscheduling.NodeClaim) []*Replacement {
 	return lo.Map(newNodeClaims, func(n *pscheduling.NodeClaim, _ int) *Replacement { return &Replacement{NodeClaim: n} })
 }
@@ -237,6 +253,11 @@ type Command struct {
 	Candidates          []*Candidate
 	Replacements        []*Replacement
 	PoolDisruptionCosts map[string]float64
+	// Surges are the Deployments that are scaled up before the candidates are deleted, nil until they're resolved
+	Surges []*Surge
+
+	// planPhase is the last phase recorded for the command's DisruptionPlan, empty if none was recorded
+	planPhase v1alpha1.DisruptionPlanPhase
 }
 
 // Reason returns the disruption reason for this command.
@@ -399,25 +420,28 @@ func (c Command) EmitRejectedEvents(recorder events.Recorder, reason string) {
 	}
 }
 
+// EmitDryRunEvents emits DisruptionDryRun events for all candidates in this command
+func (c Command) EmitDryRunEvents(recorder events.Recorder) {
+	for _, candidate := range c.Candidates {
+		recorder.Publish(disruptionevents.DryRun(candidate.Node, candidate.NodeClaim, c.StringForNode(candidate), string(c.Reason()), c.EstimatedSavings())...)
+	}
+}
+
 func (c Command) LogValues() []any {
 	podCount := lo.Reduce(c.Candidates, func(acc int, cd *Candidate, _ int) int { return acc + len(cd.reschedulablePods) }, 0)
 
 	candidateNodes := lo.Map(c.Candidates, func(candidate *Candidate, _ int) any {
 		return map[string]any{
-			"Node":          klog.KObj(candidate.Node),
-			"NodeClaim":     klog.KObj(candidate.NodeClaim),
-			"instance-type": candidate.Labels()[corev1.LabelInstanceTypeStable],
-			"capacity-type": candidate.Labels()[v1.CapacityTypeLabelKey],
+			"Node":            klog.KObj(candidate.Node),
+			"NodeClaim":       klog.KObj(candidate.NodeClaim),
+			"instance-type":   candidate.Labels()[corev1.LabelInstanceTypeStable],
+			"capacity-type":   candidate.Labels()[v1.CapacityTypeLabelKey],
+			"disruption-cost": candidate.RescheduleDisruptionCost,
 		}
 	})
 	replacementNodes := lo.Map(c.Replacements, func(replacement *Replacement, _ int) any {
-		ct := replacement.Requirements.Get(v1.CapacityTypeLabelKey)
 		m := map[string]any{
-			"capacity-type": lo.If(
-				ct.Has(v1.CapacityTypeReserved), v1.CapacityTypeReserved,
-			).ElseIf(
-				ct.Has(v1.CapacityTypeSpot), v1.CapacityTypeSpot,
-			).Else(v1.CapacityTypeOnDemand),
+			"capacity-type": replacement.capacityType(),
 		}
 		if len(c.Replacements) == 1 {
 			m["instance-types"] = pscheduling.InstanceTypeList(replacement.InstanceTypeOptions)
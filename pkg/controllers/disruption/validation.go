@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/samber/lo"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -107,6 +108,13 @@ type validation struct {
 	reason        v1.DisruptionReason
 }
 
+// publishPending assigns the command its ID and publishes its DisruptionPlan while the command waits to be validated
+func (v *validation) publishPending(ctx context.Context, cmd Command) Command {
+	cmd.ID = uuid.New()
+	v.queue.publishPendingPlan(ctx, cmd, v.reason)
+	return cmd
+}
+
 type EmptinessValidator struct {
 	validation
 	filter         CandidateFilter
@@ -133,6 +141,7 @@ func NewEmptinessValidator(c consolidation) *EmptinessValidator {
 
 func (e *EmptinessValidator) Validate(ctx context.Context, cmd Command, validationPeriod time.Duration) (Command, error) {
 	if validationPeriod > 0 {
+		cmd = e.publishPending(ctx, cmd)
 		select {
 		case <-ctx.Done():
 			return Command{}, errors.New("interrupted")
@@ -190,6 +199,9 @@ func NewMultiConsolidationValidator(c consolidation) *ConsolidationValidator {
 }
 
 func (c *ConsolidationValidator) Validate(ctx context.Context, cmd Command, validationPeriod time.Duration) (Command, error) {
+	if validationPeriod > 0 {
+		cmd = c.publishPending(ctx, cmd)
+	}
 	if err := c.isValid(ctx, cmd, validationPeriod); err != nil {
 		return Command{}, err
 	}
@@ -0,0 +1,186 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	opmetrics "github.com/awslabs/operatorpkg/metrics"
+	"github.com/awslabs/operatorpkg/reconciler"
+	"github.com/awslabs/operatorpkg/singleton"
+	"github.com/prometheus/client_golang/prometheus"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+const (
+	reservationIDLabel    = "reservation_id"
+	reservationsSubsystem = "reservations"
+)
+
+var (
+	Nodes = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: reservationsSubsystem,
+			Name:      "nodes",
+			Help:      "The number of nodes that Karpenter launched into a capacity reservation. Labeled by reservation ID.",
+		},
+		[]string{reservationIDLabel},
+	)
+	AvailableCapacity = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: reservationsSubsystem,
+			Name:      "available_capacity",
+			Help:      "The number of instances that can still be launched into a capacity reservation, as reported by the cloud provider. Labeled by reservation ID.",
+		},
+		[]string{reservationIDLabel},
+	)
+	Utilization = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: reservationsSubsystem,
+			Name:      "utilization",
+			Help:      "The fraction of a capacity reservation that is used by nodes that Karpenter launched into it. Labeled by reservation ID.",
+		},
+		[]string{reservationIDLabel},
+	)
+	EndTimeSeconds = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: reservationsSubsystem,
+			Name:      "end_time_seconds",
+			Help:      "The unix time that a capacity reservation ends. Only emitted for reservations that end. Labeled by reservation ID.",
+		},
+		[]string{reservationIDLabel},
+	)
+)
+
+// Controller emits metrics on the utilization of the capacity reservations that the managed NodePools can launch into
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	cluster       *state.Cluster
+	metricStore   *metrics.Store
+}
+
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		cluster:       cluster,
+		metricStore:   metrics.NewStore(),
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
+	ctx = injection.WithControllerName(ctx, c.Name())
+
+	if !options.FromContext(ctx).FeatureGates.ReservedCapacity || cloudprovider.ReservationIDLabel == "" {
+		c.metricStore.ReplaceAll(nil)
+		return reconciler.Result{RequeueAfter: time.Minute}, nil
+	}
+	offerings, err := c.reservedOfferings(ctx)
+	if err != nil {
+		return reconciler.Result{}, err
+	}
+	nodes := map[string]int{}
+	for n := range c.cluster.Nodes() {
+		if n.Labels()[v1.CapacityTypeLabelKey] != v1.CapacityTypeReserved {
+			continue
+		}
+		if id, ok := n.Labels()[cloudprovider.ReservationIDLabel]; ok {
+			nodes[id]++
+		}
+	}
+	metricsMap := map[string][]*metrics.StoreMetric{}
+	for id, o := range offerings {
+		metricsMap[id] = buildMetrics(id, o, nodes[id])
+	}
+	c.metricStore.ReplaceAll(metricsMap)
+	return reconciler.Result{RequeueAfter: 30 * time.Second}, nil
+}
+
+// reservedOfferings returns the reserved offerings of the managed NodePools' instance types by reservation ID. If
+// multiple offerings share a reservation, the one with the least capacity is used, as in the scheduler.
+func (c *Controller) reservedOfferings(ctx context.Context) (map[string]*cloudprovider.Offering, error) {
+	nodePools, err := nodepoolutils.ListManaged(ctx, c.kubeClient, c.cloudProvider)
+	if err != nil {
+		return nil, fmt.Errorf("listing nodepools, %w", err)
+	}
+	offerings := map[string]*cloudprovider.Offering{}
+	for _, np := range nodePools {
+		its, err := c.cloudProvider.GetInstanceTypes(ctx, np)
+		if err != nil {
+			return nil, fmt.Errorf("getting instance types for nodepool %q, %w", np.Name, err)
+		}
+		for _, it := range its {
+			for _, o := range it.Offerings {
+				if o.CapacityType() != v1.CapacityTypeReserved {
+					continue
+				}
+				if current, ok := offerings[o.ReservationID()]; !ok || current.ReservationCapacity > o.ReservationCapacity {
+					offerings[o.ReservationID()] = o
+				}
+			}
+		}
+	}
+	return offerings, nil
+}
+
+func buildMetrics(reservationID string, offering *cloudprovider.Offering, nodes int) []*metrics.StoreMetric {
+	labels := map[string]string{reservationIDLabel: reservationID}
+	res := []*metrics.StoreMetric{
+		{GaugeMetric: Nodes, Labels: labels, Value: float64(nodes)},
+		{GaugeMetric: AvailableCapacity, Labels: labels, Value: float64(offering.ReservationCapacity)},
+	}
+	if total := nodes + offering.ReservationCapacity; total > 0 {
+		res = append(res, &metrics.StoreMetric{GaugeMetric: Utilization, Labels: labels, Value: float64(nodes) / float64(total)})
+	}
+	if !offering.ReservationEndTime.IsZero() {
+		res = append(res, &metrics.StoreMetric{GaugeMetric: EndTimeSeconds, Labels: labels, Value: float64(offering.ReservationEndTime.Unix())})
+	}
+	return res
+}
+
+func (c *Controller) Name() string {
+	return "metrics.reservation"
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named(c.Name()).
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(c))
+}
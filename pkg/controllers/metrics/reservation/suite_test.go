@@ -0,0 +1,148 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/metrics/reservation"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/state/cost"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var env *test.Environment
+var cluster *state.Cluster
+var nodeController *informer.NodeController
+var nodeClaimController *informer.NodeClaimController
+var reservationController *reservation.Controller
+var cloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ReservationMetrics")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+
+	ctx = options.ToContext(ctx, test.Options())
+	cloudProvider = fake.NewCloudProvider()
+	cluster = state.NewCluster(env.Clock, env.Client, cloudProvider)
+	clusterCost := cost.NewClusterCost(ctx, cloudProvider, env.Client)
+	nodeController = informer.NewNodeController(env.Client, cluster)
+	nodeClaimController = informer.NewNodeClaimController(env.Client, cloudProvider, cluster, clusterCost)
+	reservationController = reservation.NewController(env.Client, cloudProvider, cluster)
+})
+
+var _ = AfterSuite(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("Reservation Metrics", func() {
+	var nodePool *v1.NodePool
+	var reservedOffering *cloudprovider.Offering
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		reservedOffering = &cloudprovider.Offering{
+			Available:           true,
+			ReservationCapacity: 3,
+			ReservationEndTime:  metav1.NewTime(env.Clock.Now().Add(time.Hour).Truncate(time.Second)),
+			Requirements: scheduling.NewLabelRequirements(map[string]string{
+				v1.CapacityTypeLabelKey:     v1.CapacityTypeReserved,
+				corev1.LabelTopologyZone:    "test-zone-1",
+				v1alpha1.LabelReservationID: "r-1",
+			}),
+		}
+		it := fake.NewInstanceType("reserved-instance-type")
+		it.Offerings = append(it.Offerings, reservedOffering)
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{it}
+	})
+	AfterEach(func() {
+		ExpectCleanedUp(ctx, env.Client)
+		cloudProvider.Reset()
+		cluster.Reset()
+	})
+	It("should emit the utilization of a capacity reservation", func() {
+		nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:         nodePool.Name,
+					v1.CapacityTypeLabelKey:     v1.CapacityTypeReserved,
+					v1alpha1.LabelReservationID: "r-1",
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeController, nodeClaimController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		ExpectSingletonReconciled(ctx, reservationController)
+
+		labels := map[string]string{"reservation_id": "r-1"}
+		metric, found := FindMetricWithLabelValues("karpenter_reservations_nodes", labels)
+		Expect(found).To(BeTrue())
+		Expect(metric.GetGauge().GetValue()).To(BeNumerically("==", 1))
+		metric, found = FindMetricWithLabelValues("karpenter_reservations_available_capacity", labels)
+		Expect(found).To(BeTrue())
+		Expect(metric.GetGauge().GetValue()).To(BeNumerically("==", 3))
+		metric, found = FindMetricWithLabelValues("karpenter_reservations_utilization", labels)
+		Expect(found).To(BeTrue())
+		Expect(metric.GetGauge().GetValue()).To(BeNumerically("~", 0.25))
+		metric, found = FindMetricWithLabelValues("karpenter_reservations_end_time_seconds", labels)
+		Expect(found).To(BeTrue())
+		Expect(metric.GetGauge().GetValue()).To(BeNumerically("==", reservedOffering.ReservationEndTime.Unix()))
+	})
+	It("should remove the metrics when the reservation is no longer offered", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectSingletonReconciled(ctx, reservationController)
+		_, found := FindMetricWithLabelValues("karpenter_reservations_available_capacity", map[string]string{"reservation_id": "r-1"})
+		Expect(found).To(BeTrue())
+
+		cloudProvider.InstanceTypes[0].Offerings = cloudProvider.InstanceTypes[0].Offerings[:len(cloudProvider.InstanceTypes[0].Offerings)-1]
+		ExpectSingletonReconciled(ctx, reservationController)
+		_, found = FindMetricWithLabelValues("karpenter_reservations_available_capacity", map[string]string{"reservation_id": "r-1"})
+		Expect(found).To(BeFalse())
+	})
+	It("shouldn't emit metrics when the ReservedCapacity feature gate is disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{ReservedCapacity: new(false)}}))
+		DeferCleanup(func() { ctx = options.ToContext(ctx, test.Options()) })
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectSingletonReconciled(ctx, reservationController)
+		_, found := FindMetricWithLabelValues("karpenter_reservations_available_capacity", map[string]string{"reservation_id": "r-1"})
+		Expect(found).To(BeFalse())
+	})
+})
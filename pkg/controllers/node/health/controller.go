@@ -46,6 +46,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
 	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
+	disruptionutils "sigs.k8s.io/karpenter/pkg/utils/disruption"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
@@ -55,19 +56,21 @@ var allowedUnhealthyPercent = intstr.FromString("20%")
 
 // Controller for the resource
 type Controller struct {
-	clock         clock.Clock
-	recorder      events.Recorder
-	kubeClient    client.Client
-	cloudProvider cloudprovider.CloudProvider
+	clock           clock.Clock
+	recorder        events.Recorder
+	kubeClient      client.Client
+	cloudProvider   cloudprovider.CloudProvider
+	forcefulBudgets *disruptionutils.ForcefulBudgets
 }
 
 // NewController constructs a controller instance
-func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, clock clock.Clock, recorder events.Recorder) *Controller {
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, clock clock.Clock, recorder events.Recorder, forcefulBudgets *disruptionutils.ForcefulBudgets) *Controller {
 	return &Controller{
-		clock:         clock,
-		recorder:      recorder,
-		kubeClient:    kubeClient,
-		cloudProvider: cloudProvider,
+		clock:           clock,
+		recorder:        recorder,
+		kubeClient:      kubeClient,
+		cloudProvider:   cloudProvider,
+		forcefulBudgets: forcefulBudgets,
 	}
 }
 
@@ -140,7 +143,7 @@ func (c *Controller) Reconcile(ctx context.Context, node *corev1.Node) (reconcil
 			return reconcile.Result{}, client.IgnoreNotFound(err)
 		}
 		if !nodePoolHealthy {
-			if err := c.publishNodePoolHealthEvent(ctx, node, nodeClaim, nodePoolName); err != nil {
+			if err := c.publishNodePoolEvent(ctx, node, nodeClaim, nodePoolName, fmt.Sprintf("more than %s nodes are unhealthy in the nodepool", allowedUnhealthyPercent.String())); err != nil {
 				return reconcile.Result{}, err
 			}
 			return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
@@ -155,8 +158,20 @@ func (c *Controller) Reconcile(ctx context.Context, node *corev1.Node) (reconcil
 			return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
 		}
 	}
+	// If the NodePool has a Repaired budget, wait until it allows the node to be repaired
+	delay, err := c.forcefulBudgets.Reserve(ctx, c.kubeClient, c.clock, nodeClaim, v1.DisruptionReasonRepaired, terminationTime)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if delay > 0 {
+		if err := c.publishNodePoolEvent(ctx, node, nodeClaim, nodePoolName, fmt.Sprintf("disruption budget for %s nodes is exhausted", v1.DisruptionReasonRepaired)); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
 	// For unhealthy past the tolerationDisruption window we can forcefully terminate the node
 	if err := c.annotateTerminationGracePeriod(ctx, nodeClaim); err != nil {
+		c.forcefulBudgets.Release(nodeClaim)
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
 	return c.deleteNodeClaim(ctx, nodeClaim, node, unhealthyNodeCondition)
@@ -168,6 +183,7 @@ func (c *Controller) deleteNodeClaim(ctx context.Context, nodeClaim *v1.NodeClai
 		return reconcile.Result{}, nil
 	}
 	if err := c.kubeClient.Delete(ctx, nodeClaim); err != nil {
+		c.forcefulBudgets.Release(nodeClaim)
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
 	// The deletion timestamp has successfully been set for the Node, update relevant metrics.
@@ -265,11 +281,11 @@ func (c *Controller) areNodesHealthy(ctx context.Context, opts ...client.ListOpt
 	return unhealthyNodeCount <= threshold, nil
 }
 
-func (c *Controller) publishNodePoolHealthEvent(ctx context.Context, node *corev1.Node, nodeClaim *v1.NodeClaim, npName string) error {
+func (c *Controller) publishNodePoolEvent(ctx context.Context, node *corev1.Node, nodeClaim *v1.NodeClaim, npName string, reason string) error {
 	np := &v1.NodePool{}
 	if err := c.kubeClient.Get(ctx, types.NamespacedName{Name: npName}, np); err != nil {
 		return client.IgnoreNotFound(err)
 	}
-	c.recorder.Publish(NodeRepairBlocked(node, nodeClaim, np, fmt.Sprintf("more than %s nodes are unhealthy in the nodepool", allowedUnhealthyPercent.String()))...)
+	c.recorder.Publish(NodeRepairBlocked(node, nodeClaim, np, reason)...)
 	return nil
 }
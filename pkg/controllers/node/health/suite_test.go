@@ -33,10 +33,12 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/health"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
+	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	disruptionutils "sigs.k8s.io/karpenter/pkg/utils/disruption"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 	. "sigs.k8s.io/karpenter/pkg/utils/testing"
 )
@@ -63,8 +65,8 @@ var _ = BeforeSuite(func() {
 	cloudProvider = fake.NewCloudProvider()
 	cloudProvider = fake.NewCloudProvider()
 	recorder = test.NewEventRecorder()
-	queue = terminator.NewQueue(env.Client, recorder)
-	healthController = health.NewController(env.Client, cloudProvider, env.Clock, recorder)
+	queue = terminator.NewQueue(env.Client, recorder, env.Clock)
+	healthController = health.NewController(env.Client, cloudProvider, env.Clock, recorder, disruptionutils.NewForcefulBudgets())
 })
 
 var _ = AfterSuite(func() {
@@ -270,6 +272,42 @@ var _ = Describe("Node Health", func() {
 			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 			Expect(nodeClaim.DeletionTimestamp).ToNot(BeNil())
 		})
+		It("should wait for budgets that list the Repaired reason", func() {
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "0", Reasons: []v1.DisruptionReason{v1.DisruptionReasonRepaired}}}
+			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+				Type:               "BadNode",
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.Time{Time: env.Clock.Now()},
+			})
+			env.Clock.Step(60 * time.Minute)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			// Determine to delete unhealthy nodes
+			result := ExpectObjectReconciled(ctx, env.Client, healthController, node)
+			Expect(result.RequeueAfter).To(Equal(disruptionutils.ForcefulBudgetPollPeriod))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.DeletionTimestamp).To(BeNil())
+			Expect(recorder.Calls(events.NodeRepairBlocked)).To(BeNumerically(">", 0))
+		})
+		It("should repair nodes once they've waited for forcefulBudgetMaxWait", func() {
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "0", Reasons: []v1.DisruptionReason{v1.DisruptionReasonRepaired}}}
+			nodePool.Spec.Disruption.ForcefulBudgetMaxWait = &metav1.Duration{Duration: 10 * time.Minute}
+			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+				Type:               "BadNode",
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.Time{Time: env.Clock.Now()},
+			})
+			// The node is repairable after 30 minutes and then waits up to 10 minutes for the budget
+			env.Clock.Step(35 * time.Minute)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			result := ExpectObjectReconciled(ctx, env.Client, healthController, node)
+			Expect(result.RequeueAfter).To(Equal(disruptionutils.ForcefulBudgetPollPeriod))
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp).To(BeNil())
+
+			env.Clock.Step(5 * time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, healthController, node)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp).ToNot(BeNil())
+		})
 		It("should ignore do-not-disrupt on a node", func() {
 			node.Annotations = map[string]string{v1.DoNotDisruptAnnotationKey: "true"}
 			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
@@ -24,6 +24,7 @@ import (
 	"github.com/awslabs/operatorpkg/serrors"
 	"github.com/awslabs/operatorpkg/status"
 	"github.com/samber/lo"
+	"go.uber.org/multierr"
 	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
@@ -64,21 +65,23 @@ const (
 
 // Controller for the resource
 type Controller struct {
-	clock         clock.Clock
-	kubeClient    client.Client
-	cloudProvider cloudprovider.CloudProvider
-	terminator    *terminator.Terminator
-	recorder      events.Recorder
+	clock            clock.Clock
+	kubeClient       client.Client
+	cloudProvider    cloudprovider.CloudProvider
+	terminator       *terminator.Terminator
+	recorder         events.Recorder
+	terminationHooks []cloudprovider.NodeTerminationHook
 }
 
 // NewController constructs a controller instance
-func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, terminator *terminator.Terminator, recorder events.Recorder) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, terminator *terminator.Terminator, recorder events.Recorder, terminationHooks []cloudprovider.NodeTerminationHook) *Controller {
 	return &Controller{
-		clock:         clk,
-		kubeClient:    kubeClient,
-		cloudProvider: cloudProvider,
-		terminator:    terminator,
-		recorder:      recorder,
+		clock:            clk,
+		kubeClient:       kubeClient,
+		cloudProvider:    cloudProvider,
+		terminator:       terminator,
+		recorder:         recorder,
+		terminationHooks: terminationHooks,
 	}
 }
 
@@ -145,8 +148,10 @@ func (c *Controller) finalize(ctx context.Context, node *corev1.Node) (reconcile
 	var terminationErr error
 	var result reconcile.Result
 	for _, f := range []terminationFunc{
+		c.awaitTerminationHooks(cloudprovider.NodeTerminationHookStagePreDrain),
 		c.awaitDrain,
 		c.awaitVolumeDetachment,
+		c.awaitTerminationHooks(cloudprovider.NodeTerminationHookStagePreDelete),
 		c.awaitInstanceTermination,
 	} {
 		result, terminationErr = f(ctx, nodeClaim, node, nodeTerminationTime)
@@ -192,6 +197,65 @@ func (c *Controller) finalize(ctx context.Context, node *corev1.Node) (reconcile
 
 type terminationFunc func(context.Context, *v1.NodeClaim, *corev1.Node, *time.Time) (reconcile.Result, error)
 
+// awaitTerminationHooks returns a terminationFunc that requeues until all termination hooks of the stage have
+// completed. Hooks are evaluated in parallel and aren't evaluated again once they've completed. Once the nodeClaim's
+// terminationGracePeriod has elapsed at nodeTerminationTime, the remaining hooks are skipped.
+//
+//nolint:gocyclo
+func (c *Controller) awaitTerminationHooks(stage cloudprovider.NodeTerminationHookStage) terminationFunc {
+	return func(ctx context.Context, nodeClaim *v1.NodeClaim, node *corev1.Node, nodeTerminationTime *time.Time) (reconcile.Result, error) {
+		if nodeClaim == nil {
+			return reconcile.Result{}, nil
+		}
+		hooks := lo.Filter(c.terminationHooks, func(h cloudprovider.NodeTerminationHook, _ int) bool {
+			return h.Stage() == stage && !nodeClaim.StatusConditions().IsTrue(v1.TerminationHookConditionType(h.Name()))
+		})
+		if len(hooks) == 0 {
+			return reconcile.Result{}, nil
+		}
+		if c.hasTerminationGracePeriodElapsed(nodeTerminationTime) {
+			for _, h := range hooks {
+				nodeClaim.StatusConditions(status.WithClock(c.clock)).SetFalse(v1.TerminationHookConditionType(h.Name()), "TerminationGracePeriodElapsed", "TerminationGracePeriodElapsed")
+			}
+			return reconcile.Result{}, nil
+		}
+		results := make([]cloudprovider.NodeLifecycleHookResult, len(hooks))
+		errs := make([]error, len(hooks))
+		workqueue.ParallelizeUntil(ctx, len(hooks), len(hooks), func(i int) {
+			results[i], errs[i] = hooks[i].Terminating(ctx, nodeClaim, node)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("termination hook %q failed, %w", hooks[i].Name(), errs[i])
+			}
+		})
+
+		// Surface the status of each hook and compute the shortest requeue interval of the pending hooks
+		var pendingHooks []string
+		mergedResult := reconcile.Result{}
+		for i, h := range hooks {
+			conditionType := v1.TerminationHookConditionType(h.Name())
+			switch {
+			case errs[i] != nil:
+				nodeClaim.StatusConditions(status.WithClock(c.clock)).SetUnknownWithReason(conditionType, "TerminationHookFailed", errs[i].Error())
+			case !lo.IsEmpty(results[i]):
+				nodeClaim.StatusConditions(status.WithClock(c.clock)).SetUnknownWithReason(conditionType, "TerminationHookPending", "TerminationHookPending")
+				if mergedResult.RequeueAfter == 0 || (results[i].RequeueAfter > 0 && results[i].RequeueAfter < mergedResult.RequeueAfter) {
+					mergedResult.RequeueAfter = results[i].RequeueAfter
+				}
+				mergedResult.Requeue = mergedResult.Requeue || results[i].Requeue //nolint:staticcheck
+			default:
+				nodeClaim.StatusConditions(status.WithClock(c.clock)).SetTrue(conditionType)
+				continue
+			}
+			pendingHooks = append(pendingHooks, h.Name())
+		}
+		if len(pendingHooks) > 0 {
+			log.FromContext(ctx).V(1).Info("awaiting termination hooks", "stage", stage, "hooks", pendingHooks)
+			return mergedResult, multierr.Combine(errs...)
+		}
+		return reconcile.Result{}, nil
+	}
+}
+
 // awaitDrain initiates the drain of the node and will continue to requeue until the node has been drained and the minimum drain time has passed.
 // If the nodeClaim has a terminationGracePeriod set, pods will be deleted to ensure this function does not requeue past the
 // nodeTerminationTime.
@@ -209,6 +273,10 @@ func (c *Controller) awaitDrain(
 			return reconcile.Result{}, fmt.Errorf("draining node, %w", err)
 		}
 		c.recorder.Publish(terminatorevents.NodeFailedToDrain(node, err))
+		// Surface which pods are still waiting to be evicted, and which PDBs block them, on the Drained condition
+		if nodeClaim != nil {
+			nodeClaim.StatusConditions(status.WithClock(c.clock)).SetUnknownWithReason(v1.ConditionTypeDrained, "Draining", pretty.Sentence(err.Error()))
+		}
 		return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
 	}
 
@@ -27,6 +27,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,6 +38,7 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/apis"
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
@@ -74,8 +76,8 @@ var _ = BeforeSuite(func() {
 
 	cloudProvider = fake.NewCloudProvider()
 	recorder = test.NewEventRecorder()
-	queue = terminator.NewQueue(env.Client, recorder)
-	terminationController = termination.NewController(env.Clock, env.Client, cloudProvider, terminator.NewTerminator(env.Clock, env.Client, queue, recorder), recorder)
+	queue = terminator.NewQueue(env.Client, recorder, env.Clock)
+	terminationController = termination.NewController(env.Clock, env.Client, cloudProvider, terminator.NewTerminator(env.Clock, env.Client, queue, recorder), recorder, nil)
 })
 
 var _ = AfterSuite(func() {
@@ -90,7 +92,7 @@ var _ = Describe("Termination", func() {
 	BeforeEach(func() {
 		env.Clock.SetTime(time.Now())
 		cloudProvider.Reset()
-		*queue = lo.FromPtr(terminator.NewQueue(env.Client, recorder))
+		*queue = lo.FromPtr(terminator.NewQueue(env.Client, recorder, env.Clock))
 
 		nodePool = test.NodePool()
 		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Finalizers: []string{v1.TerminationFinalizer}}})
@@ -412,6 +414,37 @@ var _ = Describe("Termination", func() {
 			ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
 			ExpectNotFound(ctx, env.Client, node)
 		})
+		It("should surface PDBs that block evictions on the Drained condition", func() {
+			minAvailable := intstr.FromInt32(1)
+			labelSelector := map[string]string{test.RandomName(): test.RandomName()}
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels: labelSelector,
+				// Don't let any pod evict
+				MinAvailable: &minAvailable,
+			})
+			podNoEvict := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:          labelSelector,
+					OwnerReferences: defaultOwnerRefs,
+				},
+				Phase: corev1.PodRunning,
+			})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, podNoEvict, pdb)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // DrainInitiation
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, queue, podNoEvict))
+
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained)
+			Expect(cond.IsUnknown()).To(BeTrue())
+			Expect(cond.Message).To(ContainSubstring("1 pods are waiting to be evicted"))
+			Expect(cond.Message).To(ContainSubstring(fmt.Sprintf("%s/%s by %s", podNoEvict.Namespace, podNoEvict.Name, pdb.Name)))
+		})
 		It("should evict pods in order and wait until pods are fully deleted", func() {
 			daemonEvict := test.DaemonSet()
 			daemonNodeCritical := test.DaemonSet(test.DaemonSetOptions{PodOptions: test.PodOptions{PriorityClassName: "system-node-critical"}})
@@ -971,6 +1004,221 @@ var _ = Describe("Termination", func() {
 			})
 		})
 	})
+	Context("Drain Stages", func() {
+		BeforeEach(func() {
+			node.Labels[v1.NodePoolLabelKey] = nodePool.Name
+		})
+		It("should evict non-critical pods that aren't in a drain stage before the stages and critical pods after them", func() {
+			nodePool.Spec.Disruption.DrainStages = []v1.DrainStage{{Name: "ingress", PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ingress"}}}}
+			podIngress := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "ingress"}, OwnerReferences: defaultOwnerRefs}})
+			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			podNodeCritical := test.Pod(test.PodOptions{NodeName: node.Name, PriorityClassName: "system-node-critical", ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, nodePool, node, nodeClaim, podIngress, podEvict, podNodeCritical)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+			podGroups := [][]*corev1.Pod{{podEvict}, {podIngress}, {podNodeCritical}}
+			for i, podGroup := range podGroups {
+				node = ExpectNodeExists(ctx, env.Client, node.Name)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))
+				for _, pod := range podGroup {
+					ExpectObjectReconciled(ctx, env.Client, queue, pod)
+				}
+				EventuallyExpectTerminating(ctx, env.Client, lo.Map(podGroup, func(p *corev1.Pod, _ int) client.Object { return p })...)
+				if i != len(podGroups)-1 {
+					for _, pod := range podGroups[i+1] {
+						Expect(queue.Has(pod)).To(BeFalse())
+					}
+				}
+				ExpectDeleted(ctx, env.Client, lo.Map(podGroup, func(p *corev1.Pod, _ int) client.Object { return p })...)
+			}
+
+			// Reconcile to delete node
+			env.Clock.Step(2 * termination.MinDrainTime)
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // DrainValidation, VolumeDetachment, InstanceTerminationInitiation
+			ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
+			ExpectNotFound(ctx, env.Client, node)
+		})
+		It("should evict drain stages in order", func() {
+			nodePool.Spec.Disruption.DrainStages = []v1.DrainStage{
+				{Name: "apps", PodSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"ingress"}}}}},
+				{Name: "ingress", PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ingress"}}},
+			}
+			podIngress := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "ingress"}, OwnerReferences: defaultOwnerRefs}})
+			podNodeCritical := test.Pod(test.PodOptions{NodeName: node.Name, PriorityClassName: "system-node-critical", ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, nodePool, node, nodeClaim, podIngress, podNodeCritical, podEvict)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+
+			// The apps stage selects both the critical and non-critical pods
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))
+			ExpectObjectReconciled(ctx, env.Client, queue, podEvict)
+			ExpectObjectReconciled(ctx, env.Client, queue, podNodeCritical)
+			EventuallyExpectTerminating(ctx, env.Client, podEvict, podNodeCritical)
+			Expect(queue.Has(podIngress)).To(BeFalse())
+			ExpectDeleted(ctx, env.Client, podEvict, podNodeCritical)
+
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))
+			ExpectObjectReconciled(ctx, env.Client, queue, podIngress)
+			EventuallyExpectTerminating(ctx, env.Client, podIngress)
+		})
+		It("should select pods by their priority", func() {
+			priorityClass := &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: test.RandomName()}, Value: 1000000}
+			nodePool.Spec.Disruption.DrainStages = []v1.DrainStage{{Name: "high-priority", Priority: &v1.PriorityRange{Min: new(int32(1000))}}}
+			podHighPriority := test.Pod(test.PodOptions{NodeName: node.Name, PriorityClassName: priorityClass.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			podHighPriority.Spec.Priority = new(priorityClass.Value)
+			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, priorityClass, nodePool, node, nodeClaim, podHighPriority, podEvict)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))
+			ExpectObjectReconciled(ctx, env.Client, queue, podEvict)
+			EventuallyExpectTerminating(ctx, env.Client, podEvict)
+			Expect(queue.Has(podHighPriority)).To(BeFalse())
+			ExpectDeleted(ctx, env.Client, podEvict)
+
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))
+			ExpectObjectReconciled(ctx, env.Client, queue, podHighPriority)
+			EventuallyExpectTerminating(ctx, env.Client, podHighPriority)
+			ExpectDeleted(ctx, env.Client, priorityClass)
+		})
+		It("should wait before evicting a drain stage", func() {
+			nodePool.Spec.Disruption.DrainStages = []v1.DrainStage{{
+				Name:        "sidecars",
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "sidecar"}},
+				Wait:        &metav1.Duration{Duration: time.Minute},
+			}}
+			podSidecar := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "sidecar"}, OwnerReferences: defaultOwnerRefs}})
+			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			ExpectApplied(ctx, env.Client, nodePool, node, nodeClaim, podSidecar, podEvict)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))
+			ExpectObjectReconciled(ctx, env.Client, queue, podEvict)
+			EventuallyExpectTerminating(ctx, env.Client, podEvict)
+			ExpectDeleted(ctx, env.Client, podEvict)
+
+			// The sidecar stage is next, but its wait hasn't elapsed yet
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))
+			Expect(queue.Has(podSidecar)).To(BeFalse())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			Expect(node.Annotations).To(HaveKeyWithValue(v1.DrainStageAnnotationKey, "sidecars"))
+			Expect(node.Annotations).To(HaveKey(v1.DrainStageStartTimestampAnnotationKey))
+
+			message := ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().Get(v1.ConditionTypeDrained).Message
+			Expect(message).To(ContainSubstring("waiting until"))
+
+			// The wait is resumed rather than restarted by a new terminator, e.g. after the controller restarts
+			restartedController := termination.NewController(env.Clock, env.Client, cloudProvider, terminator.NewTerminator(env.Clock, env.Client, queue, recorder), recorder, nil)
+			env.Clock.Step(30 * time.Second)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, restartedController, node))
+			Expect(queue.Has(podSidecar)).To(BeFalse())
+			// The Drained condition isn't updated while the stage waits
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().Get(v1.ConditionTypeDrained).Message).To(Equal(message))
+
+			env.Clock.Step(30 * time.Second)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, restartedController, node))
+			Expect(queue.Has(podSidecar)).To(BeTrue())
+			ExpectObjectReconciled(ctx, env.Client, queue, podSidecar)
+			EventuallyExpectTerminating(ctx, env.Client, podSidecar)
+		})
+	})
+	Context("Termination Hooks", func() {
+		var hook *testTerminationHook
+		var hookController *termination.Controller
+		BeforeEach(func() {
+			hook = &testTerminationHook{name: "LoadBalancerDeregistration", stage: cloudprovider.NodeTerminationHookStagePreDrain}
+			hookController = termination.NewController(env.Clock, env.Client, cloudProvider, terminator.NewTerminator(env.Clock, env.Client, queue, recorder), recorder, []cloudprovider.NodeTerminationHook{hook})
+		})
+		It("should not drain the node until pre-drain hooks complete", func() {
+			hook.result = cloudprovider.NodeLifecycleHookResult{RequeueAfter: time.Minute}
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			Expect(ExpectObjectReconciled(ctx, env.Client, hookController, node).RequeueAfter).To(Equal(time.Minute))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.TerminationHookConditionType(hook.name)).IsUnknown()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.TerminationHookConditionType(hook.name)).Reason).To(Equal("TerminationHookPending"))
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained)).To(BeNil())
+
+			hook.result = cloudprovider.NodeLifecycleHookResult{}
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, hookController, node)) // Hook completion and Start Drain
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.TerminationHookConditionType(hook.name)).IsTrue()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained).IsUnknown()).To(BeTrue())
+		})
+		It("should not terminate the instance until pre-delete hooks complete", func() {
+			hook.stage = cloudprovider.NodeTerminationHookStagePreDelete
+			hook.result = cloudprovider.NodeLifecycleHookResult{Requeue: true}
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, hookController, node)) // Taint and Start Drain
+			env.Clock.Step(2 * termination.MinDrainTime)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, hookController, node)) // Drain, VolumeDetachment, PreDelete hooks
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeVolumesDetached).IsTrue()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.TerminationHookConditionType(hook.name)).IsUnknown()).To(BeTrue())
+			Expect(cloudProvider.DeleteCalls).To(BeEmpty())
+
+			hook.result = cloudprovider.NodeLifecycleHookResult{}
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, hookController, node))    // PreDelete hooks, InstanceTerminationInitiation
+			ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, hookController, node)) // InstanceTerminationValidation
+			ExpectNotFound(ctx, env.Client, node)
+			Expect(cloudProvider.DeleteCalls).ToNot(BeEmpty())
+			Expect(hook.calls).To(Equal(2))
+		})
+		It("should surface termination hook errors on the nodeclaim", func() {
+			hook.err = fmt.Errorf("load balancer unavailable")
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			Expect(ExpectObjectReconcileFailed(ctx, env.Client, hookController, node)).To(MatchError(ContainSubstring("load balancer unavailable")))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.TerminationHookConditionType(hook.name)).IsUnknown()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.TerminationHookConditionType(hook.name)).Reason).To(Equal("TerminationHookFailed"))
+			ExpectNodeExists(ctx, env.Client, node.Name)
+		})
+		It("should skip termination hooks once the terminationGracePeriod has elapsed", func() {
+			hook.result = cloudprovider.NodeLifecycleHookResult{RequeueAfter: time.Minute}
+			nodeClaim.Annotations = map[string]string{
+				v1.NodeClaimTerminationTimestampAnnotationKey: env.Clock.Now().Add(-time.Minute).Format(time.RFC3339),
+			}
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, hookController, node)) // Skip hooks and Start Drain
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.TerminationHookConditionType(hook.name)).IsFalse()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.TerminationHookConditionType(hook.name)).Reason).To(Equal("TerminationGracePeriodElapsed"))
+			Expect(hook.calls).To(Equal(0))
+		})
+		It("should not evaluate completed termination hooks again", func() {
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, hookController, node)) // PreDrain hooks, Taint and Start Drain
+			env.Clock.Step(2 * termination.MinDrainTime)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, hookController, node))    // Drain, VolumeDetachment, InstanceTerminationInitiation
+			ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, hookController, node)) // InstanceTerminationValidation
+			ExpectNotFound(ctx, env.Client, node)
+			Expect(hook.calls).To(Equal(1))
+		})
+	})
 	Context("Metrics", func() {
 		It("should fire the terminationSummary metric when deleting nodes", func() {
 			ExpectApplied(ctx, env.Client, node, nodeClaim)
@@ -1036,3 +1284,20 @@ func ExpectRequeued(result reconcile.Result) {
 	//nolint:staticcheck
 	Expect(result.Requeue || result.RequeueAfter != time.Duration(0)).To(BeTrue())
 }
+
+type testTerminationHook struct {
+	name   string
+	stage  cloudprovider.NodeTerminationHookStage
+	result cloudprovider.NodeLifecycleHookResult
+	err    error
+	calls  int
+}
+
+func (h *testTerminationHook) Name() string { return h.name }
+func (h *testTerminationHook) Stage() cloudprovider.NodeTerminationHookStage {
+	return h.stage
+}
+func (h *testTerminationHook) Terminating(context.Context, *v1.NodeClaim, *corev1.Node) (cloudprovider.NodeLifecycleHookResult, error) {
+	h.calls++
+	return h.result, h.err
+}
@@ -18,6 +18,7 @@ package events
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
@@ -40,6 +41,17 @@ func EvictPod(pod *corev1.Pod, reason string) events.Event {
 	}
 }
 
+func EvictionBlocked(pod *corev1.Pod, pdbs []string, attempts int, firstAttemptTime time.Time, lastError string) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.EvictionBlocked,
+		Message: fmt.Sprintf("Eviction blocked by PodDisruptionBudget(s) %s after %d attempt(s) since %s, %s",
+			strings.Join(pdbs, ", "), attempts, firstAttemptTime.UTC().Format(time.RFC3339), lastError),
+		DedupeValues: []string{pod.Name},
+	}
+}
+
 func DisruptPodDelete(pod *corev1.Pod, gracePeriodSeconds *int64, nodeGracePeriodTerminationTime *time.Time) events.Event {
 	return events.Event{
 		InvolvedObject: pod,
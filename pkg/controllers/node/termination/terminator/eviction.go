@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -30,10 +31,12 @@ import (
 	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -90,22 +93,45 @@ func NewQueueKey(pod *corev1.Pod) QueueKey {
 	}
 }
 
+// EvictionStatus tracks the eviction attempts of a pod in the Queue
+type EvictionStatus struct {
+	NodeName         string
+	FirstAttemptTime time.Time
+	Attempts         int
+	LastError        string
+	// PodDisruptionBudgets are the names of the PDBs that blocked the last eviction attempt. They're cached until the
+	// eviction is blocked for a different reason.
+	PodDisruptionBudgets []string
+}
+
 type Queue struct {
 	sync.Mutex
 
-	source chan event.TypedGenericEvent[*corev1.Pod]
-	set    sets.Set[QueueKey]
+	source   chan event.TypedGenericEvent[*corev1.Pod]
+	set      sets.Set[QueueKey]
+	statuses map[QueueKey]*EvictionStatus
+	// blocked are the namespace and PDB label sets of the PodsEvictionBlocked metric that are currently reported
+	blocked sets.Set[blockedKey]
 
 	kubeClient client.Client
 	recorder   events.Recorder
+	clock      clock.Clock
+}
+
+type blockedKey struct {
+	namespace string
+	pdb       string
 }
 
-func NewQueue(kubeClient client.Client, recorder events.Recorder) *Queue {
+func NewQueue(kubeClient client.Client, recorder events.Recorder, clk clock.Clock) *Queue {
 	return &Queue{
 		source:     make(chan event.TypedGenericEvent[*corev1.Pod], 10000),
 		set:        sets.New[QueueKey](),
+		statuses:   map[QueueKey]*EvictionStatus{},
+		blocked:    sets.New[blockedKey](),
 		kubeClient: kubeClient,
 		recorder:   recorder,
+		clock:      clk,
 	}
 }
 
@@ -157,6 +183,34 @@ func (q *Queue) Has(pod *corev1.Pod) bool {
 	return q.set.Has(NewQueueKey(pod))
 }
 
+// EvictionStatus returns the eviction attempts of the pod, if its eviction has been attempted and hasn't succeeded yet
+func (q *Queue) EvictionStatus(pod *corev1.Pod) (EvictionStatus, bool) {
+	q.Lock()
+	defer q.Unlock()
+
+	status, ok := q.statuses[NewQueueKey(pod)]
+	if !ok {
+		return EvictionStatus{}, false
+	}
+	return *status, true
+}
+
+// Prune forgets the pods that were queued from the node but are no longer on it, e.g. because they were deleted
+// without being evicted
+func (q *Queue) Prune(nodeName string, pods []*corev1.Pod) {
+	q.Lock()
+	defer q.Unlock()
+
+	keys := sets.New(lo.Map(pods, func(p *corev1.Pod, _ int) QueueKey { return NewQueueKey(p) })...)
+	for qk, status := range q.statuses {
+		if status.NodeName == nodeName && !keys.Has(qk) {
+			q.set.Delete(qk)
+			delete(q.statuses, qk)
+		}
+	}
+	q.updateBlockedMetric()
+}
+
 func (q *Queue) Reconcile(ctx context.Context, pod *corev1.Pod) (reconcile.Result, error) {
 	ctx = injection.WithControllerName(ctx, q.Name())
 
@@ -191,6 +245,7 @@ func (q *Queue) Reconcile(ctx context.Context, pod *corev1.Pod) (reconcile.Resul
 			// https://github.com/kubernetes/kubernetes/blob/ad19beaa83363de89a7772f4d5af393b85ce5e61/pkg/registry/core/pod/storage/eviction.go#L160
 			// 409 - The pod exists, but it is not the same pod that we initiated the eviction on
 			// https://github.com/kubernetes/kubernetes/blob/ad19beaa83363de89a7772f4d5af393b85ce5e61/pkg/registry/core/pod/storage/eviction.go#L318
+			q.forget(pod)
 			return reconcile.Result{}, nil
 		}
 		// The pod exists and is the same pod, we need to continue
@@ -204,9 +259,27 @@ func (q *Queue) Reconcile(ctx context.Context, pod *corev1.Pod) (reconcile.Resul
 			}
 			errorMessage := lo.Ternary(message == multiplePodDisruptionBudgetsError, "eviction does not support multiple PDBs", "evicting pod violates a PDB")
 			q.recorder.Publish(terminatorevents.NodeFailedToDrain(node, serrors.Wrap(errors.New(errorMessage), "Pod", klog.KRef(pod.Namespace, pod.Name))))
+			// The PDBs are only looked up again when the pod's eviction is blocked for a different reason, since the pod is
+			// retried until it's evicted
+			previous, blocked := q.EvictionStatus(pod)
+			blocked = blocked && len(previous.PodDisruptionBudgets) > 0 && previous.LastError == errorMessage
+			pdbs := previous.PodDisruptionBudgets
+			if !blocked {
+				if pdbs, err2 = q.podDisruptionBudgets(ctx, pod); err2 != nil {
+					return reconcile.Result{}, err2
+				}
+			}
+			status := q.recordAttempt(pod, errorMessage, pdbs)
+			for _, pdb := range pdbs {
+				PodsEvictionBlockedTotal.Inc(map[string]string{NamespaceLabel: pod.Namespace, PodDisruptionBudgetLabel: pdb})
+			}
+			if !blocked {
+				q.recorder.Publish(terminatorevents.EvictionBlocked(pod, status.PodDisruptionBudgets, status.Attempts, status.FirstAttemptTime, status.LastError))
+			}
 			return reconcile.Result{Requeue: true}, nil
 		}
 		// Its not a PDB, we should requeue
+		q.recordAttempt(pod, err.Error(), nil)
 		return reconcile.Result{}, err
 	}
 	PodsEvictionRequestsTotal.Inc(map[string]string{CodeLabel: "200"})
@@ -214,10 +287,80 @@ func (q *Queue) Reconcile(ctx context.Context, pod *corev1.Pod) (reconcile.Resul
 	q.recorder.Publish(terminatorevents.EvictPod(pod, reason))
 	PodsDrainedTotal.Inc(map[string]string{ReasonLabel: reason})
 
+	q.forget(pod)
+	return reconcile.Result{}, nil
+}
+
+// recordAttempt records a failed eviction attempt of the pod and returns its updated eviction status
+func (q *Queue) recordAttempt(pod *corev1.Pod, lastError string, pdbs []string) EvictionStatus {
 	q.Lock()
 	defer q.Unlock()
-	q.set.Delete(NewQueueKey(pod))
-	return reconcile.Result{}, nil
+
+	qk := NewQueueKey(pod)
+	status, ok := q.statuses[qk]
+	if !ok {
+		status = &EvictionStatus{NodeName: pod.Spec.NodeName, FirstAttemptTime: q.clock.Now()}
+		q.statuses[qk] = status
+	}
+	status.Attempts++
+	status.LastError = lastError
+	status.PodDisruptionBudgets = pdbs
+	q.updateBlockedMetric()
+	return *status
+}
+
+// forget removes the pod from the Queue once it no longer needs to be evicted
+func (q *Queue) forget(pod *corev1.Pod) {
+	q.Lock()
+	defer q.Unlock()
+
+	qk := NewQueueKey(pod)
+	q.set.Delete(qk)
+	if _, ok := q.statuses[qk]; ok {
+		delete(q.statuses, qk)
+		q.updateBlockedMetric()
+	}
+}
+
+// updateBlockedMetric reports the number of pods whose eviction is blocked by each PDB. It must be called while
+// holding the Queue's lock.
+func (q *Queue) updateBlockedMetric() {
+	counts := map[blockedKey]int{}
+	for qk, status := range q.statuses {
+		for _, pdb := range status.PodDisruptionBudgets {
+			counts[blockedKey{namespace: qk.Namespace, pdb: pdb}]++
+		}
+	}
+	for key := range q.blocked {
+		if _, ok := counts[key]; !ok {
+			PodsEvictionBlocked.Delete(map[string]string{NamespaceLabel: key.namespace, PodDisruptionBudgetLabel: key.pdb})
+			q.blocked.Delete(key)
+		}
+	}
+	for key, count := range counts {
+		PodsEvictionBlocked.Set(float64(count), map[string]string{NamespaceLabel: key.namespace, PodDisruptionBudgetLabel: key.pdb})
+		q.blocked.Insert(key)
+	}
+}
+
+// podDisruptionBudgets returns the names of the PDBs that select the pod
+func (q *Queue) podDisruptionBudgets(ctx context.Context, pod *corev1.Pod) ([]string, error) {
+	pdbList := &policyv1.PodDisruptionBudgetList{}
+	if err := q.kubeClient.List(ctx, pdbList, client.InNamespace(pod.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing pdbs, %w", err)
+	}
+	var names []string
+	for _, pdb := range pdbList.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			names = append(names, pdb.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
 }
 
 func evictionReason(ctx context.Context, pod *corev1.Pod, kubeClient client.Client) string {
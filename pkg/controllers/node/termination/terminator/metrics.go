@@ -29,6 +29,10 @@ const (
 	CodeLabel = "code"
 	// ReasonLabel for pod draining
 	ReasonLabel = "reason"
+	// NamespaceLabel for blocked pod evictions
+	NamespaceLabel = "namespace"
+	// PodDisruptionBudgetLabel for blocked pod evictions
+	PodDisruptionBudgetLabel = "pod_disruption_budget"
 )
 
 var PodsEvictionRequestsTotal = opmetrics.NewPrometheusCounter(
@@ -52,3 +56,25 @@ var PodsDrainedTotal = opmetrics.NewPrometheusCounter(
 	},
 	[]string{ReasonLabel},
 )
+
+var PodsEvictionBlockedTotal = opmetrics.NewPrometheusCounter(
+	crmetrics.Registry,
+	prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.PodSubsystem,
+		Name:      "eviction_blocked_total",
+		Help:      "The total number of pod eviction requests made by Karpenter that were blocked by a PodDisruptionBudget, labeled by namespace and PodDisruptionBudget",
+	},
+	[]string{NamespaceLabel, PodDisruptionBudgetLabel},
+)
+
+var PodsEvictionBlocked = opmetrics.NewPrometheusGauge(
+	crmetrics.Registry,
+	prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.PodSubsystem,
+		Name:      "eviction_blocked",
+		Help:      "The number of pods that Karpenter is waiting to evict whose last eviction request was blocked by a PodDisruptionBudget, labeled by namespace and PodDisruptionBudget",
+	},
+	[]string{NamespaceLabel, PodDisruptionBudgetLabel},
+)
@@ -65,7 +65,7 @@ var _ = BeforeSuite(func() {
 	)
 	ctx = options.ToContext(ctx, test.Options())
 	recorder = test.NewEventRecorder()
-	queue = terminator.NewQueue(env.Client, recorder)
+	queue = terminator.NewQueue(env.Client, recorder, env.Clock)
 	terminatorInstance = terminator.NewTerminator(env.Clock, env.Client, queue, recorder)
 })
 
@@ -76,7 +76,7 @@ var _ = AfterSuite(func() {
 var _ = BeforeEach(func() {
 	recorder.Reset() // Reset the events that we captured during the run
 	// Shut down the queue and restart it to ensure no races
-	*queue = lo.FromPtr(terminator.NewQueue(env.Client, recorder))
+	*queue = lo.FromPtr(terminator.NewQueue(env.Client, recorder, env.Clock))
 })
 
 var _ = AfterEach(func() {
@@ -100,6 +100,8 @@ var _ = Describe("Eviction/Queue", func() {
 
 		terminator.PodsEvictionRequestsTotal.Reset()
 		terminator.PodsDrainedTotal.Reset()
+		terminator.PodsEvictionBlockedTotal.Reset()
+		terminator.PodsEvictionBlocked.Reset()
 	})
 
 	Context("Eviction API", func() {
@@ -138,9 +140,8 @@ var _ = Describe("Eviction/Queue", func() {
 			//nolint:staticcheck
 			Expect(result.Requeue).To(BeTrue())
 			ExpectMetricCounterValue(terminator.PodsEvictionRequestsTotal, 1, map[string]string{terminator.CodeLabel: "429"})
-			e := recorder.Events()
+			e := lo.Filter(recorder.Events(), func(e events.Event, _ int) bool { return e.Reason == events.FailedDraining })
 			Expect(e).To(HaveLen(1))
-			Expect(e[0].Reason).To(Equal(events.FailedDraining))
 			Expect(e[0].Message).To(ContainSubstring("evicting pod violates a PDB"))
 		})
 		It("should return a NodeDrainError event when two PDBs refer to the same pod", func() {
@@ -155,9 +156,8 @@ var _ = Describe("Eviction/Queue", func() {
 			//nolint:staticcheck
 			Expect(result.Requeue).To(BeTrue())
 			ExpectMetricCounterValue(terminator.PodsEvictionRequestsTotal, 1, map[string]string{terminator.CodeLabel: "500"})
-			e := recorder.Events()
+			e := lo.Filter(recorder.Events(), func(e events.Event, _ int) bool { return e.Reason == events.FailedDraining })
 			Expect(e).To(HaveLen(1))
-			Expect(e[0].Reason).To(Equal(events.FailedDraining))
 			Expect(e[0].Message).To(ContainSubstring("eviction does not support multiple PDBs"))
 		})
 		It("should ensure that calling Evict() is valid while making Add() calls", func() {
@@ -218,6 +218,84 @@ var _ = Describe("Eviction/Queue", func() {
 		})
 	})
 
+	Context("Eviction Status", func() {
+		It("should track eviction attempts that are blocked by a PDB", func() {
+			ExpectApplied(ctx, env.Client, pdb, pod, node)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			queue.Add(pod)
+			ExpectObjectReconciled(ctx, env.Client, queue, pod)
+
+			status, ok := queue.EvictionStatus(pod)
+			Expect(ok).To(BeTrue())
+			Expect(status.Attempts).To(Equal(1))
+			Expect(status.FirstAttemptTime).To(Equal(env.Clock.Now()))
+			Expect(status.LastError).To(Equal("evicting pod violates a PDB"))
+			Expect(status.PodDisruptionBudgets).To(ConsistOf(pdb.Name))
+			firstAttemptTime := status.FirstAttemptTime
+
+			env.Clock.Step(time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, queue, pod)
+			status, ok = queue.EvictionStatus(pod)
+			Expect(ok).To(BeTrue())
+			Expect(status.Attempts).To(Equal(2))
+			Expect(status.FirstAttemptTime).To(Equal(firstAttemptTime))
+		})
+		It("should publish a pod event and metrics when an eviction is blocked by a PDB", func() {
+			ExpectApplied(ctx, env.Client, pdb, pod, node)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			queue.Add(pod)
+			ExpectObjectReconciled(ctx, env.Client, queue, pod)
+
+			e := lo.Filter(recorder.Events(), func(e events.Event, _ int) bool { return e.Reason == events.EvictionBlocked })
+			Expect(e).To(HaveLen(1))
+			Expect(e[0].InvolvedObject).To(Equal(pod))
+			Expect(e[0].Message).To(ContainSubstring(pdb.Name))
+			labels := map[string]string{terminator.NamespaceLabel: pod.Namespace, terminator.PodDisruptionBudgetLabel: pdb.Name}
+			ExpectMetricCounterValue(terminator.PodsEvictionBlockedTotal, 1, labels)
+			ExpectMetricGaugeValue(terminator.PodsEvictionBlocked, 1, labels)
+		})
+		It("should only publish a pod event when an eviction is first blocked", func() {
+			ExpectApplied(ctx, env.Client, pdb, pod, node)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			queue.Add(pod)
+			ExpectObjectReconciled(ctx, env.Client, queue, pod)
+			env.Clock.Step(time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, queue, pod)
+
+			status, ok := queue.EvictionStatus(pod)
+			Expect(ok).To(BeTrue())
+			Expect(status.Attempts).To(Equal(2))
+			Expect(status.PodDisruptionBudgets).To(ConsistOf(pdb.Name))
+			Expect(lo.Filter(recorder.Events(), func(e events.Event, _ int) bool { return e.Reason == events.EvictionBlocked })).To(HaveLen(1))
+		})
+		It("should clear the eviction status once the pod is evicted", func() {
+			ExpectApplied(ctx, env.Client, pdb, pod, node)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			queue.Add(pod)
+			ExpectObjectReconciled(ctx, env.Client, queue, pod)
+			_, ok := queue.EvictionStatus(pod)
+			Expect(ok).To(BeTrue())
+
+			ExpectDeleted(ctx, env.Client, pdb)
+			ExpectObjectReconciled(ctx, env.Client, queue, pod)
+			_, ok = queue.EvictionStatus(pod)
+			Expect(ok).To(BeFalse())
+			Expect(queue.Has(pod)).To(BeFalse())
+			_, found := FindMetricWithLabelValues("karpenter_pods_eviction_blocked", map[string]string{terminator.NamespaceLabel: pod.Namespace, terminator.PodDisruptionBudgetLabel: pdb.Name})
+			Expect(found).To(BeFalse())
+		})
+		It("should forget pods that are no longer on the node", func() {
+			ExpectApplied(ctx, env.Client, pdb, pod, node)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			queue.Add(pod)
+			ExpectObjectReconciled(ctx, env.Client, queue, pod)
+
+			queue.Prune(node.Name, nil)
+			_, ok := queue.EvictionStatus(pod)
+			Expect(ok).To(BeFalse())
+			Expect(queue.Has(pod)).To(BeFalse())
+		})
+	})
 	Context("Pod Deletion API", func() {
 		It("should not delete a pod with no nodeTerminationTime", func() {
 			ExpectApplied(ctx, env.Client, pod, node)
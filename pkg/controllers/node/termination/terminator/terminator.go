@@ -19,20 +19,27 @@ package terminator
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
 	"sigs.k8s.io/karpenter/pkg/events"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 )
 
 type Terminator struct {
@@ -98,24 +105,133 @@ func (t *Terminator) Drain(ctx context.Context, node *corev1.Node, nodeGracePeri
 	if err != nil {
 		return fmt.Errorf("listing pods on node, %w", err)
 	}
+	t.evictionQueue.Prune(node.Name, pods)
 	podsToDelete := lo.Filter(pods, func(p *corev1.Pod, _ int) bool {
 		return podutil.IsWaitingEviction(p, t.clock) && (!podutil.IsTerminating(p) || podutil.IsPodEligibleForForcedEviction(p, nodeGracePeriodExpirationTime))
 	})
 	if err := t.DeleteExpiringPods(ctx, podsToDelete, nodeGracePeriodExpirationTime); err != nil {
 		return fmt.Errorf("deleting expiring pods, %w", err)
 	}
+	stages, err := t.drainStages(ctx, node)
+	if err != nil {
+		return fmt.Errorf("resolving drain stages, %w", err)
+	}
 	// Monitor pods in pod groups that either haven't been evicted or are actively evicting
-	podGroups := t.groupPodsByPriority(lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return podutil.IsWaitingEviction(p, t.clock) }))
+	podGroups := t.groupPods(ctx, lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return podutil.IsWaitingEviction(p, t.clock) }), stages)
 	for _, group := range podGroups {
-		if len(group) > 0 {
+		if len(group.pods) > 0 {
+			if group.wait > 0 {
+				start, err := t.stageStartTime(ctx, node, group.name)
+				if err != nil {
+					return fmt.Errorf("recording drain stage start time, %w", err)
+				}
+				// The error is surfaced on the node's events and the NodeClaim's Drained condition, so it includes when the
+				// wait ends rather than the remaining time, which would change on every reconcile
+				if end := start.Add(group.wait); end.After(t.clock.Now()) {
+					return NewNodeDrainError(fmt.Errorf("waiting until %s before evicting %d pods in drain stage %q", end.UTC().Format(time.RFC3339), len(group.pods), group.name))
+				}
+			}
 			// Only add pods to the eviction queue that haven't been evicted yet
-			t.evictionQueue.Add(lo.Filter(group, func(p *corev1.Pod, _ int) bool { return podutil.IsEvictable(p, t.clock, t.recorder) })...)
-			return NewNodeDrainError(fmt.Errorf("%d pods are waiting to be evicted", lo.SumBy(podGroups, func(pods []*corev1.Pod) int { return len(pods) })))
+			t.evictionQueue.Add(lo.Filter(group.pods, func(p *corev1.Pod, _ int) bool { return podutil.IsEvictable(p, t.clock, t.recorder) })...)
+			return NewNodeDrainError(fmt.Errorf("%d pods are waiting to be evicted%s", lo.SumBy(podGroups, func(g podGroup) int { return len(g.pods) }), t.blockedEvictions(group.pods)))
 		}
 	}
 	return nil
 }
 
+// blockedEvictions summarizes the pods whose last eviction attempt was blocked by a PDB
+func (t *Terminator) blockedEvictions(pods []*corev1.Pod) string {
+	var blocked []string
+	for _, pod := range pods {
+		if status, ok := t.evictionQueue.EvictionStatus(pod); ok && len(status.PodDisruptionBudgets) > 0 {
+			blocked = append(blocked, fmt.Sprintf("%s/%s by %s since %s", pod.Namespace, pod.Name,
+				strings.Join(status.PodDisruptionBudgets, ", "), status.FirstAttemptTime.UTC().Format(time.RFC3339)))
+		}
+	}
+	if len(blocked) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", evictions blocked by PodDisruptionBudgets (%s)", pretty.Slice(blocked, 5))
+}
+
+// podGroup is a set of pods that are evicted together. The pods of a group are only evicted once the pods of all
+// previous groups are gone and the group's wait has elapsed.
+type podGroup struct {
+	name string
+	wait time.Duration
+	pods []*corev1.Pod
+}
+
+// drainStages returns the drain stages configured on the NodePool that owns the node
+func (t *Terminator) drainStages(ctx context.Context, node *corev1.Node) ([]v1.DrainStage, error) {
+	nodePoolName, ok := node.Labels[v1.NodePoolLabelKey]
+	if !ok {
+		return nil, nil
+	}
+	nodePool := &v1.NodePool{}
+	if err := t.kubeClient.Get(ctx, types.NamespacedName{Name: nodePoolName}, nodePool); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return nodePool.Spec.Disruption.DrainStages, nil
+}
+
+// groupPods assigns each pod to the first drain stage that selects it. Pods that aren't selected by any stage are
+// grouped by priority. The non-critical groups are drained before the stages and the critical groups after them, so
+// that critical pods keep running until every stage is drained.
+func (t *Terminator) groupPods(ctx context.Context, pods []*corev1.Pod, stages []v1.DrainStage) []podGroup {
+	selectors := lo.Map(stages, func(s v1.DrainStage, _ int) labels.Selector {
+		if s.PodSelector == nil {
+			return labels.Everything()
+		}
+		selector, err := metav1.LabelSelectorAsSelector(s.PodSelector)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "invalid pod selector, drain stage will not select any pods", "stage", s.Name)
+			return labels.Nothing()
+		}
+		return selector
+	})
+	stagePods := make([][]*corev1.Pod, len(stages))
+	var unstaged []*corev1.Pod
+	for _, pod := range pods {
+		_, i, ok := lo.FindIndexOf(lo.Range(len(stages)), func(i int) bool {
+			return selectors[i].Matches(labels.Set(pod.Labels)) && (stages[i].Priority == nil || stages[i].Priority.Matches(lo.FromPtr(pod.Spec.Priority)))
+		})
+		if !ok {
+			unstaged = append(unstaged, pod)
+			continue
+		}
+		stagePods[i] = append(stagePods[i], pod)
+	}
+	priorityGroups := lo.Map(t.groupPodsByPriority(unstaged), func(pods []*corev1.Pod, _ int) podGroup { return podGroup{pods: pods} })
+	// The first two priority groups are the non-critical pods, and the last two are the critical pods
+	groups := slices.Clone(priorityGroups[:2])
+	for i, s := range stages {
+		groups = append(groups, podGroup{name: s.Name, wait: lo.FromPtr(s.Wait).Duration, pods: stagePods[i]})
+	}
+	return append(groups, priorityGroups[2:]...)
+}
+
+// stageStartTime returns when the node started waiting on the drain stage, which is the first time the stage was
+// observed as the next stage to evict. The start time is stored on the node, so the wait isn't reset when the
+// controller restarts.
+func (t *Terminator) stageStartTime(ctx context.Context, node *corev1.Node, stage string) (time.Time, error) {
+	if node.Annotations[v1.DrainStageAnnotationKey] == stage {
+		if start, err := time.Parse(time.RFC3339, node.Annotations[v1.DrainStageStartTimestampAnnotationKey]); err == nil {
+			return start, nil
+		}
+	}
+	stored := node.DeepCopy()
+	now := t.clock.Now()
+	node.Annotations = lo.Assign(node.Annotations, map[string]string{
+		v1.DrainStageAnnotationKey:               stage,
+		v1.DrainStageStartTimestampAnnotationKey: now.UTC().Format(time.RFC3339),
+	})
+	if err := t.kubeClient.Patch(ctx, node, client.MergeFrom(stored)); err != nil {
+		return time.Time{}, err
+	}
+	return now, nil
+}
+
 func (t *Terminator) groupPodsByPriority(pods []*corev1.Pod) [][]*corev1.Pod {
 	// 1. Prioritize noncritical pods, non-daemon pods https://kubernetes.io/docs/concepts/architecture/nodes/#graceful-node-shutdown
 	var nonCriticalNonDaemon, nonCriticalDaemon, criticalNonDaemon, criticalDaemon []*corev1.Pod
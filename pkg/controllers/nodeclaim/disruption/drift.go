@@ -32,6 +32,7 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 )
 
@@ -39,6 +40,7 @@ const (
 	NodePoolDrifted      cloudprovider.DriftReason = "NodePoolDrifted"
 	RequirementsDrifted  cloudprovider.DriftReason = "RequirementsDrifted"
 	InstanceTypeNotFound cloudprovider.DriftReason = "InstanceTypeNotFound"
+	ReservationExpiring  cloudprovider.DriftReason = "ReservationExpiring"
 )
 
 // Drift is a nodeclaim sub-controller that adds or removes status conditions on drifted nodeclaims
@@ -101,6 +103,13 @@ func (d *Drift) isDrifted(ctx context.Context, nodePool *v1.NodePool, nodeClaim
 		if reason := instanceTypeNotFound(its, nodeClaim); reason != "" {
 			return reason, nil
 		}
+		// Replace NodeClaims that are running in capacity reservations ahead of the reservation ending, rather than letting
+		// them be demoted to on-demand or reclaimed.
+		if options.FromContext(ctx).FeatureGates.ReservedCapacity && nodeClaim.Labels[v1.CapacityTypeLabelKey] == v1.CapacityTypeReserved {
+			if reason := reservationExpiring(its, nodeClaim, d.clock.Now().Add(options.FromContext(ctx).ReservationExpiryLeadTime)); reason != "" {
+				return reason, nil
+			}
+		}
 		// Only add a cache entry once we've validated that an instance type exists. We only cache a successful check rather
 		// that the result to ensure we respond quickly to transient abnormalities in the GetInstanceTypes response.
 		d.instanceTypeNotFoundCheckCache.SetDefault(string(nodeClaim.UID), nil)
@@ -149,6 +158,29 @@ func instanceTypeNotFound(its []*cloudprovider.InstanceType, nodeClaim *v1.NodeC
 	return ""
 }
 
+// reservationExpiring returns ReservationExpiring if the capacity reservation that the NodeClaim was launched into ends
+// before expiresBefore. The reservation is found through the offerings of the NodeClaim's instance type, since the
+// NodeClaim only records the reservation's ID.
+func reservationExpiring(its []*cloudprovider.InstanceType, nodeClaim *v1.NodeClaim, expiresBefore time.Time) cloudprovider.DriftReason {
+	reservationID, ok := nodeClaim.Labels[cloudprovider.ReservationIDLabel]
+	if !ok {
+		return ""
+	}
+	it, ok := lo.Find(its, func(it *cloudprovider.InstanceType) bool {
+		return it.Name == nodeClaim.Labels[corev1.LabelInstanceTypeStable]
+	})
+	if !ok {
+		return ""
+	}
+	if lo.ContainsBy(it.Offerings, func(o *cloudprovider.Offering) bool {
+		return o.CapacityType() == v1.CapacityTypeReserved && o.ReservationID() == reservationID &&
+			!o.ReservationEndTime.IsZero() && o.ReservationEndTime.Time.Before(expiresBefore)
+	}) {
+		return ReservationExpiring
+	}
+	return ""
+}
+
 // Eligible fields for drift are described in the docs
 // https://karpenter.sh/docs/concepts/disruption/#drift
 func areStaticFieldsDrifted(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) cloudprovider.DriftReason {
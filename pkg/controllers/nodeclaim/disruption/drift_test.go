@@ -569,5 +569,26 @@ var _ = Describe("Drift", func() {
 			Entry("should drift when a matching instance type exists but the only offering is spot", true, v1.CapacityTypeSpot),
 			Entry("should drift when a matching instance type exists but there are no compatible offerings", true),
 		)
+		DescribeTable(
+			"ReservationExpiring",
+			func(expectDrifted bool, endsIn time.Duration) {
+				ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+				env.Clock.Step(time.Hour * 2) // To move 2h past the creationTimestamp
+				reservedOffering := it.Offerings[len(it.Offerings)-1]
+				if endsIn != 0 {
+					reservedOffering.ReservationEndTime = metav1.NewTime(env.Clock.Now().Add(endsIn))
+				}
+				ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(Equal(expectDrifted))
+				if expectDrifted {
+					Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Reason).To(Equal(string(disruption.ReservationExpiring)))
+				}
+			},
+			Entry("should drift when the reservation ends within the lead time", true, 30*time.Minute),
+			Entry("shouldn't drift when the reservation ends after the lead time", false, 2*time.Hour),
+			Entry("shouldn't drift when the reservation doesn't end", false, time.Duration(0)),
+		)
 	})
 })
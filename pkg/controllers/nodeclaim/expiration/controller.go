@@ -35,23 +35,26 @@ import (
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	disruptionutils "sigs.k8s.io/karpenter/pkg/utils/disruption"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 )
 
 // Expiration is a nodeclaim controller that deletes expired nodeclaims based on expireAfter
 type Controller struct {
-	clock         clock.Clock
-	kubeClient    client.Client
-	cloudProvider cloudprovider.CloudProvider
+	clock           clock.Clock
+	kubeClient      client.Client
+	cloudProvider   cloudprovider.CloudProvider
+	forcefulBudgets *disruptionutils.ForcefulBudgets
 }
 
 // NewController constructs a nodeclaim disruption controller
-func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, forcefulBudgets *disruptionutils.ForcefulBudgets) *Controller {
 	return &Controller{
-		clock:         clk,
-		kubeClient:    kubeClient,
-		cloudProvider: cloudProvider,
+		clock:           clk,
+		kubeClient:      kubeClient,
+		cloudProvider:   cloudProvider,
+		forcefulBudgets: forcefulBudgets,
 	}
 }
 
@@ -67,7 +70,7 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (re
 	if !nodeClaim.DeletionTimestamp.IsZero() {
 		return reconcile.Result{}, nil
 	}
-	// From here there are three scenarios to handle:
+	// From here there are five scenarios to handle:
 	// 1. If ExpireAfter is not configured, exit expiration loop
 	if nodeClaim.Spec.ExpireAfter.Duration == nil {
 		return reconcile.Result{}, nil
@@ -78,11 +81,30 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (re
 		// Use t.Sub(clock.Now()) instead of time.Until() to ensure we're using the injected clock.
 		return reconcile.Result{RequeueAfter: expirationTime.Sub(c.clock.Now())}, nil
 	}
-	// 3. Otherwise, if the NodeClaim is expired we can forcefully expire the nodeclaim (by deleting it)
+	// 3. If the NodePool has maintenance windows, wait until a window is open long enough to drain the NodeClaim
+	delay, err := disruptionutils.MaintenanceWindowDelay(ctx, c.kubeClient, c.clock, nodeClaim)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if delay > 0 {
+		log.FromContext(ctx).V(1).Info("waiting for maintenance window to expire nodeclaim")
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
+	// 4. If the NodePool has an Expired budget, wait until it allows the NodeClaim to be disrupted
+	delay, err = c.forcefulBudgets.Reserve(ctx, c.kubeClient, c.clock, nodeClaim, v1.DisruptionReasonExpired, expirationTime)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if delay > 0 {
+		log.FromContext(ctx).V(1).Info("waiting for disruption budget to expire nodeclaim")
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
+	// 5. Otherwise, if the NodeClaim is expired we can forcefully expire the nodeclaim (by deleting it)
 	if err := c.kubeClient.Delete(ctx, nodeClaim); err != nil {
+		c.forcefulBudgets.Release(nodeClaim)
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
-	// 4. The deletion timestamp has successfully been set for the NodeClaim, update relevant metrics.
+	// 6. The deletion timestamp has successfully been set for the NodeClaim, update relevant metrics.
 	log.FromContext(ctx).V(1).Info("deleting expired nodeclaim")
 	labels := map[string]string{
 		metrics.ReasonLabel:              strings.ToLower(metrics.ExpiredReason),
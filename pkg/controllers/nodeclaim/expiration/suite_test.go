@@ -18,11 +18,14 @@ package expiration_test
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -35,6 +38,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	disruptionutils "sigs.k8s.io/karpenter/pkg/utils/disruption"
 	. "sigs.k8s.io/karpenter/pkg/utils/testing"
 )
 
@@ -53,7 +57,7 @@ var _ = BeforeSuite(func() {
 	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeProviderIDFieldIndexer(ctx)))
 	ctx = options.ToContext(ctx, test.Options())
 	cp = fake.NewCloudProvider()
-	expirationController = expiration.NewController(env.Clock, env.Client, cp)
+	expirationController = expiration.NewController(env.Clock, env.Client, cp, disruptionutils.NewForcefulBudgets())
 })
 
 var _ = AfterSuite(func() {
@@ -158,6 +162,160 @@ var _ = Describe("Expiration", func() {
 			})
 		})
 	})
+	Context("Budgets", func() {
+		var nodeClaim2 *v1.NodeClaim
+		BeforeEach(func() {
+			nodeClaim2 = test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:     map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+					Finalizers: []string{"test-finalizer"},
+				},
+				Spec: v1.NodeClaimSpec{
+					ExpireAfter: v1.MustParseNillableDuration("30s"),
+				},
+			})
+			nodeClaim.Finalizers = append(nodeClaim.Finalizers, "test-finalizer")
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+			nodeClaim2.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+		})
+		It("should wait for the Expired budget before expiring nodeclaims", func() {
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "1", Reasons: []v1.DisruptionReason{v1.DisruptionReasonExpired}}}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, nodeClaim2)
+
+			// step forward to make the nodes expired
+			env.Clock.Step(60 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeFalse())
+
+			result := ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim2)
+			Expect(result.RequeueAfter).To(Equal(disruptionutils.ForcefulBudgetPollPeriod))
+			Expect(ExpectExists(ctx, env.Client, nodeClaim2).DeletionTimestamp.IsZero()).To(BeTrue())
+
+			ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim2)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim2).DeletionTimestamp.IsZero()).To(BeFalse())
+		})
+		It("should not exceed the Expired budget when nodeclaims are reconciled concurrently", func() {
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "1", Reasons: []v1.DisruptionReason{v1.DisruptionReasonExpired}}}
+			nodeClaims := []*v1.NodeClaim{nodeClaim, nodeClaim2}
+			for range 8 {
+				nc := test.NodeClaim(v1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels:     map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+						Finalizers: []string{"test-finalizer"},
+					},
+					Spec: v1.NodeClaimSpec{
+						ExpireAfter: v1.MustParseNillableDuration("30s"),
+					},
+				})
+				nc.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+				nodeClaims = append(nodeClaims, nc)
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			for _, nc := range nodeClaims {
+				ExpectApplied(ctx, env.Client, nc)
+			}
+
+			// step forward to make the nodes expired
+			env.Clock.Step(60 * time.Second)
+			var wg sync.WaitGroup
+			for _, nc := range nodeClaims {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					ExpectObjectReconciled(ctx, env.Client, expirationController, nc)
+				}()
+			}
+			wg.Wait()
+			Expect(lo.CountBy(nodeClaims, func(nc *v1.NodeClaim) bool {
+				return !ExpectExists(ctx, env.Client, nc).DeletionTimestamp.IsZero()
+			})).To(Equal(1))
+		})
+		It("should expire nodeclaims once they've waited for forcefulBudgetMaxWait", func() {
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "0", Reasons: []v1.DisruptionReason{v1.DisruptionReasonExpired}}}
+			nodePool.Spec.Disruption.ForcefulBudgetMaxWait = &metav1.Duration{Duration: 5 * time.Minute}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+			// step forward to make the node expired
+			env.Clock.Step(60 * time.Second)
+			result := ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(result.RequeueAfter).To(Equal(disruptionutils.ForcefulBudgetPollPeriod))
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeTrue())
+
+			env.Clock.Step(5 * time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeFalse())
+		})
+		It("should not apply budgets that don't list the Expired reason", func() {
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "0"}}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+			// step forward to make the node expired
+			env.Clock.Step(60 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeFalse())
+		})
+	})
+	Context("Maintenance Windows", func() {
+		// windowAt returns a daily maintenance window that opens at the given time
+		windowAt := func(t time.Time, duration time.Duration) *v1.MaintenanceWindows {
+			t = t.UTC()
+			return &v1.MaintenanceWindows{Windows: []v1.MaintenanceWindow{{
+				Schedule: fmt.Sprintf("%d %d * * *", t.Minute(), t.Hour()),
+				Duration: metav1.Duration{Duration: duration},
+			}}}
+		}
+		BeforeEach(func() {
+			nodeClaim.Finalizers = append(nodeClaim.Finalizers, "test-finalizer")
+		})
+		It("should wait for a maintenance window before expiring nodeclaims", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = windowAt(env.Clock.Now().Add(3*time.Hour), time.Hour)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+			// step forward to make the node expired
+			env.Clock.Step(60 * time.Second)
+			result := ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(result.RequeueAfter).To(Equal(time.Hour))
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeTrue())
+
+			env.Clock.Step(3 * time.Hour)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeFalse())
+		})
+		It("should expire nodeclaims when the window closes before the terminationGracePeriod", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = windowAt(env.Clock.Now(), time.Hour)
+			nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: 2 * time.Hour}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+			// step forward to make the node expired
+			env.Clock.Step(60 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeFalse())
+		})
+		It("should expire nodeclaims during an open maintenance window", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = windowAt(env.Clock.Now(), time.Hour)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+			// step forward to make the node expired
+			env.Clock.Step(60 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeFalse())
+		})
+		It("should not expire nodeclaims on excluded dates", func() {
+			nodePool.Spec.Disruption.MaintenanceWindows = windowAt(env.Clock.Now(), time.Hour)
+			nodePool.Spec.Disruption.MaintenanceWindows.Exclusions = []string{
+				env.Clock.Now().UTC().Format(time.DateOnly),
+				env.Clock.Now().UTC().AddDate(0, 0, 1).Format(time.DateOnly),
+			}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+			// step forward to make the node expired
+			env.Clock.Step(60 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeTrue())
+		})
+	})
 	DescribeTable(
 		"Expiration",
 		func(isNodeClaimManaged bool) {
@@ -0,0 +1,333 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interruption
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awslabs/operatorpkg/reconciler"
+	"github.com/awslabs/operatorpkg/singleton"
+	"github.com/awslabs/operatorpkg/status"
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+)
+
+// pollingPeriod is how often the cloud provider's pending interruptions are listed. Interruption notices can be as
+// short as a couple of minutes, so this is kept well below that.
+const pollingPeriod = 5 * time.Second
+
+// Controller is a nodeclaim controller that disrupts NodeClaims whose instances the cloud provider has announced it
+// will interrupt. It launches replacements for the NodeClaim's pods and waits for them to initialize before deleting
+// it, and sets the NodeClaim's termination timestamp to the interruption's deadline so that the node is drained before
+// it's interrupted.
+type Controller struct {
+	clock                clock.Clock
+	kubeClient           client.Client
+	cloudProvider        cloudprovider.CloudProvider
+	interruptionProvider cloudprovider.InterruptionProvider
+	cluster              *state.Cluster
+	provisioner          *provisioning.Provisioner
+	recorder             events.Recorder
+}
+
+// NewController constructs a controller for the interruptions returned by the interruptionProvider
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, interruptionProvider cloudprovider.InterruptionProvider,
+	cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder,
+) *Controller {
+	return &Controller{
+		clock:                clk,
+		kubeClient:           kubeClient,
+		cloudProvider:        cloudProvider,
+		interruptionProvider: interruptionProvider,
+		cluster:              cluster,
+		provisioner:          provisioner,
+		recorder:             recorder,
+	}
+}
+
+func (c *Controller) Name() string {
+	return "nodeclaim.interruption"
+}
+
+func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
+	ctx = injection.WithControllerName(ctx, c.Name())
+
+	// Replacements are scheduled against the cluster's capacity, so we need an up-to-date view of it
+	if !c.cluster.Synced(ctx) {
+		return reconciler.Result{RequeueAfter: time.Second}, nil
+	}
+	interruptions, err := c.interruptionProvider.Interruptions(ctx)
+	if err != nil {
+		return reconciler.Result{}, fmt.Errorf("listing interruptions, %w", err)
+	}
+	// Interruptions are handled sequentially, so that the replacements launched for one interruption are considered
+	// when scheduling the pods of the next
+	errs := make([]error, len(interruptions))
+	for i := range interruptions {
+		errs[i] = c.interrupt(ctx, interruptions[i])
+	}
+	if err := multierr.Combine(errs...); err != nil {
+		return reconciler.Result{}, err
+	}
+	return reconciler.Result{RequeueAfter: pollingPeriod}, nil
+}
+
+// interrupt launches replacements for the pods on the interrupted NodeClaim and deletes it
+func (c *Controller) interrupt(ctx context.Context, interruption cloudprovider.Interruption) error {
+	nodeClaims, err := nodeclaimutils.ListManaged(ctx, c.kubeClient, c.cloudProvider, nodeclaimutils.ForProviderID(interruption.ProviderID))
+	if err != nil {
+		return fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	if len(nodeClaims) != 1 {
+		return nil
+	}
+	nodeClaim := nodeClaims[0]
+	if !nodeClaim.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("NodeClaim", klog.KObj(nodeClaim), "reason", interruption.Reason))
+	if nodeClaim.Status.NodeName != "" {
+		ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("Node", klog.KRef("", nodeClaim.Status.NodeName)))
+	}
+
+	// 1. Mark the NodeClaim as interrupted before launching its replacements. The mark records that replacements are
+	// being launched, so that they're only launched once even if recording their names fails.
+	replacements, launched := replacementsOf(nodeClaim)
+	if !launched {
+		c.recorder.Publish(InterruptedEvent(nodeClaim, interruption))
+	}
+	if err := c.markInterrupted(ctx, nodeClaim, interruption.Deadline); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	// 2. Mark the NodeClaim for deletion, so that it isn't considered by other disruption methods or by scheduling
+	c.cluster.MarkForDeletion(interruption.ProviderID)
+	// 3. Launch replacements for the NodeClaim's pods, so that they can reschedule as soon as they're evicted. The
+	// instance is interrupted regardless, so pods that we fail to launch capacity for are left to the provisioner.
+	if !launched {
+		var err error
+		if replacements, err = c.launchReplacements(ctx, nodeClaim); err != nil {
+			log.FromContext(ctx).Error(err, "failed launching replacements for interrupted nodeclaim")
+		}
+		if err := c.recordReplacements(ctx, nodeClaim, replacements); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+	// 4. Wait for the replacements to initialize, so that the pods have somewhere to go when they're evicted. The
+	// instance is gone after the deadline, so there's no reason to wait past it.
+	if interruption.Deadline.IsZero() || c.clock.Now().Before(interruption.Deadline) {
+		initialized, err := c.replacementsInitialized(ctx, replacements)
+		if err != nil {
+			return err
+		}
+		if !initialized {
+			log.FromContext(ctx).WithValues("NodeClaims", replacements).V(1).Info("waiting on replacements for interrupted nodeclaim to initialize")
+			return nil
+		}
+	}
+	// 5. Delete the NodeClaim, which drains its node before the termination timestamp
+	if err := c.kubeClient.Delete(ctx, nodeClaim); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	log.FromContext(ctx).Info("deleting interrupted nodeclaim")
+	labels := map[string]string{
+		metrics.ReasonLabel:              metrics.InterruptedReason,
+		metrics.NodePoolLabel:            nodeClaim.Labels[v1.NodePoolLabelKey],
+		metrics.CapacityTypeLabel:        nodeClaim.Labels[v1.CapacityTypeLabelKey],
+		metrics.ConsolidationPolicyLabel: "",
+		metrics.TerminationModeLabel:     nodeclaimutils.DisruptionTerminationMode(nodeClaim),
+	}
+	metrics.NodeClaimsDisruptedTotal.Inc(labels)
+	reschedulablePods, err := nodeutils.ReschedulablePods(ctx, c.kubeClient, nodeClaim.Status.NodeName)
+	if err != nil {
+		log.FromContext(ctx).V(1).Info("listing reschedulable pods for disruption metric", "error", err.Error())
+	}
+	metrics.PodsDisruptionInitiatedTotal.Add(float64(len(reschedulablePods)), labels)
+	return nil
+}
+
+// launchReplacements schedules the reschedulable pods of the NodeClaim's node against the rest of the cluster, and
+// launches NodeClaims for the pods that don't fit on existing capacity. It returns the names of the launched NodeClaims.
+func (c *Controller) launchReplacements(ctx context.Context, nodeClaim *v1.NodeClaim) ([]string, error) {
+	if nodeClaim.Status.NodeName == "" {
+		return nil, nil
+	}
+	pods, err := nodeutils.ReschedulablePods(ctx, c.kubeClient, nodeClaim.Status.NodeName)
+	if err != nil {
+		return nil, fmt.Errorf("listing reschedulable pods, %w", err)
+	}
+	if len(pods) == 0 {
+		return nil, nil
+	}
+	stateNodes := lo.Filter(c.cluster.DeepCopyNodes().Active(), func(n *state.StateNode, _ int) bool {
+		return n.ProviderID() != nodeClaim.Status.ProviderID
+	})
+	pods = c.provisioner.ApplyPredictions(ctx, pods)
+
+	var opts []scheduling.Options
+	if options.FromContext(ctx).PreferencePolicy == options.PreferencePolicyIgnore {
+		opts = append(opts, scheduling.IgnorePreferences)
+	}
+	opts = append(opts, scheduling.MinValuesPolicy(options.FromContext(ctx).MinValuesPolicy))
+	// The pods are migrating off of the interrupted node, so the devices they hold can be reallocated
+	deletingPodUIDs := sets.New(lo.Map(pods, func(p *corev1.Pod, _ int) types.UID { return p.UID })...)
+	scheduler, err := c.provisioner.NewScheduler(ctx, pods, stateNodes, deletingPodUIDs, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating scheduler, %w", err)
+	}
+	results, err := scheduler.Solve(ctx, pods)
+	if err != nil {
+		return nil, fmt.Errorf("scheduling pods, %w", err)
+	}
+	results = results.TruncateInstanceTypes(ctx, scheduling.MaxInstanceTypes)
+	if len(results.PodErrors) > 0 {
+		log.FromContext(ctx).WithValues("pods", len(results.PodErrors)).V(1).Info("unable to schedule all pods of interrupted nodeclaim")
+	}
+	if len(results.NewNodeClaims) == 0 {
+		return nil, nil
+	}
+	nodeClaimNames, err := c.provisioner.CreateNodeClaims(ctx, results.NewNodeClaims, provisioning.WithReason(metrics.InterruptedReason))
+	nodeClaimNames = lo.Compact(nodeClaimNames)
+	if err != nil {
+		return nodeClaimNames, fmt.Errorf("creating nodeclaims, %w", err)
+	}
+	log.FromContext(ctx).WithValues("NodeClaims", nodeClaimNames).Info("launched replacements for interrupted nodeclaim")
+	return nodeClaimNames, nil
+}
+
+// replacementsOf returns the replacements that were launched for the interrupted NodeClaim, and whether they were
+// launched at all
+func replacementsOf(nodeClaim *v1.NodeClaim) ([]string, bool) {
+	value, ok := nodeClaim.Annotations[v1.InterruptionReplacementsAnnotationKey]
+	if !ok {
+		return nil, false
+	}
+	return lo.Compact(strings.Split(value, ",")), true
+}
+
+// replacementsInitialized returns true once every replacement has initialized. Replacements that were deleted, e.g.
+// because they failed to launch, aren't waited on.
+func (c *Controller) replacementsInitialized(ctx context.Context, replacements []string) (bool, error) {
+	for _, name := range replacements {
+		nodeClaim := &v1.NodeClaim{}
+		if err := c.kubeClient.Get(ctx, types.NamespacedName{Name: name}, nodeClaim); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return false, fmt.Errorf("getting replacement nodeclaim, %w", err)
+		}
+		if !nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized).IsTrue() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// markInterrupted sets the DisruptionReason condition of the NodeClaim and marks that its replacements are being
+// launched. It also sets the NodeClaim's termination timestamp to the interruption's deadline unless the NodeClaim
+// already has to be terminated sooner.
+func (c *Controller) markInterrupted(ctx context.Context, nodeClaim *v1.NodeClaim, deadline time.Time) error {
+	stored := nodeClaim.DeepCopy()
+	nodeClaim.StatusConditions(status.WithClock(c.clock)).SetTrueWithReason(v1.ConditionTypeDisruptionReason, string(v1.DisruptionReasonInterrupted), string(v1.DisruptionReasonInterrupted))
+	if !equality.Semantic.DeepEqual(stored, nodeClaim) {
+		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+		// can cause races due to the fact that it fully replaces the list on a change
+		// Here, we are updating the status condition list
+		if err := c.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+			return fmt.Errorf("patching nodeclaim status, %w", err)
+		}
+	}
+	stored = nodeClaim.DeepCopy()
+	if _, ok := nodeClaim.Annotations[v1.InterruptionReplacementsAnnotationKey]; !ok {
+		nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.InterruptionReplacementsAnnotationKey: ""})
+	}
+	if !deadline.IsZero() {
+		terminationTime, err := time.Parse(time.RFC3339, nodeClaim.Annotations[v1.NodeClaimTerminationTimestampAnnotationKey])
+		if err != nil || terminationTime.After(deadline) {
+			nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.NodeClaimTerminationTimestampAnnotationKey: deadline.UTC().Format(time.RFC3339)})
+		}
+	}
+	if equality.Semantic.DeepEqual(stored, nodeClaim) {
+		return nil
+	}
+	// We use client.MergeFromWithOptimisticLock so that we don't overwrite a termination timestamp that another
+	// controller has set in the meantime
+	if err := c.kubeClient.Patch(ctx, nodeClaim, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("patching nodeclaim, %w", err)
+	}
+	log.FromContext(ctx).WithValues(
+		v1.InterruptionReplacementsAnnotationKey, nodeClaim.Annotations[v1.InterruptionReplacementsAnnotationKey],
+		v1.NodeClaimTerminationTimestampAnnotationKey, nodeClaim.Annotations[v1.NodeClaimTerminationTimestampAnnotationKey],
+	).Info("annotated nodeclaim")
+	return nil
+}
+
+// recordReplacements records the names of the NodeClaim's replacements, so that they're waited on if disrupting the
+// NodeClaim has to be retried
+func (c *Controller) recordReplacements(ctx context.Context, nodeClaim *v1.NodeClaim, replacements []string) error {
+	if len(replacements) == 0 {
+		return nil
+	}
+	value := strings.Join(replacements, ",")
+	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := c.kubeClient.Get(ctx, client.ObjectKeyFromObject(nodeClaim), nodeClaim); err != nil {
+			return err
+		}
+		stored := nodeClaim.DeepCopy()
+		nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.InterruptionReplacementsAnnotationKey: value})
+		// We use client.MergeFromWithOptimisticLock so that we don't overwrite a termination timestamp that another
+		// controller has set in the meantime
+		return c.kubeClient.Patch(ctx, nodeClaim, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{}))
+	}); err != nil {
+		return fmt.Errorf("patching nodeclaim, %w", err)
+	}
+	log.FromContext(ctx).WithValues(v1.InterruptionReplacementsAnnotationKey, value).Info("annotated nodeclaim")
+	return nil
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named(c.Name()).
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(c))
+}
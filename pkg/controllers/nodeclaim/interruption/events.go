@@ -0,0 +1,48 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interruption
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+func InterruptedEvent(nodeClaim *v1.NodeClaim, interruption cloudprovider.Interruption) events.Event {
+	message := "Instance will be interrupted"
+	if interruption.Reason != "" {
+		message = fmt.Sprintf("%s (%s)", message, interruption.Reason)
+	}
+	if !interruption.Deadline.IsZero() {
+		message = fmt.Sprintf("%s at %s", message, interruption.Deadline.UTC().Format(time.RFC3339))
+	}
+	if interruption.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, interruption.Message)
+	}
+	return events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.Interrupted,
+		Message:        message,
+		DedupeValues:   []string{string(nodeClaim.UID)},
+	}
+}
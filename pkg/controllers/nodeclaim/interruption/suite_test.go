@@ -0,0 +1,275 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interruption_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/dynamicresources/deviceallocation"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/interruption"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/state/cost"
+	"sigs.k8s.io/karpenter/pkg/state/prediction"
+	"sigs.k8s.io/karpenter/pkg/state/virtualpods"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var env *test.Environment
+var cluster *state.Cluster
+var cloudProvider *fake.CloudProvider
+var recorder *test.EventRecorder
+var nodeStateController *informer.NodeController
+var nodeClaimStateController *informer.NodeClaimController
+var interruptionController *interruption.Controller
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Interruption")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeClaimProviderIDFieldIndexer(ctx)))
+	ctx = options.ToContext(ctx, test.Options())
+	cloudProvider = fake.NewCloudProvider()
+	cluster = state.NewCluster(env.Clock, env.Client, cloudProvider)
+	nodeStateController = informer.NewNodeController(env.Client, cluster)
+	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cloudProvider, cluster, cost.NewClusterCost(ctx, cloudProvider, env.Client))
+	recorder = test.NewEventRecorder()
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	cloudProvider.Reset()
+	cluster.Reset()
+	recorder.Reset()
+	env.Clock.SetTime(time.Now())
+	prov := provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, env.Clock, deviceallocation.NewController(env.Client), virtualpods.NewVirtualPodCache(env.Client), prediction.NewStore())
+	interruptionController = interruption.NewController(env.Clock, env.Client, cloudProvider, cloudProvider, cluster, prov, recorder)
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = Describe("Interruption", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+	var deadline time.Time
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:     nodePool.Name,
+					v1.CapacityTypeLabelKey: v1.CapacityTypeSpot,
+				},
+				Finalizers: []string{v1.TerminationFinalizer},
+			},
+			Status: v1.NodeClaimStatus{
+				ProviderID: test.RandomProviderID(),
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:  resource.MustParse("2"),
+					corev1.ResourcePods: resource.MustParse("10"),
+				},
+			},
+		})
+		deadline = env.Clock.Now().Add(2 * time.Minute).Truncate(time.Second)
+	})
+	It("should launch replacements and delete the interrupted nodeclaim", func() {
+		pod := test.Pod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		cloudProvider.PendingInterruptions = []cloudprovider.Interruption{{
+			ProviderID: nodeClaim.Status.ProviderID,
+			Reason:     "SpotInterruption",
+			Deadline:   deadline,
+		}}
+
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(2))
+		replacement, ok := lo.Find(nodeClaims, func(n *v1.NodeClaim) bool { return n.Name != nodeClaim.Name })
+		Expect(ok).To(BeTrue())
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.InterruptionReplacementsAnnotationKey, replacement.Name))
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodeClaimTerminationTimestampAnnotationKey, deadline.UTC().Format(time.RFC3339)))
+		cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason)
+		Expect(cond.IsTrue()).To(BeTrue())
+		Expect(cond.Reason).To(Equal(string(v1.DisruptionReasonInterrupted)))
+		Expect(ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim).MarkedForDeletion()).To(BeTrue())
+		// The nodeclaim isn't deleted until its replacement is initialized
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeTrue())
+
+		// The replacement is only launched once
+		ExpectSingletonReconciled(ctx, interruptionController)
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+		Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeTrue())
+
+		ExpectMakeNodeClaimsInitialized(ctx, env.Client, env.Clock, replacement)
+		ExpectSingletonReconciled(ctx, interruptionController)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeFalse())
+		Expect(recorder.Calls(events.Interrupted)).To(Equal(1))
+		ExpectMetricCounterValue(metrics.NodeClaimsDisruptedTotal, 1, map[string]string{
+			metrics.ReasonLabel:   metrics.InterruptedReason,
+			metrics.NodePoolLabel: nodePool.Name,
+		})
+	})
+	It("should delete the interrupted nodeclaim once the deadline is reached, even if its replacements aren't initialized", func() {
+		pod := test.Pod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		cloudProvider.PendingInterruptions = []cloudprovider.Interruption{{ProviderID: nodeClaim.Status.ProviderID, Deadline: deadline}}
+
+		ExpectSingletonReconciled(ctx, interruptionController)
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+		Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeTrue())
+
+		env.Clock.SetTime(deadline)
+		ExpectSingletonReconciled(ctx, interruptionController)
+		Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeFalse())
+	})
+	It("should not launch replacements again for a nodeclaim that has recorded its replacements", func() {
+		pod := test.Pod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		}})
+		nodeClaim.Annotations = map[string]string{v1.InterruptionReplacementsAnnotationKey: ""}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		cloudProvider.PendingInterruptions = []cloudprovider.Interruption{{ProviderID: nodeClaim.Status.ProviderID, Deadline: deadline}}
+
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+		Expect(cloudProvider.CreateCalls).To(BeEmpty())
+		Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeFalse())
+	})
+	It("should delete the interrupted nodeclaim without replacements when it has no reschedulable pods", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		cloudProvider.PendingInterruptions = []cloudprovider.Interruption{{ProviderID: nodeClaim.Status.ProviderID, Deadline: deadline}}
+
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+		Expect(cloudProvider.CreateCalls).To(BeEmpty())
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeFalse())
+	})
+	It("should not delay an earlier termination timestamp", func() {
+		terminationTime := deadline.Add(-time.Minute).UTC().Format(time.RFC3339)
+		nodeClaim.Annotations = map[string]string{v1.NodeClaimTerminationTimestampAnnotationKey: terminationTime}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		cloudProvider.PendingInterruptions = []cloudprovider.Interruption{{ProviderID: nodeClaim.Status.ProviderID, Deadline: deadline}}
+
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeFalse())
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodeClaimTerminationTimestampAnnotationKey, terminationTime))
+	})
+	It("should not set a termination timestamp when the interruption has no deadline", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		cloudProvider.PendingInterruptions = []cloudprovider.Interruption{{ProviderID: nodeClaim.Status.ProviderID}}
+
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeFalse())
+		Expect(nodeClaim.Annotations).ToNot(HaveKey(v1.NodeClaimTerminationTimestampAnnotationKey))
+	})
+	It("should wait for replacements to initialize when the interruption has no deadline", func() {
+		pod := test.Pod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		cloudProvider.PendingInterruptions = []cloudprovider.Interruption{{ProviderID: nodeClaim.Status.ProviderID}}
+
+		ExpectSingletonReconciled(ctx, interruptionController)
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(2))
+		Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeTrue())
+
+		replacement, ok := lo.Find(nodeClaims, func(n *v1.NodeClaim) bool { return n.Name != nodeClaim.Name })
+		Expect(ok).To(BeTrue())
+		ExpectMakeNodeClaimsInitialized(ctx, env.Client, env.Clock, replacement)
+		ExpectSingletonReconciled(ctx, interruptionController)
+		Expect(ExpectExists(ctx, env.Client, nodeClaim).DeletionTimestamp.IsZero()).To(BeFalse())
+	})
+	It("should ignore interruptions of instances without a nodeclaim", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		cloudProvider.PendingInterruptions = []cloudprovider.Interruption{{ProviderID: test.RandomProviderID(), Deadline: deadline}}
+
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeTrue())
+		Expect(recorder.Calls(events.Interrupted)).To(Equal(0))
+	})
+	It("should return an error when listing interruptions fails", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, env.Clock, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+		cloudProvider.NextInterruptionsErr = fmt.Errorf("failed listing interruptions")
+
+		_, err := interruptionController.Reconcile(ctx)
+		Expect(err).To(HaveOccurred())
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeTrue())
+	})
+})
@@ -0,0 +1,96 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+// coschedulingPodGroupGVK is the PodGroup of the scheduler-plugins coscheduling plugin, which defines the group's size
+// as its minMember
+var coschedulingPodGroupGVK = schema.GroupVersionKind{Group: "scheduling.x-k8s.io", Version: "v1alpha1", Kind: "PodGroup"}
+
+// podGroupSizes returns the number of pending members that each pending pod group with a known size needs before
+// capacity is launched for it. A group's size is the karpenter.sh/pod-group-size annotation of its members, or the
+// minMember of its coscheduling PodGroup. Members that are already bound to nodes count towards the size.
+func (p *Provisioner) podGroupSizes(ctx context.Context, pods []*corev1.Pod) (map[types.NamespacedName]int, error) {
+	sizes := map[types.NamespacedName]int{}
+	selectors := map[types.NamespacedName]*metav1.LabelSelector{}
+	for _, pod := range pods {
+		group, ok := podutils.PodGroup(pod)
+		if !ok || !podutils.IsProvisionable(pod) {
+			continue
+		}
+		selectors[group], _ = podutils.PodGroupSelector(pod)
+		if size, ok := podutils.PodGroupSize(pod); ok {
+			sizes[group] = max(sizes[group], size)
+			continue
+		}
+		if _, ok := sizes[group]; ok || !podutils.IsCoschedulingPodGroup(pod) {
+			continue
+		}
+		size, err := p.coschedulingPodGroupSize(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		if size > 0 {
+			sizes[group] = size
+		}
+	}
+	for group, size := range sizes {
+		podList := &corev1.PodList{}
+		if err := p.kubeClient.List(ctx, podList, client.InNamespace(group.Namespace), client.MatchingLabels(selectors[group].MatchLabels)); err != nil {
+			return nil, fmt.Errorf("listing pod group members, %w", err)
+		}
+		bound := lo.CountBy(podList.Items, func(pod corev1.Pod) bool {
+			return pod.Spec.NodeName != "" && !podutils.IsTerminal(&pod) && !podutils.IsTerminating(&pod)
+		})
+		sizes[group] = size - bound
+	}
+	return lo.PickBy(sizes, func(_ types.NamespacedName, size int) bool { return size > 0 }), nil
+}
+
+// coschedulingPodGroupSize returns the minMember of the group's coscheduling PodGroup, or zero if the PodGroup or its
+// CRD doesn't exist
+func (p *Provisioner) coschedulingPodGroupSize(ctx context.Context, group types.NamespacedName) (int, error) {
+	podGroup := &unstructured.Unstructured{}
+	podGroup.SetGroupVersionKind(coschedulingPodGroupGVK)
+	if err := p.kubeClient.Get(ctx, group, podGroup); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("getting coscheduling podgroup, %w", err)
+	}
+	size, _, err := unstructured.NestedInt64(podGroup.Object, "spec", "minMember")
+	if err != nil {
+		return 0, fmt.Errorf("getting coscheduling podgroup minMember, %w", err)
+	}
+	return int(size), nil
+}
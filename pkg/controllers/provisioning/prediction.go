@@ -0,0 +1,98 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/state/prediction"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// ApplyPredictions returns the pods sized by the active prediction of the workload that owns them. Pods
+// without a prediction are returned as-is. Pods are never mutated in place; a copy is returned for every pod
+// whose effective requests change. This is a no-op unless the ResourcePrediction feature gate is enabled.
+func (p *Provisioner) ApplyPredictions(ctx context.Context, pods []*corev1.Pod) []*corev1.Pod {
+	if !options.FromContext(ctx).FeatureGates.ResourcePrediction || p.predictionStore == nil {
+		return pods
+	}
+	out := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		pred, ok := p.predictionFor(ctx, pod)
+		if !ok {
+			out = append(out, pod)
+			continue
+		}
+		predicted, changed := pred.Apply(pod)
+		if changed {
+			requests := resources.Ceiling(predicted).Requests
+			if p.cm.HasChanged(string(pod.UID)+"/prediction", requests) {
+				log.FromContext(ctx).WithValues("Pod", klog.KObj(pod), "requests", resources.Ceiling(pod).Requests, "predicted-requests", requests).
+					Info("using predicted resource requests for pod")
+				p.recorder.Publish(PredictedRequestsEvent(pod, requests))
+			}
+		}
+		out = append(out, predicted)
+	}
+	return out
+}
+
+// predictionFor resolves the workload that controls the pod and returns its active prediction. Predictions
+// are keyed by the UID of the workload targeted by the source (e.g. a VPA's targetRef). Since Deployments
+// control their pods through a ReplicaSet, the ReplicaSet's controller is checked when the pod's direct
+// controller has no prediction.
+func (p *Provisioner) predictionFor(ctx context.Context, pod *corev1.Pod) (*prediction.Prediction, bool) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil, false
+	}
+	if pred, ok := p.predictionStore.Get(owner.UID); ok {
+		return pred, true
+	}
+	if owner.Kind != "ReplicaSet" {
+		return nil, false
+	}
+	rs := &appsv1.ReplicaSet{}
+	if err := p.kubeClient.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: owner.Name}, rs); err != nil || rs.UID != owner.UID {
+		return nil, false
+	}
+	rsOwner := metav1.GetControllerOf(rs)
+	if rsOwner == nil {
+		return nil, false
+	}
+	return p.predictionStore.Get(rsOwner.UID)
+}
+
+func PredictedRequestsEvent(pod *corev1.Pod, requests corev1.ResourceList) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.PredictedRequests,
+		Message:        fmt.Sprintf("Pod is sized by its workload's predicted resource requests: %s", resources.String(requests)),
+		DedupeValues:   []string{string(pod.UID), resources.String(requests)},
+	}
+}
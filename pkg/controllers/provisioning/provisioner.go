@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -58,9 +59,12 @@ import (
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/scheduling/dynamicresources"
+	"sigs.k8s.io/karpenter/pkg/state/prediction"
 	"sigs.k8s.io/karpenter/pkg/utils/daemonset"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	"sigs.k8s.io/karpenter/pkg/utils/pdb"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 )
 
@@ -92,11 +96,14 @@ type Provisioner struct {
 	clock                      clock.Clock
 	deviceAllocationController *deviceallocation.Controller
 	virtualPodCache            *virtualpods.Cache
+	predictionStore            *prediction.Store
+	schedulingCache            *scheduler.Cache
 }
 
 func NewProvisioner(kubeClient client.Client, recorder events.Recorder,
 	cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster,
 	clock clock.Clock, deviceAllocationController *deviceallocation.Controller, virtualPodCache *virtualpods.Cache,
+	predictionStore *prediction.Store,
 ) *Provisioner {
 	p := &Provisioner{
 		batcher:                    NewBatcher[types.UID](clock),
@@ -109,6 +116,8 @@ func NewProvisioner(kubeClient client.Client, recorder events.Recorder,
 		clock:                      clock,
 		deviceAllocationController: deviceAllocationController,
 		virtualPodCache:            virtualPodCache,
+		predictionStore:            predictionStore,
+		schedulingCache:            scheduler.NewCache(),
 	}
 	return p
 }
@@ -347,6 +356,21 @@ func (p *Provisioner) NewScheduler(
 		allocator = dynamicresources.NewAllocator(inClusterSlices, allocatedDevices, dynamicresources.BuildAttributeBindings(instanceTypes), p.kubeClient, deletingPodUIDs)
 	}
 
+	// Pod groups that don't completely schedule are solved again without their members on a new scheduler. The state
+	// nodes are copied for it, since the scheduler records the host port and volume usage of its pods on them.
+	if lo.SomeBy(pods, func(pod *corev1.Pod) bool { _, ok := podutils.PodGroup(pod); return ok }) {
+		sizes, err := p.podGroupSizes(ctx, pods)
+		if err != nil {
+			return nil, fmt.Errorf("getting pod group sizes, %w", err)
+		}
+		opts = append(slices.Clip(opts), scheduler.PodGroupSizes(sizes))
+		rebuildNodes := lo.Map(stateNodes, func(n *state.StateNode, _ int) *state.StateNode { return n.DeepCopy() })
+		rebuildOpts := slices.Clip(opts)
+		opts = append(rebuildOpts, scheduler.WithRebuild(func(ctx context.Context, pods []*corev1.Pod) (*scheduler.Scheduler, error) {
+			return p.NewScheduler(ctx, pods, rebuildNodes, deletingPodUIDs, rebuildOpts...)
+		}))
+	}
+
 	// Pass volumeReqs to scheduler - added to nodeRequirements for NodeClaim zone selection
 	return scheduler.NewScheduler(ctx, p.kubeClient, nodePools, p.cluster, stateNodes, topology, instanceTypes, daemonSetPods, p.recorder, p.clock, volumeReqs, allocator, opts...), nil
 }
@@ -386,6 +410,7 @@ func (p *Provisioner) Schedule(ctx context.Context) (scheduler.Results, error) {
 	if len(pods) == 0 {
 		return scheduler.Results{}, nil
 	}
+	pods = p.ApplyPredictions(ctx, pods)
 	deletingPodUIDs := sets.New(lo.Map(deletingNodePods, func(p *corev1.Pod, _ int) types.UID { return p.UID })...)
 	log.FromContext(ctx).V(1).WithValues("pending-pods", len(pendingPods), "deleting-pods", len(deletingNodePods)).Info("computing scheduling decision for provisionable pod(s)")
 
@@ -397,6 +422,24 @@ func (p *Provisioner) Schedule(ctx context.Context) (scheduler.Results, error) {
 	if options.FromContext(ctx).PreferencePolicy == options.PreferencePolicyIgnore {
 		opts = append(opts, scheduler.IgnorePreferences)
 	}
+	if options.FromContext(ctx).SchedulingSolver == options.SchedulingSolverBestFit {
+		opts = append(opts, scheduler.BestFitBinPacking(options.FromContext(ctx).SchedulingSolverBudget))
+	}
+	if options.FromContext(ctx).SchedulingDecisionTrace {
+		opts = append(opts, scheduler.RecordDecisionTraces)
+	}
+	if preemption := options.FromContext(ctx).SchedulingPreemption; preemption != options.SchedulingPreemptionDisabled {
+		pdbs, err := pdb.NewLimits(ctx, p.kubeClient)
+		if err != nil {
+			return scheduler.Results{}, fmt.Errorf("tracking PodDisruptionBudgets, %w", err)
+		}
+		opts = append(opts, scheduler.SimulatePreemption(pdbs, preemption == options.SchedulingPreemptionSimulateAndProvision))
+	}
+	// Only the provisioning loop is incrementally scheduled, since disruption simulations schedule against a different
+	// view of the cluster on every run
+	if options.FromContext(ctx).FeatureGates.IncrementalScheduling {
+		opts = append(opts, scheduler.WithCache(p.schedulingCache))
+	}
 	s, err := p.NewScheduler(
 		ctx,
 		pods,
@@ -0,0 +1,230 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/awslabs/operatorpkg/serrors"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/scheduling/dynamicresources"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// repackBestFit repacks the pods that the greedy pass scheduled to new NodeClaims to lower the launch price of the batch.
+// The greedy pass places each pod on the first NodeClaim that can fit it, which grows NodeClaims towards the largest
+// compatible instance types even when a few smaller instance types would be cheaper. The repack is a best-fit decreasing
+// pass over the pods of each NodeClaimTemplate, placing each pod where it adds the least to the launch price, and is
+// only committed if it is cheaper than the greedy result and finishes within the solver budget. Placements that can reserve
+// capacity are priced at the reservation's price, so reserved capacity is preferred while it lasts. This is a heuristic, not
+// an exact solver, so it can miss cheaper packings. NodePools whose pods participate in topologies, request DRA
+// resource claims, or relaxed minValues are skipped. remainingResources are the NodePool limits before the greedy pass.
+func (s *Scheduler) repackBestFit(ctx context.Context, remainingResources map[string]corev1.ResourceList) {
+	deadline := s.clock.Now().Add(s.solverBudget)
+	repacked := map[string][]*NodeClaim{}
+	for _, template := range s.nodeClaimTemplates {
+		greedy := lo.Filter(s.newNodeClaims, func(n *NodeClaim, _ int) bool {
+			return n.NodePoolName == template.NodePoolName
+		})
+		if nodeClaims, remaining, ok := s.repack(ctx, template, greedy, remainingResources[template.NodePoolName], deadline); ok {
+			repacked[template.NodePoolName] = nodeClaims
+			s.remainingResources[template.NodePoolName] = remaining
+		}
+	}
+	if len(repacked) == 0 {
+		return
+	}
+	newNodeClaims := lo.Reject(s.newNodeClaims, func(n *NodeClaim, _ int) bool {
+		_, ok := repacked[n.NodePoolName]
+		return ok
+	})
+	for _, template := range s.nodeClaimTemplates {
+		newNodeClaims = append(newNodeClaims, repacked[template.NodePoolName]...)
+	}
+	s.newNodeClaims = newNodeClaims
+}
+
+// repack re-places the pods from the greedy NodeClaims for a NodeClaimTemplate onto a new set of NodeClaims. It returns
+// the repacked NodeClaims, the NodePool's remaining resources after launching them, and whether they should replace the
+// greedy NodeClaims. When the repack isn't used, the greedy NodeClaims' reservations are restored.
+//
+//nolint:gocyclo
+func (s *Scheduler) repack(ctx context.Context, template *NodeClaimTemplate, greedy []*NodeClaim, remaining corev1.ResourceList, deadline time.Time) ([]*NodeClaim, corev1.ResourceList, bool) {
+	if len(greedy) == 0 {
+		return nil, nil, false
+	}
+	var pods []*corev1.Pod
+	for _, n := range greedy {
+		if n.Annotations[v1.NodeClaimMinValuesRelaxedAnnotationKey] == "true" {
+			return nil, nil, false
+		}
+		// Moving a pod that participates in a topology would invalidate the counts that the greedy pass recorded for it
+		for _, p := range n.Pods {
+			if s.cachedPodData[p.UID].HasResourceClaimRequests || !s.topology.isIndependent(p, n.Spec.Taints, n.Requirements, scheduling.AllowUndefinedWellKnownLabels) {
+				return nil, nil, false
+			}
+		}
+		pods = append(pods, n.Pods...)
+	}
+	sort.SliceStable(pods, byCPUAndMemoryDescending(pods, s.cachedPodData))
+
+	// Release the greedy NodeClaims' reservations so that they're available to the repacked NodeClaims
+	for _, n := range greedy {
+		s.reservationManager.Release(n.hostname, n.reservedOfferings...)
+	}
+	var nodeClaims []*NodeClaim
+	fallback := func() {
+		for _, n := range nodeClaims {
+			s.reservationManager.Release(n.hostname, n.reservedOfferings...)
+		}
+		for _, n := range greedy {
+			s.reservationManager.Reserve(n.hostname, n.reservedOfferings...)
+		}
+	}
+	for _, p := range pods {
+		if ctx.Err() != nil || !s.clock.Now().Before(deadline) {
+			log.FromContext(ctx).V(1).WithValues("NodePool", klog.KRef("", template.NodePoolName), "budget", s.solverBudget).Info("solver budget exceeded, using greedy scheduling result")
+			fallback()
+			return nil, nil, false
+		}
+		nodeClaim, err := s.bestFit(ctx, template, nodeClaims, remaining, p)
+		if err != nil {
+			log.FromContext(ctx).V(1).WithValues("NodePool", klog.KRef("", template.NodePoolName), "Pod", klog.KObj(p)).Info("failed repacking pod, using greedy scheduling result", "error", err)
+			fallback()
+			return nil, nil, false
+		}
+		if !lo.Contains(nodeClaims, nodeClaim) {
+			nodeClaims = append(nodeClaims, nodeClaim)
+			remaining = subtractMax(remaining, nodeClaim.InstanceTypeOptions)
+		}
+	}
+	greedyPrice, repackedPrice := totalLaunchPrice(greedy), totalLaunchPrice(nodeClaims)
+	if repackedPrice >= greedyPrice {
+		fallback()
+		return nil, nil, false
+	}
+	log.FromContext(ctx).V(1).WithValues(
+		"NodePool", klog.KRef("", template.NodePoolName),
+		"greedy-nodeclaims", len(greedy),
+		"greedy-price", greedyPrice,
+		"nodeclaims", len(nodeClaims),
+		"price", repackedPrice,
+	).Info("repacked pods onto cheaper nodeclaim(s)")
+	return nodeClaims, remaining, true
+}
+
+// bestFit adds the pod to the NodeClaim where it increases the launch price the least, creating a new NodeClaim from the
+// template if that's cheaper than growing any of the existing NodeClaims and the NodePool's limits allow it.
+func (s *Scheduler) bestFit(ctx context.Context, template *NodeClaimTemplate, nodeClaims []*NodeClaim, remaining corev1.ResourceList, p *corev1.Pod) (*NodeClaim, error) {
+	podData := s.cachedPodData[p.UID]
+
+	var best, newNodeClaim *placement
+	bestDelta := math.MaxFloat64
+	consider := func(n *NodeClaim, price float64) error {
+		r, its, ofs, result, err := n.CanAdd(ctx, p, podData, false, s.allocator)
+		if err != nil {
+			return err
+		}
+		candidate := &placement{nodeClaim: n, requirements: r, instanceTypes: its, offerings: ofs, allocationResult: result}
+		if !lo.Contains(nodeClaims, n) {
+			newNodeClaim = candidate
+		}
+		// Ties are won by the NodeClaims considered first, preferring existing NodeClaims over a new NodeClaim
+		if delta := launchPrice(its, r, ofs) - price; delta < bestDelta {
+			best, bestDelta = candidate, delta
+		}
+		return nil
+	}
+	for _, n := range nodeClaims {
+		_ = consider(n, launchPrice(n.InstanceTypeOptions, n.Requirements, n.reservedOfferings))
+	}
+	if err := s.considerNewNodeClaim(template, remaining, consider); err != nil && best == nil {
+		return nil, err
+	}
+	// None of the placements have a launch price when the pod's compatible offerings are all reserved or unavailable,
+	// in which case the pod goes on a new NodeClaim like it would in the greedy pass
+	if best == nil {
+		best = newNodeClaim
+	}
+	if best == nil {
+		return nil, serrors.Wrap(fmt.Errorf("no nodeclaim can launch with the pod"), "NodePool", klog.KRef("", template.NodePoolName))
+	}
+	best.nodeClaim.Add(ctx, p, podData, best.requirements, best.instanceTypes, best.offerings, best.allocationResult, s.allocator)
+	return best.nodeClaim, nil
+}
+
+// placement is the result of checking whether a pod can be added to a NodeClaim
+type placement struct {
+	nodeClaim        *NodeClaim
+	requirements     scheduling.Requirements
+	instanceTypes    []*cloudprovider.InstanceType
+	offerings        []*cloudprovider.Offering
+	allocationResult *dynamicresources.AllocationResult
+}
+
+// considerNewNodeClaim considers a new NodeClaim from the template, restricted to the instance types that fit within the
+// NodePool's remaining resources
+func (s *Scheduler) considerNewNodeClaim(template *NodeClaimTemplate, remaining corev1.ResourceList, consider func(*NodeClaim, float64) error) error {
+	if nodesRemaining, ok := remaining[resources.Node]; ok && nodesRemaining.IsZero() {
+		return serrors.Wrap(fmt.Errorf("node limits have been exhausted for nodepool"), "NodePool", klog.KRef("", template.NodePoolName))
+	}
+	its := filterByRemainingResources(template.InstanceTypeOptions, remaining)
+	if len(its) == 0 {
+		return serrors.Wrap(fmt.Errorf("all available instance types exceed limits for nodepool"), "NodePool", klog.KRef("", template.NodePoolName))
+	}
+	nodeClaim := NewNodeClaim(template, s.topology, s.daemonOverheadGroups[template], its, s.reservationManager, s.reservedOfferingMode)
+	nodeClaim.Annotations[v1.NodeClaimMinValuesRelaxedAnnotationKey] = "false"
+	return consider(nodeClaim, 0)
+}
+
+// launchPrice returns the cheapest price that a NodeClaim with the given instance types, requirements, and reserved
+// offerings can launch at. A NodeClaim that holds reservations launches into one of them, so it's priced at the cheapest
+// reservation. Placements are priced with the offerings that CanAdd would reserve for them, so reserved capacity that is
+// still available is priced before it's reserved. Reserved offerings that can't be reserved for the NodeClaim can't be
+// launched into, so they're skipped.
+func launchPrice(instanceTypes []*cloudprovider.InstanceType, requirements scheduling.Requirements, reservedOfferings cloudprovider.Offerings) float64 {
+	if len(reservedOfferings) != 0 {
+		return reservedOfferings.Cheapest().Price
+	}
+	price := math.MaxFloat64
+	for _, it := range instanceTypes {
+		for _, o := range it.Offerings {
+			if !o.Available || o.CapacityType() == v1.CapacityTypeReserved || !requirements.IsCompatible(o.Requirements, scheduling.AllowUndefinedWellKnownLabels) {
+				continue
+			}
+			price = math.Min(price, o.Price)
+		}
+	}
+	return price
+}
+
+func totalLaunchPrice(nodeClaims []*NodeClaim) float64 {
+	return lo.SumBy(nodeClaims, func(n *NodeClaim) float64 {
+		return launchPrice(n.InstanceTypeOptions, n.Requirements, n.reservedOfferings)
+	})
+}
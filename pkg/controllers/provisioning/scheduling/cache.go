@@ -0,0 +1,361 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/mitchellh/hashstructure/v2"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	karpopts "sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+)
+
+// Cache persists the parts of a scheduling simulation that are expensive to compute across scheduling loops, so that
+// they're updated incrementally as the cluster changes instead of being rebuilt for every batch of pods:
+//   - the NodeClaimTemplate of each NodePool, with its pre-filtered instance types and daemon overhead groups
+//   - the topology domains of the NodePools' instance types
+//   - the daemon pods that are compatible with each existing node
+//   - the pods that are bound to each existing node, which are only listed again once the node's pods change
+//   - the pods that each topology selects on each node, which are only matched again once the node's pods change
+//
+// Instance types are compared by identity, so cloud providers must return new instance types when they change. Entries
+// that a scheduling loop doesn't use are evicted, so a Cache should only be used by one scheduler at a time.
+type Cache struct {
+	mu sync.Mutex
+
+	templates    map[string]*cachedTemplate    // NodePool name -> template
+	domainGroups *cachedDomainGroups           // topology key -> domains of the NodePools' instance types
+	nodeDaemons  map[string]*cachedNodeDaemons // node name -> compatible daemon pods
+	nodePods     map[string]*cachedNodePods    // node name -> bound pods
+	topologyPods map[uint64]*cachedTopology    // topology selector hash -> selected pods per node
+	topologyLoop uint64
+}
+
+type nodePoolVersion struct {
+	uid        types.UID
+	generation int64
+}
+
+type cachedTemplate struct {
+	nodePool       nodePoolVersion
+	instanceTypes  []*cloudprovider.InstanceType
+	relaxMinValues bool
+	template       *NodeClaimTemplate
+	err            error
+
+	daemonSetKey         uint64
+	daemonOverheadGroups []DaemonOverheadGroup
+}
+
+type cachedDomainGroups struct {
+	nodePools     map[string]nodePoolVersion
+	instanceTypes map[string][]*cloudprovider.InstanceType
+	domainGroups  map[string]TopologyDomainGroup
+}
+
+type cachedNodeDaemons struct {
+	labels       map[string]string
+	taints       []corev1.Taint
+	daemonSetKey uint64
+	daemons      []*corev1.Pod
+}
+
+type cachedNodePods struct {
+	podRevision uint64
+	pods        []cachedPod
+}
+
+// cachedPod is the part of a bound pod that topologies select on
+type cachedPod struct {
+	uid       string
+	namespace string
+	labels    labels.Set
+}
+
+type cachedTopology struct {
+	loop  uint64
+	nodes map[string]*cachedSelectedPods // node name -> selected pods
+}
+
+type cachedSelectedPods struct {
+	podRevision uint64
+	uids        []string
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		templates:    map[string]*cachedTemplate{},
+		nodeDaemons:  map[string]*cachedNodeDaemons{},
+		nodePods:     map[string]*cachedNodePods{},
+		topologyPods: map[uint64]*cachedTopology{},
+	}
+}
+
+// nodeClaimTemplates returns the NodeClaimTemplates of the NodePools with their instance types filtered by the NodePool
+// requirements, along with the errors for the NodePools whose requirements filtered out all instance types. A template
+// is only rebuilt when its NodePool or instance types change.
+func (c *Cache) nodeClaimTemplates(nodePools []*v1.NodePool, instanceTypes map[string][]*cloudprovider.InstanceType, relaxMinValues bool) ([]*NodeClaimTemplate, map[*v1.NodePool]error) {
+	if c != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	errs := map[*v1.NodePool]error{}
+	templates := lo.FilterMap(nodePools, func(np *v1.NodePool, _ int) (*NodeClaimTemplate, bool) {
+		var nct *NodeClaimTemplate
+		var err error
+		if c == nil {
+			nct, err = newFilteredNodeClaimTemplate(np, instanceTypes[np.Name], relaxMinValues)
+		} else {
+			entry, ok := c.templates[np.Name]
+			if !ok || entry.nodePool != versionOf(np) || entry.relaxMinValues != relaxMinValues || !slices.Equal(entry.instanceTypes, instanceTypes[np.Name]) {
+				entry = &cachedTemplate{nodePool: versionOf(np), instanceTypes: instanceTypes[np.Name], relaxMinValues: relaxMinValues}
+				entry.template, entry.err = newFilteredNodeClaimTemplate(np, instanceTypes[np.Name], relaxMinValues)
+				c.templates[np.Name] = entry
+			}
+			nct, err = entry.template, entry.err
+		}
+		if len(nct.InstanceTypeOptions) == 0 {
+			errs[np] = err
+			return nil, false
+		}
+		return nct, true
+	})
+	if c != nil {
+		names := sets.New(lo.Map(nodePools, func(np *v1.NodePool, _ int) string { return np.Name })...)
+		maps.DeleteFunc(c.templates, func(name string, _ *cachedTemplate) bool { return !names.Has(name) })
+	}
+	return templates, errs
+}
+
+// daemonOverheadGroups returns the daemon overhead groups of the NodeClaimTemplates, which are only rebuilt when the
+// template or the daemon pods change
+func (c *Cache) daemonOverheadGroups(ctx context.Context, nodeClaimTemplates []*NodeClaimTemplate, daemonSetPods []*corev1.Pod) map[*NodeClaimTemplate][]DaemonOverheadGroup {
+	if c == nil {
+		return buildDaemonOverheadGroups(ctx, nodeClaimTemplates, daemonSetPods)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := daemonSetKey(ctx, daemonSetPods)
+	return lo.SliceToMap(nodeClaimTemplates, func(nct *NodeClaimTemplate) (*NodeClaimTemplate, []DaemonOverheadGroup) {
+		entry, ok := c.templates[nct.NodePoolName]
+		if !ok || entry.template != nct {
+			return nct, buildDaemonOverheadGroupsForTemplate(ctx, nct, daemonSetPods)
+		}
+		if entry.daemonOverheadGroups == nil || entry.daemonSetKey != key {
+			entry.daemonSetKey = key
+			entry.daemonOverheadGroups = buildDaemonOverheadGroupsForTemplate(ctx, nct, daemonSetPods)
+		}
+		return nct, entry.daemonOverheadGroups
+	})
+}
+
+// compatibleDaemonPods returns the daemon pods that can schedule to the existing node, which are only recomputed when
+// the node's labels or taints, or the daemon pods change
+func (c *Cache) compatibleDaemonPods(node *state.StateNode, taints []corev1.Taint, daemonSetKey uint64, compute func() []*corev1.Pod) []*corev1.Pod {
+	if c == nil {
+		return compute()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.nodeDaemons[node.Name()]
+	if !ok || entry.daemonSetKey != daemonSetKey || !maps.Equal(entry.labels, node.Labels()) || !equality.Semantic.DeepEqual(entry.taints, taints) {
+		entry = &cachedNodeDaemons{labels: node.Labels(), taints: taints, daemonSetKey: daemonSetKey, daemons: compute()}
+		c.nodeDaemons[node.Name()] = entry
+	}
+	return entry.daemons
+}
+
+// evictNodes evicts the cached data of the nodes that are no longer in the cluster
+func (c *Cache) evictNodes(stateNodes []*state.StateNode) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := sets.New(lo.Map(stateNodes, func(n *state.StateNode, _ int) string { return n.Name() })...)
+	maps.DeleteFunc(c.nodeDaemons, func(name string, _ *cachedNodeDaemons) bool { return !names.Has(name) })
+}
+
+// topologyDomainGroups returns the domains of the NodePools' instance types by topology key, which are only rebuilt
+// when the NodePools or their instance types change
+func (c *Cache) topologyDomainGroups(nodePools []*v1.NodePool, instanceTypes map[string][]*cloudprovider.InstanceType) map[string]TopologyDomainGroup {
+	if c == nil {
+		return buildDomainGroups(nodePools, instanceTypes)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	versions := lo.SliceToMap(lo.Filter(nodePools, func(np *v1.NodePool, _ int) bool {
+		_, ok := instanceTypes[np.Name]
+		return ok
+	}), func(np *v1.NodePool) (string, nodePoolVersion) { return np.Name, versionOf(np) })
+	if c.domainGroups == nil || !maps.Equal(c.domainGroups.nodePools, versions) ||
+		!maps.EqualFunc(c.domainGroups.instanceTypes, instanceTypes, slices.Equal[[]*cloudprovider.InstanceType]) {
+		c.domainGroups = &cachedDomainGroups{
+			nodePools:     versions,
+			instanceTypes: maps.Clone(instanceTypes),
+			domainGroups:  buildDomainGroups(nodePools, instanceTypes),
+		}
+	}
+	return c.domainGroups.domainGroups
+}
+
+// startTopology evicts the pods of the topologies that weren't counted by the previous topology. It's called when a
+// new topology is built for a scheduling loop.
+func (c *Cache) startTopology() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	maps.DeleteFunc(c.topologyPods, func(_ uint64, entry *cachedTopology) bool { return entry.loop != c.topologyLoop })
+	c.topologyLoop++
+}
+
+// countDomains records the pods that the topology group selects in the domains of the nodes that they're bound to. It
+// counts the same pods as Topology.countDomains, but from the nodes in cluster state rather than from a pod list. Both
+// the pods bound to each node and the pods that the group selects are cached, and only the nodes whose pods changed
+// are listed and matched again, so the counts are incrementally updated.
+func (c *Cache) countDomains(ctx context.Context, t *Topology, tg *TopologyGroup) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := lo.Must(hashstructure.Hash([]any{tg.namespaces, hashSelector(tg.rawSelector)}, hashstructure.FormatV2, &hashstructure.HashOptions{SlicesAsSets: true}))
+	entry, ok := c.topologyPods[key]
+	if !ok {
+		entry = &cachedTopology{nodes: map[string]*cachedSelectedPods{}}
+		c.topologyPods[key] = entry
+	}
+	entry.loop = c.topologyLoop
+	selector := TopologyListOptions("", tg.rawSelector).LabelSelector
+	nodes := t.clusterNodes()
+	for _, n := range nodes {
+		selected, ok := entry.nodes[n.Name]
+		if !ok || selected.podRevision != n.podRevision {
+			pods, err := c.podsOn(ctx, t.kubeClient, n)
+			if err != nil {
+				return err
+			}
+			selected = &cachedSelectedPods{podRevision: n.podRevision, uids: lo.FilterMap(pods, func(p cachedPod, _ int) (string, bool) {
+				return p.uid, tg.namespaces.Has(p.namespace) && selector.Matches(p.labels)
+			})}
+			entry.nodes[n.Name] = selected
+		}
+		count := lo.CountBy(selected.uids, func(uid string) bool { return !t.excludedPods.Has(uid) })
+		if count == 0 {
+			continue
+		}
+		domain, ok := n.Labels[tg.Key]
+		// As in Topology.countDomains, the node name is used as the hostname if the node doesn't have the label yet
+		if !ok && tg.Key == corev1.LabelHostname {
+			domain, ok = n.Name, true
+		}
+		if !ok || !tg.nodeFilter.Matches(n.Spec.Taints, scheduling.NewLabelRequirements(n.Labels)) {
+			continue
+		}
+		for range count {
+			tg.Record(domain)
+		}
+	}
+	maps.DeleteFunc(entry.nodes, func(name string, _ *cachedSelectedPods) bool {
+		_, ok := nodes[name]
+		return !ok
+	})
+	maps.DeleteFunc(c.nodePods, func(name string, _ *cachedNodePods) bool {
+		_, ok := nodes[name]
+		return !ok
+	})
+	return nil
+}
+
+// podsOn returns the pods bound to the node that are counted for topology, which are only listed again once the
+// node's pods change
+func (c *Cache) podsOn(ctx context.Context, kubeClient client.Client, n clusterNode) ([]cachedPod, error) {
+	if entry, ok := c.nodePods[n.Name]; ok && entry.podRevision == n.podRevision {
+		return entry.pods, nil
+	}
+	pods, err := nodeutils.GetPods(ctx, kubeClient, n.Name)
+	if err != nil {
+		return nil, err
+	}
+	entry := &cachedNodePods{podRevision: n.podRevision, pods: lo.FilterMap(pods, func(p *corev1.Pod, _ int) (cachedPod, bool) {
+		return cachedPod{uid: string(p.UID), namespace: p.Namespace, labels: p.Labels}, !IgnoredForTopology(p)
+	})}
+	c.nodePods[n.Name] = entry
+	return entry.pods, nil
+}
+
+// clusterNode is a node in cluster state along with the revision of its pods
+type clusterNode struct {
+	*corev1.Node
+	podRevision uint64
+}
+
+// clusterNodes returns the nodes in cluster state. They're read once per topology so that every topology group is
+// counted against the same nodes.
+func (t *Topology) clusterNodes() map[string]clusterNode {
+	if t.nodes == nil {
+		t.nodes = map[string]clusterNode{}
+		for n := range t.cluster.Nodes() {
+			if n.Node != nil {
+				t.nodes[n.Node.Name] = clusterNode{Node: n.Node, podRevision: n.PodRevision()}
+			}
+		}
+	}
+	return t.nodes
+}
+
+func newFilteredNodeClaimTemplate(nodePool *v1.NodePool, instanceTypes []*cloudprovider.InstanceType, relaxMinValues bool) (*NodeClaimTemplate, error) {
+	var err error
+	nct := NewNodeClaimTemplate(nodePool)
+	nct.InstanceTypeOptions, _, err = filterInstanceTypesByRequirements(instanceTypes, nct.Requirements, &corev1.Pod{}, corev1.ResourceList{}, []DaemonOverheadGroup{{InstanceTypes: instanceTypes, HostPortUsage: scheduling.NewHostPortUsage()}}, corev1.ResourceList{}, relaxMinValues)
+	return nct, err
+}
+
+func versionOf(nodePool *v1.NodePool) nodePoolVersion {
+	return nodePoolVersion{uid: nodePool.UID, generation: nodePool.Generation}
+}
+
+// daemonSetKey hashes the daemon pods that are considered for scheduling, so that the data derived from them is only
+// recomputed when they change
+func daemonSetKey(ctx context.Context, daemonSetPods []*corev1.Pod) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%t", karpopts.FromContext(ctx).IgnoreDRARequests)
+	for _, p := range daemonSetPods {
+		fmt.Fprintf(h, "%s/%s", p.Namespace, p.Name)
+		// Quantities and other unexported fields are ignored by hashstructure, so the spec is hashed in its serialized form
+		spec, _ := json.Marshal(p.Spec)
+		_, _ = h.Write(spec)
+	}
+	return h.Sum64()
+}
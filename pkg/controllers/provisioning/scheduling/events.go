@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
@@ -50,6 +51,17 @@ func NominatePodEvent(pod *corev1.Pod, node *corev1.Node, nodeClaim *v1.NodeClai
 	}
 }
 
+func PodPreemptionEvent(pod *corev1.Pod, node *corev1.Node, victims []*corev1.Pod) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.Preempting,
+		Message:        fmt.Sprintf("Pod should schedule on: node/%s by preempting %d lower priority pod(s)", node.Name, len(victims)),
+		DedupeValues:   []string{string(pod.UID)},
+		RateLimiter:    PodNominationRateLimiter,
+	}
+}
+
 func NoCompatibleInstanceTypes(np *v1.NodePool, minValuesIncompatibleError bool) events.Event {
 	return events.Event{
 		InvolvedObject: np,
@@ -71,3 +83,26 @@ func PodFailedToScheduleEvent(pod *corev1.Pod, err error) events.Event {
 		DedupeTimeout:  5 * time.Minute,
 	}
 }
+
+// maxEventMessageLength is the maximum length of an event message accepted by the API server
+const maxEventMessageLength = 1024
+
+func PodSchedulingDecisionEvent(pod *corev1.Pod, trace *DecisionTrace) events.Event {
+	msg := fmt.Sprintf("Scheduling decision trace, %s", trace)
+	if len(msg) > maxEventMessageLength {
+		// Truncate on a rune boundary so that the message stays valid UTF-8
+		end := maxEventMessageLength - 3
+		for end > 0 && !utf8.RuneStart(msg[end]) {
+			end--
+		}
+		msg = msg[:end] + "..."
+	}
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.SchedulingDecision,
+		Message:        msg,
+		DedupeValues:   []string{string(pod.UID)},
+		DedupeTimeout:  5 * time.Minute,
+	}
+}
@@ -18,11 +18,14 @@ package scheduling_test
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"time"
 
 	"github.com/mitchellh/hashstructure/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -31,6 +34,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	scheduler "sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
@@ -1486,4 +1490,163 @@ var _ = Describe("Instance Type Selection", func() {
 			Expect(len(supportedInstanceTypes(cloudProvider.CreateCalls[0]))).To(BeNumerically(">=", 2))
 		})
 	})
+	Context("Instance Selection Policy", func() {
+		BeforeEach(func() {
+			instanceType := func(cpu string, price float64) *cloudprovider.InstanceType {
+				return fake.NewInstanceType(fmt.Sprintf("instance-type-%s", cpu),
+					fake.WithResources(corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse(cpu + "Gi"),
+					}),
+					fake.WithOfferings(
+						cloudprovider.Offering{
+							Available:    true,
+							Requirements: scheduler.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeSpot, corev1.LabelTopologyZone: "test-zone-1"}),
+							Price:        price,
+						},
+					),
+				)
+			}
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{instanceType("2", 1.0), instanceType("4", 2.0), instanceType("8", 4.0)}
+			scheduling.MaxInstanceTypes = 1
+		})
+		It("should launch with the cheapest instance types without a policy", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Labels[corev1.LabelInstanceTypeStable]).To(Equal("instance-type-2"))
+		})
+		It("should launch with the instance types that the policy ranks first", func() {
+			nodePool.Spec.InstanceSelection = &v1.InstanceSelection{Objectives: []v1.InstanceSelectionObjective{
+				{Type: v1.InstanceSelectionObjectivePrice, Weight: 1},
+				{Type: v1.InstanceSelectionObjectiveNodeCount, Weight: 2},
+			}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Labels[corev1.LabelInstanceTypeStable]).To(Equal("instance-type-8"))
+		})
+		It("should keep the instance types that the policy ranks first when truncating results", func() {
+			nodePool.Spec.InstanceSelection = &v1.InstanceSelection{Objectives: []v1.InstanceSelectionObjective{
+				{Type: v1.InstanceSelectionObjectiveNodeCount, Weight: 1},
+			}}
+			scheduling.MaxInstanceTypes = 2
+			ExpectApplied(ctx, env.Client, nodePool, test.UnschedulablePod())
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.NewNodeClaims).To(HaveLen(1))
+			Expect(lo.Map(results.NewNodeClaims[0].InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) string { return it.Name })).To(Equal([]string{"instance-type-8", "instance-type-4"}))
+		})
+	})
+	Context("BestFit Solver", func() {
+		var pods []*corev1.Pod
+		BeforeEach(func() {
+			instanceType := func(cpu string, price float64) *cloudprovider.InstanceType {
+				return fake.NewInstanceType(fmt.Sprintf("instance-type-%s", cpu),
+					fake.WithResources(corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse(cpu + "Gi"),
+					}),
+					fake.WithOfferings(
+						cloudprovider.Offering{
+							Available:    true,
+							Requirements: scheduler.NewLabelRequirements(map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeSpot, corev1.LabelTopologyZone: "test-zone-1"}),
+							Price:        price,
+						},
+					),
+				)
+			}
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{instanceType("1", 0.52), instanceType("4", 3.0)}
+			// Each pod fits on its own on instance-type-1, while all of them fit together on instance-type-4
+			pods = test.UnschedulablePods(test.PodOptions{
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("0.9"),
+					corev1.ResourceMemory: resource.MustParse("0.9Gi")},
+				},
+			}, 4)
+			ExpectApplied(ctx, env.Client, nodePool)
+		})
+		AfterEach(func() {
+			ctx = options.ToContext(ctx, test.Options())
+		})
+		It("should pack all pods onto the first instance type that fits them with the greedy solver", func() {
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(ExpectScheduled(ctx, env.Client, pods[0]).Labels[corev1.LabelInstanceTypeStable]).To(Equal("instance-type-4"))
+		})
+		It("should repack pods onto cheaper nodeclaims with the best fit solver", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				SchedulingSolver:       new(options.SchedulingSolverBestFit),
+				SchedulingSolverBudget: new(time.Minute),
+			}))
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(4))
+			nodeNames := sets.New[string]()
+			for _, p := range pods {
+				node := ExpectScheduled(ctx, env.Client, p)
+				Expect(node.Labels[corev1.LabelInstanceTypeStable]).To(Equal("instance-type-1"))
+				nodeNames.Insert(node.Name)
+			}
+			Expect(nodeNames).To(HaveLen(4))
+		})
+		It("should keep the greedy result with the best fit solver when it is cheaper", func() {
+			cloudProvider.InstanceTypes[1].Offerings[0].Price = 1.0
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				SchedulingSolver:       new(options.SchedulingSolverBestFit),
+				SchedulingSolverBudget: new(time.Minute),
+			}))
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(ExpectScheduled(ctx, env.Client, pods[0]).Labels[corev1.LabelInstanceTypeStable]).To(Equal("instance-type-4"))
+		})
+		It("should repack pods onto available reserved offerings with the best fit solver", func() {
+			reserved := cloudProvider.InstanceTypes[0]
+			reserved.Requirements.Get(v1.CapacityTypeLabelKey).Insert(v1.CapacityTypeReserved)
+			reserved.Offerings = append(reserved.Offerings, &cloudprovider.Offering{
+				ReservationCapacity: 4,
+				Available:           true,
+				Requirements: scheduler.NewLabelRequirements(map[string]string{
+					v1.CapacityTypeLabelKey:          v1.CapacityTypeReserved,
+					corev1.LabelTopologyZone:         "test-zone-1",
+					cloudprovider.ReservationIDLabel: "r-instance-type-1",
+				}),
+				Price: 0.1,
+			})
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				SchedulingSolver:       new(options.SchedulingSolverBestFit),
+				SchedulingSolverBudget: new(time.Minute),
+				FeatureGates:           test.FeatureGates{ReservedCapacity: new(true)},
+			}))
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(4))
+			for _, p := range pods {
+				node := ExpectScheduled(ctx, env.Client, p)
+				Expect(node.Labels).To(HaveKeyWithValue(v1.CapacityTypeLabelKey, v1.CapacityTypeReserved))
+				Expect(node.Labels).To(HaveKeyWithValue(cloudprovider.ReservationIDLabel, "r-instance-type-1"))
+			}
+		})
+		It("should not fail to repack pods when none of the offerings have a usable price", func() {
+			for _, it := range cloudProvider.InstanceTypes {
+				it.Offerings[0].Price = math.MaxFloat64
+			}
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				SchedulingSolver:       new(options.SchedulingSolverBestFit),
+				SchedulingSolverBudget: new(time.Minute),
+			}))
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			for _, p := range pods {
+				ExpectScheduled(ctx, env.Client, p)
+			}
+		})
+		It("should fall back to the greedy result when the best fit solver exceeds its budget", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+				SchedulingSolver:       new(options.SchedulingSolverBestFit),
+				SchedulingSolverBudget: new(time.Duration(0)),
+			}))
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(ExpectScheduled(ctx, env.Client, pods[0]).Labels[corev1.LabelInstanceTypeStable]).To(Equal("instance-type-4"))
+		})
+	})
 })
@@ -312,8 +312,11 @@ func (n *NodeClaim) offeringsToReserve(
 	hasCompatibleOffering := false
 	var reservedOfferings cloudprovider.Offerings
 	for _, it := range instanceTypes {
+		var reservable cloudprovider.Offerings
 		for _, o := range it.Offerings {
-			if o.CapacityType() != v1.CapacityTypeReserved || !o.Available {
+			// Reservations that are about to end are treated as unavailable, since a node launched into one would be replaced
+			// shortly after it launched
+			if o.CapacityType() != v1.CapacityTypeReserved || !o.Available || n.reservationManager.IsExpiring(o) {
 				continue
 			}
 			// Track every incompatible reserved offering for release. Since releasing a reservation is a no-op when there is no
@@ -326,9 +329,12 @@ func (n *NodeClaim) offeringsToReserve(
 			// this host, this operation is guaranteed to succeed. We may also succeed to make reservations for offerings which
 			// failed in previous iterations if other NodeClaims have released them since the last attempt.
 			if n.reservationManager.CanReserve(n.hostname, o) {
-				reservedOfferings = append(reservedOfferings, o)
+				reservable = append(reservable, o)
 			}
 		}
+		// Only reserve the instance type's reservations that end first. The NodeClaim is launched into any of its reserved
+		// offerings, so limiting them is what makes the launch prefer the capacity that would otherwise be lost.
+		reservedOfferings = append(reservedOfferings, n.reservationManager.Earliest(reservable)...)
 	}
 
 	if n.reservedOfferingMode == ReservedOfferingModeStrict {
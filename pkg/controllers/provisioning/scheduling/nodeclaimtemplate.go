@@ -59,6 +59,7 @@ type NodeClaimTemplate struct {
 	NodePoolUUID        types.UID
 	NodePoolWeight      int32
 	InstanceTypeOptions cloudprovider.InstanceTypes
+	InstanceSelection   *v1.InstanceSelection
 	Requirements        scheduling.Requirements
 	IsStaticNodeClaim   bool
 }
@@ -69,6 +70,7 @@ func NewNodeClaimTemplate(nodePool *v1.NodePool) *NodeClaimTemplate {
 		NodePoolName:      nodePool.Name,
 		NodePoolUUID:      nodePool.UID,
 		NodePoolWeight:    lo.FromPtr(nodePool.Spec.Weight),
+		InstanceSelection: nodePool.Spec.InstanceSelection,
 		Requirements:      scheduling.NewRequirements(),
 		IsStaticNodeClaim: nodePool.Spec.Replicas != nil,
 	}
@@ -110,8 +112,8 @@ func (i *NodeClaimTemplate) ToNodeClaim() *v1.NodeClaim {
 	// Inject instanceType requirements for NodeClaims belonging to dynamic NodePool
 	// For static we let cloudprovider.Create()
 	if !i.IsStaticNodeClaim {
-		// Order the instance types by price and only take up to MaxInstanceTypes of them to decrease the instance type size in the requirements
-		instanceTypes := lo.Slice(i.InstanceTypeOptions.OrderByPrice(i.Requirements), 0, MaxInstanceTypes)
+		// Order the instance types by the NodePool's instance selection policy and only take up to MaxInstanceTypes of them to decrease the instance type size in the requirements
+		instanceTypes := lo.Slice(i.InstanceTypeOptions.OrderBySelection(i.Requirements, i.InstanceSelection), 0, MaxInstanceTypes)
 		i.Requirements.Add(scheduling.NewRequirementWithFlexibility(corev1.LabelInstanceTypeStable, corev1.NodeSelectorOpIn, i.Requirements.Get(corev1.LabelInstanceTypeStable).MinValues, lo.Map(instanceTypes, func(i *cloudprovider.InstanceType, _ int) string {
 			return i.Name
 		})...))
@@ -0,0 +1,160 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+// PodGroupError is returned for the members of a pod group that could have scheduled, but weren't scheduled since other
+// members of the group couldn't, or since fewer members than the group's size are pending
+type PodGroupError struct {
+	Group   types.NamespacedName
+	Failed  int
+	Total   int
+	Missing int
+}
+
+func (e PodGroupError) Error() string {
+	if e.Missing > 0 {
+		return fmt.Sprintf("waiting for %d more pods in pod group %q, %d/%d pending pods failed to schedule", e.Missing, e.Group.String(), e.Failed, e.Total)
+	}
+	return fmt.Sprintf("%d/%d pods in pod group %q failed to schedule", e.Failed, e.Total, e.Group.String())
+}
+
+func IsPodGroupError(err error) bool {
+	_, ok := lo.ErrorsAs[PodGroupError](err)
+	return ok
+}
+
+// incompletePodGroups returns the members of pod groups that didn't completely schedule, so that no capacity is
+// launched for part of a group. A partially scheduled group, such as the workers of a distributed training job, can't
+// make progress and would hold its capacity until the rest of the group schedules. Pending groups with a known size are
+// also incomplete while fewer members than their size are pending, since the rest of the group may arrive in a later
+// batch. The members that could have scheduled are given a PodGroupError, while the members that failed keep their
+// scheduling error.
+func (s *Scheduler) incompletePodGroups(ctx context.Context, pods []*corev1.Pod, podErrors map[*corev1.Pod]error) []*corev1.Pod {
+	// Pending members are grouped separately from members that are being rescheduled from nodes that are being
+	// disrupted, so that a disruption simulation neither splits the running members of a group nor is blocked by
+	// pending members that can't schedule regardless of the disruption
+	type groupKey struct {
+		types.NamespacedName
+		pending bool
+	}
+	groups := map[groupKey][]*corev1.Pod{}
+	for _, p := range pods {
+		if group, ok := podutils.PodGroup(p); ok {
+			key := groupKey{NamespacedName: group, pending: podutils.IsProvisionable(p)}
+			groups[key] = append(groups[key], p)
+		}
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	// Members that failed or weren't attempted before the scheduling timeout aren't on any NodeClaim or existing node
+	scheduled := sets.New[types.UID]()
+	for _, n := range s.newNodeClaims {
+		scheduled.Insert(lo.Map(n.Pods, func(p *corev1.Pod, _ int) types.UID { return p.UID })...)
+	}
+	for _, n := range s.existingNodes {
+		scheduled.Insert(lo.Map(n.Pods, func(p *corev1.Pod, _ int) types.UID { return p.UID })...)
+	}
+	var incomplete []*corev1.Pod
+	for key, members := range groups {
+		failed := lo.CountBy(members, func(p *corev1.Pod) bool { return !scheduled.Has(p.UID) })
+		var missing int
+		if size, ok := s.podGroupSizes[key.NamespacedName]; ok && key.pending {
+			missing = max(size-len(members), 0)
+		}
+		if failed == 0 && missing == 0 {
+			continue
+		}
+		log.FromContext(ctx).V(1).WithValues("pod-group", klog.KRef(key.Namespace, key.Name), "failed", failed, "missing", missing, "pods", len(members)).Info("pod group failed to schedule")
+		for _, p := range members {
+			if scheduled.Has(p.UID) {
+				podErrors[p] = PodGroupError{Group: key.NamespacedName, Failed: failed, Total: len(members), Missing: missing}
+				s.decisionTrace(p).decide("")
+			}
+		}
+		incomplete = append(incomplete, members...)
+	}
+	return incomplete
+}
+
+// solveWithout solves the pods again on a new scheduler without the members of incomplete pod groups. Removing the
+// members from the NodeClaims that they were scheduled to isn't enough, since the NodeClaims' requests, requirements,
+// instance types, and topology counts would still include them. The members keep their errors from this scheduler.
+func (s *Scheduler) solveWithout(ctx context.Context, pods []*corev1.Pod, incomplete []*corev1.Pod, podErrors map[*corev1.Pod]error) (Results, error) {
+	excluded := sets.New(lo.Map(incomplete, func(p *corev1.Pod, _ int) types.UID { return p.UID })...)
+	pods = lo.Reject(pods, func(p *corev1.Pod, _ int) bool { return excluded.Has(p.UID) })
+	rebuilt, err := s.rebuild(ctx, pods)
+	if err != nil {
+		return Results{}, fmt.Errorf("rebuilding scheduler without incomplete pod groups, %w", err)
+	}
+	results, err := rebuilt.Solve(ctx, pods)
+	if results.PodErrors == nil {
+		results.PodErrors = map[*corev1.Pod]error{}
+	}
+	for _, p := range incomplete {
+		if podErr, ok := podErrors[p]; ok {
+			results.PodErrors[p] = podErr
+		}
+	}
+	if s.decisionTraces != nil {
+		if results.DecisionTraces == nil {
+			results.DecisionTraces = map[*corev1.Pod]*DecisionTrace{}
+		}
+		for _, p := range incomplete {
+			if trace, ok := s.decisionTraces[p.UID]; ok {
+				results.DecisionTraces[p] = trace
+			}
+		}
+	}
+	return results, err
+}
+
+// unschedule removes the pods from the NodeClaims and existing nodes that they were scheduled to, dropping NodeClaims
+// that are left without any pods. It's used when the scheduler can't be rebuilt to solve without the pods, and leaves
+// the requests, requirements, and topology counts that the pods contributed to their NodeClaims in place.
+func (s *Scheduler) unschedule(pods []*corev1.Pod) {
+	removed := sets.New(lo.Map(pods, func(p *corev1.Pod, _ int) types.UID { return p.UID })...)
+	isRemoved := func(p *corev1.Pod, _ int) bool { return removed.Has(p.UID) }
+	s.newNodeClaims = lo.Filter(s.newNodeClaims, func(n *NodeClaim, _ int) bool {
+		if !lo.SomeBy(n.Pods, func(p *corev1.Pod) bool { return removed.Has(p.UID) }) {
+			return true
+		}
+		n.Pods = lo.Reject(n.Pods, isRemoved)
+		if len(n.Pods) == 0 {
+			s.reservationManager.Release(n.hostname, n.reservedOfferings...)
+			return false
+		}
+		return true
+	})
+	for _, n := range s.existingNodes {
+		n.Pods = lo.Reject(n.Pods, isRemoved)
+	}
+}
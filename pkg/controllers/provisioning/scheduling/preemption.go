@@ -0,0 +1,252 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// Preemption is the placement of a pod on an existing node where the kube-scheduler can preempt lower priority pods
+// to make room for it
+type Preemption struct {
+	Node    *ExistingNode
+	Victims []*corev1.Pod
+}
+
+// preempt places the pods that couldn't be placed on existing nodes or new capacity on the existing nodes where the
+// kube-scheduler can preempt lower priority pods for them, following the kube-scheduler's preemption semantics. Unlike
+// the kube-scheduler, pods are never preempted if doing so would violate a PDB. Pods are preempted for in order of
+// priority, and the pods that are placed are removed from the pod errors. The preempted pods are returned.
+func (s *Scheduler) preempt(ctx context.Context, podErrors map[*corev1.Pod]error) []*corev1.Pod {
+	preemptors := lo.Filter(lo.Keys(podErrors), func(p *corev1.Pod, _ int) bool {
+		return canPreempt(p) && !IsReservedOfferingError(podErrors[p]) && !IsDRAError(podErrors[p])
+	})
+	sort.SliceStable(preemptors, func(i, j int) bool {
+		if podPriority(preemptors[i]) != podPriority(preemptors[j]) {
+			return podPriority(preemptors[i]) > podPriority(preemptors[j])
+		}
+		return byCPUAndMemoryDescending(preemptors, s.cachedPodData)(i, j)
+	})
+	var victims []*corev1.Pod
+	for _, p := range preemptors {
+		if ctx.Err() != nil {
+			break
+		}
+		preemption, err := s.tryPreempt(ctx, p.DeepCopy())
+		if err != nil {
+			continue
+		}
+		log.FromContext(ctx).V(1).WithValues("Pod", klog.KObj(p), "Node", klog.KObj(preemption.Node.Node), "victims", len(preemption.Victims)).Info("pod can schedule by preempting lower priority pods")
+		delete(podErrors, p)
+		s.preemptions[p] = preemption
+		victims = append(victims, preemption.Victims...)
+	}
+	return victims
+}
+
+// tryPreempt attempts to place the pod by preemption, relaxing its preferences until it can be placed
+func (s *Scheduler) tryPreempt(ctx context.Context, p *corev1.Pod) (*Preemption, error) {
+	for {
+		preemption, err := s.addByPreemption(ctx, p)
+		if err == nil {
+			return preemption, nil
+		}
+		if s.preferences.relax(ctx, p) == nil {
+			return nil, err
+		}
+		if e := s.topology.Update(ctx, p); e != nil && !errors.Is(e, context.DeadlineExceeded) {
+			log.FromContext(ctx).Error(e, "failed updating topology")
+		}
+		s.updateCachedPodData(ctx, p)
+	}
+}
+
+// addByPreemption adds the pod to the existing node where it fits by preempting the least important pods, as ordered
+// by the kube-scheduler: the lowest highest priority victim, the lowest sum of victim priorities, and the fewest victims
+func (s *Scheduler) addByPreemption(ctx context.Context, p *corev1.Pod) (*Preemption, error) {
+	podData := s.cachedPodData[p.UID]
+	if podData.HasResourceClaimRequests {
+		return nil, fmt.Errorf("pods with Dynamic Resource Allocation requirements can't be placed by preemption")
+	}
+	volumes, err := scheduling.GetVolumes(ctx, s.kubeClient, p)
+	if err != nil {
+		return nil, err
+	}
+	var best *Preemption
+	var bestRequirements scheduling.Requirements
+	for _, node := range s.existingNodes {
+		victims, requirements, err := s.selectVictims(ctx, node, p, podData, volumes)
+		// A pod that fits without preempting any pods failed to schedule for another reason
+		if err != nil || len(victims) == 0 {
+			continue
+		}
+		if best == nil || lessImportant(victims, best.Victims) {
+			best, bestRequirements = &Preemption{Node: node, Victims: victims}, requirements
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no existing node can fit the pod by preempting lower priority pods")
+	}
+	resources.MergeInto(best.Node.remainingResources, resources.RequestsForPods(best.Victims...))
+	best.Node.Add(ctx, p, podData, bestRequirements, volumes, nil)
+	s.preempted.Insert(lo.Map(best.Victims, func(v *corev1.Pod, _ int) types.UID { return v.UID })...)
+	s.preemptionPDBs.Consume(best.Victims)
+	s.decisionTrace(p).decide(fmt.Sprintf("node/%s by preempting %d pod(s)", best.Node.Name(), len(best.Victims)))
+	return best, nil
+}
+
+// selectVictims returns the fewest lower priority pods on the node that need to be preempted for the pod to fit, along
+// with the node's requirements if the pod is added to it. As in the kube-scheduler, every lower priority pod is removed
+// and then as many pods as possible are reprieved, starting with the pods whose preemption would violate a PDB and then
+// in order of priority. Only resources are freed by preemption, the node must satisfy the pod's other constraints with
+// the victims in place.
+func (s *Scheduler) selectVictims(ctx context.Context, node *ExistingNode, p *corev1.Pod, podData *PodData, volumes scheduling.Volumes) ([]*corev1.Pod, scheduling.Requirements, error) {
+	pods, err := s.existingNodePods(ctx, node)
+	if err != nil {
+		return nil, nil, err
+	}
+	candidates := lo.Filter(pods, func(candidate *corev1.Pod, _ int) bool {
+		return podPriority(candidate) < podPriority(p) && podutils.IsActive(candidate) && !podutils.IsOwnedByNode(candidate) &&
+			!podutils.IsOwnedByDaemonSet(candidate) && !s.preempted.Has(candidate.UID)
+	})
+	available := resources.Merge(node.remainingResources, resources.RequestsForPods(candidates...))
+	if len(candidates) == 0 || !resources.Fits(podData.Requests, available) {
+		return nil, nil, fmt.Errorf("exceeds node resources")
+	}
+	violating := lo.Filter(candidates, func(candidate *corev1.Pod, _ int) bool {
+		_, ok := s.preemptionPDBs.CanPreemptPods([]*corev1.Pod{candidate})
+		return !ok
+	})
+	nonViolating, _ := lo.Difference(candidates, violating)
+	var victims []*corev1.Pod
+	for _, group := range [][]*corev1.Pod{violating, nonViolating} {
+		sort.SliceStable(group, func(i, j int) bool { return podPriority(group[i]) > podPriority(group[j]) })
+		for _, candidate := range group {
+			if remaining := resources.Subtract(available, resources.RequestsForPods(candidate)); resources.Fits(podData.Requests, remaining) {
+				available = remaining
+				continue
+			}
+			victims = append(victims, candidate)
+		}
+	}
+	if pdbs, ok := s.preemptionPDBs.CanPreemptPods(victims); !ok {
+		return nil, nil, fmt.Errorf("preempting pods would violate pdbs %v", pdbs)
+	}
+	remainingResources := node.remainingResources
+	node.remainingResources = available
+	defer func() { node.remainingResources = remainingResources }()
+	requirements, _, err := node.CanAdd(ctx, p, podData, volumes, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return victims, requirements, nil
+}
+
+// existingNodePods returns the pods that are bound to the existing node, listing them once per scheduling loop
+func (s *Scheduler) existingNodePods(ctx context.Context, node *ExistingNode) ([]*corev1.Pod, error) {
+	if pods, ok := s.cachedNodePods[node.Name()]; ok {
+		return pods, nil
+	}
+	pods, err := node.StateNode.Pods(ctx, s.kubeClient)
+	if err != nil {
+		return nil, fmt.Errorf("listing pods on node, %w", err)
+	}
+	s.cachedNodePods[node.Name()] = pods
+	return pods, nil
+}
+
+// schedulePreempted schedules the preempted pods so that capacity is provisioned for them. Preempted pods that can't
+// be scheduled are left to a future scheduling loop once they're pending, so their errors aren't recorded.
+func (s *Scheduler) schedulePreempted(ctx context.Context, victims []*corev1.Pod) {
+	volumeTopology := NewVolumeTopology(s.kubeClient)
+	if s.volumeReqsByPod == nil {
+		s.volumeReqsByPod = map[types.UID][]scheduling.Requirements{}
+	}
+	victims = lo.Filter(victims, func(p *corev1.Pod, _ int) bool {
+		reqs, err := volumeTopology.GetRequirements(ctx, p)
+		if err != nil {
+			log.FromContext(ctx).WithValues("Pod", klog.KObj(p)).V(1).Info("skipping preempted pod, failed getting volume topology requirements", "error", err)
+			return false
+		}
+		if len(reqs) > 0 {
+			s.volumeReqsByPod[p.UID] = reqs
+		}
+		s.updateCachedPodData(ctx, p)
+		return true
+	})
+	q := NewQueue(victims, s.cachedPodData)
+	for {
+		p, ok := q.Pop()
+		if !ok {
+			break
+		}
+		if err := s.trySchedule(ctx, p.DeepCopy()); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			if e := s.topology.Update(ctx, p); e != nil && !errors.Is(e, context.DeadlineExceeded) {
+				log.FromContext(ctx).Error(e, "failed updating topology")
+			}
+			s.updateCachedPodData(ctx, p)
+			q.Push(p)
+		}
+	}
+}
+
+// canPreempt returns true if the pod is pending and may preempt other pods. Members of pod groups don't preempt since
+// the group can only run once every member is placed, and virtual pods don't preempt real ones.
+func canPreempt(p *corev1.Pod) bool {
+	if _, ok := podutils.PodGroup(p); ok {
+		return false
+	}
+	return podutils.IsProvisionable(p) && !isVirtualBufferPod(p) &&
+		lo.FromPtr(p.Spec.PreemptionPolicy) != corev1.PreemptNever
+}
+
+func podPriority(p *corev1.Pod) int32 {
+	return lo.FromPtr(p.Spec.Priority)
+}
+
+// lessImportant returns true if the lhs victims are less important than the rhs victims
+func lessImportant(lhs, rhs []*corev1.Pod) bool {
+	maxPriority := func(pods []*corev1.Pod) int32 {
+		return lo.Max(lo.Map(pods, func(p *corev1.Pod, _ int) int32 { return podPriority(p) }))
+	}
+	sumPriority := func(pods []*corev1.Pod) int64 {
+		return lo.SumBy(pods, func(p *corev1.Pod) int64 { return int64(podPriority(p)) })
+	}
+	if maxPriority(lhs) != maxPriority(rhs) {
+		return maxPriority(lhs) < maxPriority(rhs)
+	}
+	if sumPriority(lhs) != sumPriority(rhs) {
+		return sumPriority(lhs) < sumPriority(rhs)
+	}
+	return len(lhs) < len(rhs)
+}
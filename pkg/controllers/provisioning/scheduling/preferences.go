@@ -36,6 +36,12 @@ type Preferences struct {
 }
 
 func (p *Preferences) Relax(ctx context.Context, pod *v1.Pod) bool {
+	return p.relax(ctx, pod) != nil
+}
+
+// relax relaxes a single soft constraint for the pod, returning a description of the relaxation or nil if there was
+// nothing left to relax
+func (p *Preferences) relax(ctx context.Context, pod *v1.Pod) *string {
 	relaxations := []func(*v1.Pod) *string{
 		p.removeRequiredNodeAffinityTerm,
 		p.removePreferredPodAffinityTerm,
@@ -50,10 +56,10 @@ func (p *Preferences) Relax(ctx context.Context, pod *v1.Pod) bool {
 	for _, relaxFunc := range relaxations {
 		if reason := relaxFunc(pod); reason != nil {
 			log.FromContext(ctx).WithValues("Pod", klog.KObj(pod)).V(1).Info("relaxing soft constraints for pod since it previously failed to schedule", "reason", lo.FromPtr(reason))
-			return true
+			return reason
 		}
 	}
-	return false
+	return nil
 }
 
 func (p *Preferences) removePreferredNodeAffinityTerm(pod *v1.Pod) *string {
@@ -18,7 +18,9 @@ package scheduling
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/samber/lo"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
@@ -28,10 +30,17 @@ import (
 type ReservationManager struct {
 	reservations map[string]sets.Set[string] // hostname -> set[reservation id]
 	capacity     map[string]int              // reservation id -> count
+	endTimes     map[string]time.Time        // reservation id -> end time
+	// expiresBefore is the time before which reservations are considered to be expiring. Expiring reservations aren't
+	// reserved, since nodes launched into them would be replaced shortly after launch.
+	expiresBefore time.Time
 }
 
-func NewReservationManager(instanceTypes map[string][]*cloudprovider.InstanceType) *ReservationManager {
+// NewReservationManager constructs a ReservationManager for the reserved offerings of the instance types. Reservations
+// that end before expiresBefore are treated as expiring.
+func NewReservationManager(instanceTypes map[string][]*cloudprovider.InstanceType, expiresBefore time.Time) *ReservationManager {
 	capacity := map[string]int{}
+	endTimes := map[string]time.Time{}
 	for _, its := range instanceTypes {
 		for _, it := range its {
 			for _, o := range it.Offerings {
@@ -44,12 +53,20 @@ func NewReservationManager(instanceTypes map[string][]*cloudprovider.InstanceTyp
 				if current, ok := capacity[o.ReservationID()]; !ok || current > o.ReservationCapacity {
 					capacity[o.ReservationID()] = o.ReservationCapacity
 				}
+				// Similarly, track the earliest end time for the reservation
+				if !o.ReservationEndTime.IsZero() {
+					if current, ok := endTimes[o.ReservationID()]; !ok || o.ReservationEndTime.Time.Before(current) {
+						endTimes[o.ReservationID()] = o.ReservationEndTime.Time
+					}
+				}
 			}
 		}
 	}
 	return &ReservationManager{
-		reservations: map[string]sets.Set[string]{},
-		capacity:     capacity,
+		reservations:  map[string]sets.Set[string]{},
+		capacity:      capacity,
+		endTimes:      endTimes,
+		expiresBefore: expiresBefore,
 	}
 }
 
@@ -108,3 +125,28 @@ func (rm *ReservationManager) HasReservation(hostname string, offering *cloudpro
 func (rm *ReservationManager) RemainingCapacity(offering *cloudprovider.Offering) int {
 	return rm.capacity[offering.ReservationID()]
 }
+
+// IsExpiring returns true if the offering's reservation ends before the reservation manager's expiry cutoff
+func (rm *ReservationManager) IsExpiring(offering *cloudprovider.Offering) bool {
+	endTime, ok := rm.endTimes[offering.ReservationID()]
+	return ok && endTime.Before(rm.expiresBefore)
+}
+
+// Earliest returns the offerings whose reservations end first. Capacity in a reservation that is about to end is lost if
+// it isn't used, so it's preferred over capacity in reservations that end later or don't end at all. Offerings are only
+// dropped in favor of a reservation with an end time, so all offerings are returned if none of their reservations end.
+func (rm *ReservationManager) Earliest(offerings cloudprovider.Offerings) cloudprovider.Offerings {
+	var earliest time.Time
+	for _, o := range offerings {
+		if end, ok := rm.endTimes[o.ReservationID()]; ok && (earliest.IsZero() || end.Before(earliest)) {
+			earliest = end
+		}
+	}
+	if earliest.IsZero() {
+		return offerings
+	}
+	return lo.Filter(offerings, func(o *cloudprovider.Offering, _ int) bool {
+		end, ok := rm.endTimes[o.ReservationID()]
+		return ok && end.Equal(earliest)
+	})
+}
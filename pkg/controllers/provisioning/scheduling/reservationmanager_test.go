@@ -18,11 +18,13 @@ package scheduling_test
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
@@ -101,7 +103,7 @@ var _ = Describe("ReservationManager", func() {
 			offerings[it.Name] = it.Offerings
 		}
 
-		rm = scheduling.NewReservationManager(map[string][]*cloudprovider.InstanceType{"": instanceTypes})
+		rm = scheduling.NewReservationManager(map[string][]*cloudprovider.InstanceType{"": instanceTypes}, time.Time{})
 	})
 
 	Describe("CanReserve", func() {
@@ -365,4 +367,27 @@ var _ = Describe("ReservationManager", func() {
 			Expect(rm.RemainingCapacity(twoCapacityOffering)).To(Equal(1)) // Should have 1 available (1 total, 0 used after release)
 		})
 	})
+
+	Describe("Reservation End Times", func() {
+		var now time.Time
+		BeforeEach(func() {
+			now = time.Now()
+			instanceTypes[0].Offerings[0].ReservationEndTime = metav1.NewTime(now.Add(30 * time.Minute))
+			instanceTypes[1].Offerings[0].ReservationEndTime = metav1.NewTime(now.Add(2 * time.Hour))
+			rm = scheduling.NewReservationManager(map[string][]*cloudprovider.InstanceType{"": instanceTypes}, now.Add(time.Hour))
+		})
+		It("should treat reservations that end before the cutoff as expiring", func() {
+			Expect(rm.IsExpiring(threeCapacityOffering)).To(BeTrue())
+			Expect(rm.IsExpiring(twoCapacityOffering)).To(BeFalse())
+			Expect(rm.IsExpiring(oneCapacityOffering)).To(BeFalse())
+		})
+		It("should return the offerings whose reservations end first", func() {
+			Expect(rm.Earliest(cloudprovider.Offerings{oneCapacityOffering, twoCapacityOffering, threeCapacityOffering})).To(Equal(cloudprovider.Offerings{threeCapacityOffering}))
+			Expect(rm.Earliest(cloudprovider.Offerings{oneCapacityOffering, twoCapacityOffering})).To(Equal(cloudprovider.Offerings{twoCapacityOffering}))
+		})
+		It("should return all offerings when none of their reservations end", func() {
+			Expect(rm.Earliest(cloudprovider.Offerings{oneCapacityOffering})).To(Equal(cloudprovider.Offerings{oneCapacityOffering}))
+			Expect(rm.Earliest(nil)).To(BeEmpty())
+		})
+	})
 })
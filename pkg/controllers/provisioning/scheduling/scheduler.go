@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"sort"
 	"strings"
@@ -52,6 +53,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/scheduling/dynamicresources"
 	"sigs.k8s.io/karpenter/pkg/utils/disruption"
+	"sigs.k8s.io/karpenter/pkg/utils/pdb"
 	"sigs.k8s.io/karpenter/pkg/utils/pod"
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
@@ -90,12 +92,36 @@ const (
 	PreferencePolicyIgnore
 )
 
+type SolverMode int
+
+const (
+	// SolverModeGreedy indicates to the scheduler that each pod should be placed on the first NodeClaim that can fit it,
+	// in the order that pods are popped from the queue.
+	SolverModeGreedy SolverMode = iota
+	// SolverModeBestFit indicates to the scheduler that, after the greedy pass, it should repack the pods on new
+	// NodeClaims with a best-fit decreasing heuristic to lower the launch price of the batch. Reserved offerings that can
+	// still be reserved are priced at the reservation's price. This isn't a global optimization: the result isn't
+	// guaranteed to be the cheapest packing, and NodePools whose new NodeClaims have pods
+	// with topology constraints, DRA resource claims, or relaxed minValues keep the greedy result. The repacked result
+	// is only used if it's cheaper than the greedy result and was computed within the solver budget.
+	SolverModeBestFit
+)
+
 type options struct {
 	reservedOfferingMode    ReservedOfferingMode
 	preferencePolicy        PreferencePolicy
 	minValuesPolicy         karpopts.MinValuesPolicy
 	numConcurrentReconciles int
 	enforceConsolidateAfter bool
+	solverMode              SolverMode
+	solverBudget            time.Duration
+	recordDecisionTraces    bool
+	simulatePreemption      bool
+	preemptionPDBs          pdb.Limits
+	provisionPreempted      bool
+	cache                   *Cache
+	rebuild                 RebuildFunc
+	podGroupSizes           map[types.NamespacedName]int
 }
 
 type Options = option.Function[options]
@@ -124,6 +150,57 @@ var IsConsolidationSimulation = func(opts *options) {
 	opts.enforceConsolidateAfter = true
 }
 
+var RecordDecisionTraces = func(opts *options) {
+	opts.recordDecisionTraces = true
+}
+
+// SimulatePreemption places pods that can't be placed on existing nodes or new capacity on the existing nodes where the
+// kube-scheduler can preempt lower priority pods for them without violating the PDBs. If provisionPreempted is set, the
+// preempted pods are scheduled as well so that capacity is provisioned for them.
+var SimulatePreemption = func(pdbs pdb.Limits, provisionPreempted bool) func(*options) {
+	return func(opts *options) {
+		opts.simulatePreemption = true
+		opts.preemptionPDBs = pdbs
+		opts.provisionPreempted = provisionPreempted
+	}
+}
+
+// WithCache persists the templates, daemon overhead and topology counts of the scheduler in the cache, so that they're
+// incrementally updated across scheduling loops instead of being rebuilt for every loop. The same cache must be passed
+// to NewTopology.
+var WithCache = func(cache *Cache) func(*options) {
+	return func(opts *options) {
+		opts.cache = cache
+	}
+}
+
+// RebuildFunc builds a new scheduler, with the same view of the cluster, for a subset of the pods
+type RebuildFunc func(context.Context, []*corev1.Pod) (*Scheduler, error)
+
+// WithRebuild sets the function that the scheduler uses to solve the pods again without the members of pod groups that
+// didn't completely schedule. Without it, the members are only removed from the NodeClaims and nodes that they were
+// scheduled to.
+var WithRebuild = func(rebuild RebuildFunc) func(*options) {
+	return func(opts *options) {
+		opts.rebuild = rebuild
+	}
+}
+
+// PodGroupSizes sets the number of pending members that each pod group needs before capacity is launched for it. Pod
+// groups that aren't in the map need every pending member in the batch to schedule.
+var PodGroupSizes = func(sizes map[types.NamespacedName]int) func(*options) {
+	return func(opts *options) {
+		opts.podGroupSizes = sizes
+	}
+}
+
+var BestFitBinPacking = func(budget time.Duration) func(*options) {
+	return func(opts *options) {
+		opts.solverMode = SolverModeBestFit
+		opts.solverBudget = budget
+	}
+}
+
 func NewScheduler(
 	ctx context.Context,
 	kubeClient client.Client,
@@ -140,6 +217,7 @@ func NewScheduler(
 	opts ...Options,
 ) *Scheduler {
 	minValuesPolicy := option.Resolve(opts...).minValuesPolicy
+	cache := option.Resolve(opts...).cache
 
 	// if any of the nodePools add a taint with a prefer no schedule effect, we add a toleration for the taint
 	// during preference relaxation
@@ -153,29 +231,27 @@ func NewScheduler(
 	}
 	// Pre-filter instance types eligible for NodePools to reduce work done during scheduling loops for pods
 	// if no templates remain, we still want to build the scheduler so that Karpenter can ack pods which can schedule to existing and in-flight capacity
-	templates := lo.FilterMap(nodePools, func(np *v1.NodePool, _ int) (*NodeClaimTemplate, bool) {
-		var err error
-		nct := NewNodeClaimTemplate(np)
-		nct.InstanceTypeOptions, _, err = filterInstanceTypesByRequirements(instanceTypes[np.Name], nct.Requirements, &corev1.Pod{}, corev1.ResourceList{}, []DaemonOverheadGroup{{InstanceTypes: instanceTypes[np.Name], HostPortUsage: scheduling.NewHostPortUsage()}}, corev1.ResourceList{}, minValuesPolicy == karpopts.MinValuesPolicyBestEffort)
-		if len(nct.InstanceTypeOptions) == 0 {
-			if instanceTypeFilterErr, ok := lo.ErrorsAs[InstanceTypeFilterError](err); ok && instanceTypeFilterErr.minValuesIncompatibleErr != nil {
-				recorder.Publish(NoCompatibleInstanceTypes(np, true))
-				log.FromContext(ctx).WithValues("NodePool", klog.KObj(np)).Info("skipping, nodepool requirements filtered out all instance types", "minValuesIncompatibleErr", instanceTypeFilterErr.minValuesIncompatibleErr)
-			} else {
-				recorder.Publish(NoCompatibleInstanceTypes(np, false))
-				log.FromContext(ctx).WithValues("NodePool", klog.KObj(np)).Info("skipping, nodepool requirements filtered out all instance types")
-			}
-			return nil, false
+	templates, templateErrs := cache.nodeClaimTemplates(nodePools, instanceTypes, minValuesPolicy == karpopts.MinValuesPolicyBestEffort)
+	for _, np := range nodePools {
+		err, ok := templateErrs[np]
+		if !ok {
+			continue
 		}
-		return nct, true
-	})
+		if instanceTypeFilterErr, ok := lo.ErrorsAs[InstanceTypeFilterError](err); ok && instanceTypeFilterErr.minValuesIncompatibleErr != nil {
+			recorder.Publish(NoCompatibleInstanceTypes(np, true))
+			log.FromContext(ctx).WithValues("NodePool", klog.KObj(np)).Info("skipping, nodepool requirements filtered out all instance types", "minValuesIncompatibleErr", instanceTypeFilterErr.minValuesIncompatibleErr)
+		} else {
+			recorder.Publish(NoCompatibleInstanceTypes(np, false))
+			log.FromContext(ctx).WithValues("NodePool", klog.KObj(np)).Info("skipping, nodepool requirements filtered out all instance types")
+		}
+	}
 	s := &Scheduler{
 		uuid:                 uuid.NewUUID(),
 		kubeClient:           kubeClient,
 		nodeClaimTemplates:   templates,
 		topology:             topology,
 		cluster:              cluster,
-		daemonOverheadGroups: buildDaemonOverheadGroups(ctx, templates, daemonSetPods),
+		daemonOverheadGroups: cache.daemonOverheadGroups(ctx, templates, daemonSetPods),
 		cachedPodData:        map[types.UID]*PodData{}, // cache pod data to avoid having to continually recompute it
 		volumeReqsByPod:      volumeReqsByPod,          // Volume requirements per pod
 		recorder:             recorder,
@@ -184,14 +260,31 @@ func NewScheduler(
 			return np.Name, corev1.ResourceList(np.Spec.Limits)
 		}),
 		clock:                   clock,
-		reservationManager:      NewReservationManager(instanceTypes),
+		reservationManager:      NewReservationManager(instanceTypes, clock.Now().Add(karpopts.FromContext(ctx).ReservationExpiryLeadTime)),
 		reservedOfferingMode:    option.Resolve(opts...).reservedOfferingMode,
 		preferencePolicy:        option.Resolve(opts...).preferencePolicy,
 		minValuesPolicy:         minValuesPolicy,
 		numConcurrentReconciles: lo.Ternary(option.Resolve(opts...).numConcurrentReconciles > 0, option.Resolve(opts...).numConcurrentReconciles, 1),
+		solverMode:              option.Resolve(opts...).solverMode,
+		solverBudget:            option.Resolve(opts...).solverBudget,
 		allocator:               allocator,
 		instanceTypes:           instanceTypes,
 		cachedResourceClaims:    map[types.NamespacedName]*resourcev1.ResourceClaim{},
+		cache:                   cache,
+		rebuild:                 option.Resolve(opts...).rebuild,
+		podGroupSizes:           option.Resolve(opts...).podGroupSizes,
+	}
+	if option.Resolve(opts...).recordDecisionTraces {
+		s.decisionTraces = map[types.UID]*DecisionTrace{}
+	}
+	if option.Resolve(opts...).simulatePreemption {
+		// Each scheduler consumes its own copy of the limits, since a scheduler that is rebuilt for incomplete pod groups
+		// would otherwise start from the disruptions consumed by the scheduler it replaces
+		s.preemptionPDBs = option.Resolve(opts...).preemptionPDBs.DeepCopy()
+		s.provisionPreempted = option.Resolve(opts...).provisionPreempted
+		s.preemptions = map[*corev1.Pod]*Preemption{}
+		s.preempted = sets.New[types.UID]()
+		s.cachedNodePods = map[string][]*corev1.Pod{}
 	}
 
 	npByName := lo.SliceToMap(nodePools, func(np *v1.NodePool) (string, *v1.NodePool) {
@@ -248,6 +341,8 @@ type Scheduler struct {
 	preferencePolicy        PreferencePolicy
 	minValuesPolicy         karpopts.MinValuesPolicy
 	numConcurrentReconciles int
+	solverMode              SolverMode
+	solverBudget            time.Duration
 	deletingNodeNames       sets.Set[string]
 
 	// allocator simulates DRA device allocation for pods with ResourceClaims. It is nil when DRA support is disabled.
@@ -256,6 +351,23 @@ type Scheduler struct {
 	instanceTypes map[string][]*cloudprovider.InstanceType
 	// cachedResourceClaims memoizes ResourceClaim lookups for the duration of a single scheduling loop.
 	cachedResourceClaims map[types.NamespacedName]*resourcev1.ResourceClaim
+	// cache persists the data that is expensive to compute across scheduling loops. It is nil unless the scheduler is
+	// incrementally updated.
+	cache *Cache
+	// rebuild builds a new scheduler to solve the pods again without the members of incomplete pod groups. It is nil
+	// unless the scheduler was built with WithRebuild.
+	rebuild RebuildFunc
+	// podGroupSizes are the number of pending members that each pod group needs before capacity is launched for it
+	podGroupSizes map[types.NamespacedName]int
+	// decisionTraces records the scheduling decisions per pod. It is nil when decision tracing is disabled.
+	decisionTraces map[types.UID]*DecisionTrace
+	// preemptions are the pods that were placed by preempting lower priority pods. It is nil when preemption isn't
+	// simulated. The PDBs' allowed disruptions are consumed by the preempted pods as the simulation progresses.
+	preemptions        map[*corev1.Pod]*Preemption
+	preempted          sets.Set[types.UID]
+	preemptionPDBs     pdb.Limits
+	provisionPreempted bool
+	cachedNodePods     map[string][]*corev1.Pod
 }
 
 // DRAError indicates a pod will not be attempted to be scheduled because it has Dynamic Resource Allocation requirements
@@ -283,6 +395,11 @@ type Results struct {
 	ExistingNodes              []*ExistingNode
 	PodErrors                  map[*corev1.Pod]error
 	DRAClaimAllocationMetadata map[types.NamespacedName]*dynamicresources.ResourceClaimAllocationMetadata
+	// DecisionTraces are the scheduling decisions per pod, populated when the scheduler records decision traces
+	DecisionTraces map[*corev1.Pod]*DecisionTrace
+	// Preemptions are the pods that will schedule to existing nodes by preempting lower priority pods, populated when the
+	// scheduler simulates preemption. These pods are also included in the pods of their ExistingNode.
+	Preemptions map[*corev1.Pod]*Preemption
 }
 
 // Record sends eventing and log messages back for the results that were produced from a scheduling run
@@ -302,12 +419,24 @@ func (r Results) Record(ctx context.Context, recorder events.Recorder, cluster *
 		log.FromContext(ctx).WithValues("Pod", klog.KObj(p)).Error(err, "could not schedule pod")
 		recorder.Publish(PodFailedToScheduleEvent(p, err))
 	}
+	for p, trace := range r.DecisionTraces {
+		log.FromContext(ctx).WithValues("Pod", klog.KObj(p)).V(1).Info("recorded scheduling decision trace", "trace", trace.String())
+		recorder.Publish(PodSchedulingDecisionEvent(p, trace))
+	}
 	// Nominate nodes only for real pods. Virtual buffer pods (injected by
 	// GetPendingPods for CapacityBuffer) must NOT trigger nomination because:
 	//   1. Nomination blocks ALL disruption (drift, expiry) — we only want to
 	//      block emptiness, which is handled by cluster.HasBufferPods instead.
 	//   2. Buffer pods are re-injected every pass, so nomination would never
 	//      expire, making buffer nodes permanently undisruptable.
+	preemptors := sets.New[types.UID]()
+	for p, preemption := range r.Preemptions {
+		preemptors.Insert(p.UID)
+		recorder.Publish(PodPreemptionEvent(p, preemption.Node.Node, preemption.Victims))
+	}
+	if len(r.Preemptions) > 0 {
+		log.FromContext(ctx).WithValues("pods", len(r.Preemptions)).Info("computed preemption(s) to fit pod(s) on existing node(s)")
+	}
 	for _, existing := range r.ExistingNodes {
 		realPods := lo.Filter(existing.Pods, func(p *corev1.Pod, _ int) bool {
 			return !isVirtualBufferPod(p)
@@ -316,6 +445,10 @@ func (r Results) Record(ctx context.Context, recorder events.Recorder, cluster *
 			cluster.NominateNodeForPod(ctx, existing.ProviderID())
 		}
 		for _, p := range realPods {
+			// Pods that are placed by preemption are reported by their preemption event
+			if preemptors.Has(p.UID) {
+				continue
+			}
 			recorder.Publish(NominatePodEvent(p, existing.Node, existing.NodeClaim))
 		}
 	}
@@ -421,7 +554,7 @@ func (r Results) TruncateInstanceTypes(ctx context.Context, maxInstanceTypes int
 	for _, newNodeClaim := range r.NewNodeClaims {
 		// The InstanceTypeOptions are truncated due to limitations in sending the number of instances to launch API.
 		var err error
-		newNodeClaim.InstanceTypeOptions, err = newNodeClaim.InstanceTypeOptions.Truncate(ctx, newNodeClaim.Requirements, maxInstanceTypes)
+		newNodeClaim.InstanceTypeOptions, err = newNodeClaim.InstanceTypeOptions.Truncate(ctx, newNodeClaim.Requirements, newNodeClaim.InstanceSelection, maxInstanceTypes)
 		if err != nil {
 			// Check if the truncated InstanceTypeOptions in each NewNodeClaim from the results still satisfy the minimum requirements
 			// If number of InstanceTypes in the NodeClaim cannot satisfy the minimum requirements, add its Pods to error map with reason.
@@ -460,6 +593,11 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*corev1.Pod) (Results, err
 
 	q := NewQueue(pods, s.cachedPodData)
 
+	var remainingResources map[string]corev1.ResourceList
+	if s.solverMode == SolverModeBestFit {
+		// The best fit repack recomputes the NodePool limits for its repacked NodeClaims from the limits before the greedy pass
+		remainingResources = maps.Clone(s.remainingResources)
+	}
 	startTime := s.clock.Now()
 	for {
 		UnfinishedWorkSeconds.Set(s.clock.Since(startTime).Seconds(), map[string]string{ControllerLabel: injection.GetControllerName(ctx), schedulingIDLabel: string(s.uuid)})
@@ -490,6 +628,20 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*corev1.Pod) (Results, err
 		}
 	}
 	UnfinishedWorkSeconds.Delete(map[string]string{ControllerLabel: injection.GetControllerName(ctx), schedulingIDLabel: string(s.uuid)})
+	if s.preemptions != nil && ctx.Err() == nil {
+		if victims := s.preempt(ctx, podErrors); s.provisionPreempted && len(victims) > 0 {
+			s.schedulePreempted(ctx, victims)
+		}
+	}
+	if incomplete := s.incompletePodGroups(ctx, pods, podErrors); len(incomplete) != 0 {
+		if s.rebuild != nil && ctx.Err() == nil {
+			return s.solveWithout(ctx, pods, incomplete, podErrors)
+		}
+		s.unschedule(incomplete)
+	}
+	if s.solverMode == SolverModeBestFit && ctx.Err() == nil {
+		s.repackBestFit(ctx, remainingResources)
+	}
 	for _, m := range s.newNodeClaims {
 		m.FinalizeScheduling(s.draDriversForNodeClaim(m)...)
 	}
@@ -506,6 +658,15 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*corev1.Pod) (Results, err
 		NewNodeClaims: s.newNodeClaims,
 		ExistingNodes: s.existingNodes,
 		PodErrors:     podErrors,
+		Preemptions:   s.preemptions,
+	}
+	if s.decisionTraces != nil {
+		results.DecisionTraces = map[*corev1.Pod]*DecisionTrace{}
+		for _, p := range pods {
+			if trace, ok := s.decisionTraces[p.UID]; ok {
+				results.DecisionTraces[p] = trace
+			}
+		}
 	}
 	if s.allocator != nil {
 		results.DRAClaimAllocationMetadata = lo.MapKeys(
@@ -540,9 +701,11 @@ func (s *Scheduler) trySchedule(ctx context.Context, p *corev1.Pod) error {
 			return err
 		}
 		// Eventually we won't be able to relax anymore and this while loop will exit
-		if relaxed := s.preferences.Relax(ctx, p); !relaxed {
+		reason := s.preferences.relax(ctx, p)
+		if reason == nil {
 			return err
 		}
+		s.decisionTrace(p).relax(*reason)
 		if e := s.topology.Update(ctx, p); e != nil && !errors.Is(e, context.DeadlineExceeded) {
 			log.FromContext(ctx).Error(e, "failed updating topology")
 		}
@@ -579,7 +742,22 @@ func (s *Scheduler) updateCachedPodData(ctx context.Context, p *corev1.Pod) {
 	s.cachedPodData[p.UID] = data
 }
 
+// decisionTrace returns the decision trace for the pod, creating it if needed. It returns nil when decision tracing is
+// disabled and must only be called from the sequential path of the scheduler.
+func (s *Scheduler) decisionTrace(p *corev1.Pod) *DecisionTrace {
+	if s.decisionTraces == nil {
+		return nil
+	}
+	trace, ok := s.decisionTraces[p.UID]
+	if !ok {
+		trace = &DecisionTrace{}
+		s.decisionTraces[p.UID] = trace
+	}
+	return trace
+}
+
 func (s *Scheduler) add(ctx context.Context, pod *corev1.Pod) error {
+	s.decisionTrace(pod).startAttempt()
 	// Check if pod has DRA requirements - if so, return DRA error when IgnoreDRARequests is enabled
 	if s.cachedPodData[pod.UID].HasResourceClaimRequests && karpopts.FromContext(ctx).IgnoreDRARequests {
 		return NewDRAError(fmt.Errorf("pod has Dynamic Resource Allocation requirements that are not yet supported by Karpenter"))
@@ -624,13 +802,18 @@ func (s *Scheduler) addToExistingNode(ctx context.Context, p *corev1.Pod) error
 	if err != nil {
 		return err
 	}
+	trace := s.decisionTrace(p)
 	parallelizeUntil(s.numConcurrentReconciles, len(s.existingNodes), func(i int) bool {
 		if s.existingNodes[i].isUnderConsolidateAfter && (!pod.IsPending(p) && !s.deletingNodeNames.Has(p.Spec.NodeName)) {
 			// We shouldn't try to schedule candidate pods onto nodes that are under consolidate after.
 			// Pending pods and pods from deleting nodes are exempt.
+			trace.rejectExistingNode(s.existingNodes[i].Name(), fmt.Errorf("node is within its consolidateAfter window"))
 			return true
 		}
 		r, result, err := s.existingNodes[i].CanAdd(ctx, p, s.cachedPodData[p.UID], volumes, s.allocator)
+		if err != nil {
+			trace.rejectExistingNode(s.existingNodes[i].Name(), err)
+		}
 		if err == nil {
 			mu.Lock()
 			defer mu.Unlock()
@@ -650,6 +833,7 @@ func (s *Scheduler) addToExistingNode(ctx context.Context, p *corev1.Pod) error
 	// If we set the existingNode to something valid, this means that we successfully scheduled to one of these nodes
 	if existingNode != nil {
 		existingNode.Add(ctx, p, s.cachedPodData[p.UID], requirements, volumes, allocationResult)
+		trace.decide(fmt.Sprintf("node/%s", existingNode.Name()))
 		return nil
 	}
 	return fmt.Errorf("failed scheduling pod to existing nodes")
@@ -664,8 +848,12 @@ func (s *Scheduler) addToInflightNode(ctx context.Context, pod *corev1.Pod) erro
 	var updatedInstanceTypes []*cloudprovider.InstanceType
 	var offeringsToReserve []*cloudprovider.Offering
 	var allocationResult *dynamicresources.AllocationResult
+	trace := s.decisionTrace(pod)
 	parallelizeUntil(s.numConcurrentReconciles, len(s.newNodeClaims), func(i int) bool {
 		r, its, ofr, result, err := s.newNodeClaims[i].CanAdd(ctx, pod, s.cachedPodData[pod.UID], false, s.allocator)
+		if err != nil {
+			trace.rejectNodeClaim(fmt.Sprintf("nodeclaim for nodepool/%s with %d pod(s)", s.newNodeClaims[i].NodePoolName, len(s.newNodeClaims[i].Pods)), err)
+		}
 		if err == nil {
 			mu.Lock()
 			defer mu.Unlock()
@@ -686,6 +874,7 @@ func (s *Scheduler) addToInflightNode(ctx context.Context, pod *corev1.Pod) erro
 	})
 	if inflightNodeClaim != nil {
 		inflightNodeClaim.Add(ctx, pod, s.cachedPodData[pod.UID], updatedRequirements, updatedInstanceTypes, offeringsToReserve, allocationResult, s.allocator)
+		trace.decide(fmt.Sprintf("a new nodeclaim for nodepool/%s", inflightNodeClaim.NodePoolName))
 		return nil
 	}
 	return fmt.Errorf("failed scheduling pod to inflight nodes")
@@ -779,6 +968,21 @@ func (s *Scheduler) addToNewNodeClaim(ctx context.Context, pod *corev1.Pod) erro
 		idx = i
 		return false
 	})
+	if trace := s.decisionTrace(pod); trace != nil {
+		tried := errs
+		if idx < len(errs) {
+			// NodePools are tried in weight order, so the NodePools after the selected NodePool weren't considered
+			tried = errs[:lo.Ternary(newNodeClaim != nil, idx, idx+1)]
+		}
+		for i, err := range tried {
+			if err != nil {
+				trace.rejectNodePool(s.nodeClaimTemplates[i], s.nodeClaimTemplates[i].InstanceTypeOptions, err)
+			}
+		}
+		if newNodeClaim != nil {
+			trace.decide(fmt.Sprintf("a new nodeclaim for nodepool/%s", newNodeClaim.NodePoolName))
+		}
+	}
 	if newNodeClaim != nil {
 		// we will launch this nodeClaim and need to track its maximum possible resource usage against our remaining resources
 		newNodeClaim.Add(ctx, pod, s.cachedPodData[pod.UID], updatedRequirements, updatedInstanceTypes, offeringsToReserve, allocationResult, s.allocator)
@@ -790,14 +994,21 @@ func (s *Scheduler) addToNewNodeClaim(ctx context.Context, pod *corev1.Pod) erro
 }
 
 func (s *Scheduler) calculateExistingNodeClaims(ctx context.Context, stateNodes []*state.StateNode, daemonSetPods []*corev1.Pod, nodePoolMap map[string]*v1.NodePool, enforceConsolidateAfter bool) {
+	var daemonsKey uint64
+	if s.cache != nil {
+		daemonsKey = daemonSetKey(ctx, daemonSetPods)
+	}
 	// create our existing nodes
 	for _, node := range stateNodes {
 		taints := node.Taints()
-		daemons := s.getCompatibleDaemonPods(ctx, node, taints, daemonSetPods)
+		daemons := s.cache.compatibleDaemonPods(node, taints, daemonsKey, func() []*corev1.Pod {
+			return s.getCompatibleDaemonPods(ctx, node, taints, daemonSetPods)
+		})
 		isUnderConsolidateAfter := enforceConsolidateAfter && disruption.IsUnderConsolidateAfter(nodePoolMap[node.Name()], node.NodeClaim, s.clock)
 		s.existingNodes = append(s.existingNodes, NewExistingNode(node, s.topology, taints, resources.RequestsForPods(daemons...), s.instanceTypeForNode(node), isUnderConsolidateAfter))
 		s.updateRemainingResources(node)
 	}
+	s.cache.evictNodes(stateNodes)
 	s.sortExistingNodes()
 }
 
@@ -971,36 +1182,39 @@ type DaemonOverheadGroup struct {
 // - Requested host ports for DaemonSet pods
 func buildDaemonOverheadGroups(ctx context.Context, nodeClaimTemplates []*NodeClaimTemplate, daemonSetPods []*corev1.Pod) map[*NodeClaimTemplate][]DaemonOverheadGroup {
 	return lo.SliceToMap(nodeClaimTemplates, func(nct *NodeClaimTemplate) (*NodeClaimTemplate, []DaemonOverheadGroup) {
-		groups := map[string]*DaemonOverheadGroup{}
-		for _, it := range nct.InstanceTypeOptions {
-			compatible := lo.Filter(daemonSetPods, func(p *corev1.Pod, _ int) bool {
-				if pod.HasDRARequirements(p) && karpopts.FromContext(ctx).IgnoreDRARequests {
-					return false
-				}
-				return isDaemonPodCompatible(nct, it, p)
-			})
-			key := podSetKey(compatible)
-			if g, ok := groups[key]; ok {
-				g.InstanceTypes = append(g.InstanceTypes, it)
-			} else {
-				var overhead corev1.ResourceList
-				if len(compatible) > 0 {
-					overhead = resources.RequestsForPods(compatible...)
-				}
-				hostPortUsage := scheduling.NewHostPortUsage()
-				for _, p := range compatible {
-					hostPortUsage.Add(p, scheduling.GetHostPorts(p))
-				}
-				groups[key] = &DaemonOverheadGroup{
-					InstanceTypes:  []*cloudprovider.InstanceType{it},
-					DaemonOverhead: overhead,
-					HostPortUsage:  hostPortUsage,
-				}
+		return nct, buildDaemonOverheadGroupsForTemplate(ctx, nct, daemonSetPods)
+	})
+}
+
+func buildDaemonOverheadGroupsForTemplate(ctx context.Context, nct *NodeClaimTemplate, daemonSetPods []*corev1.Pod) []DaemonOverheadGroup {
+	groups := map[string]*DaemonOverheadGroup{}
+	for _, it := range nct.InstanceTypeOptions {
+		compatible := lo.Filter(daemonSetPods, func(p *corev1.Pod, _ int) bool {
+			if pod.HasDRARequirements(p) && karpopts.FromContext(ctx).IgnoreDRARequests {
+				return false
+			}
+			return isDaemonPodCompatible(nct, it, p)
+		})
+		key := podSetKey(compatible)
+		if g, ok := groups[key]; ok {
+			g.InstanceTypes = append(g.InstanceTypes, it)
+		} else {
+			var overhead corev1.ResourceList
+			if len(compatible) > 0 {
+				overhead = resources.RequestsForPods(compatible...)
+			}
+			hostPortUsage := scheduling.NewHostPortUsage()
+			for _, p := range compatible {
+				hostPortUsage.Add(p, scheduling.GetHostPorts(p))
+			}
+			groups[key] = &DaemonOverheadGroup{
+				InstanceTypes:  []*cloudprovider.InstanceType{it},
+				DaemonOverhead: overhead,
+				HostPortUsage:  hostPortUsage,
 			}
 		}
-		result := lo.Map(lo.Values(groups), func(g *DaemonOverheadGroup, _ int) DaemonOverheadGroup { return *g })
-		return nct, result
-	})
+	}
+	return lo.Map(lo.Values(groups), func(g *DaemonOverheadGroup, _ int) DaemonOverheadGroup { return *g })
 }
 
 // podSetKey creates a deterministic key from a list of pods for grouping.
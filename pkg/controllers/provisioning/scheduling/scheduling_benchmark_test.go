@@ -36,6 +36,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakecr "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -108,6 +109,16 @@ func BenchmarkIgnorePreferences(b *testing.B) {
 	benchmarkScheduler(b, makePreferencePods(4000), scheduling.IgnorePreferences)
 }
 
+// The scheduler setup benchmarks measure building the topology and scheduler for a batch of pods against a cluster
+// with existing nodes, where a few pods churn between batches. A fully re-solved batch recomputes the templates, daemon
+// overhead and topology counts, while an incremental batch only recomputes what the churn changed.
+func BenchmarkSchedulerSetup(b *testing.B) {
+	benchmarkSchedulerSetup(b, false)
+}
+func BenchmarkSchedulerSetupIncremental(b *testing.B) {
+	benchmarkSchedulerSetup(b, true)
+}
+
 // TestSchedulingProfile is used to gather profiling metrics, benchmarking is primarily done with standard
 // Go benchmark functions
 // go test -tags=test_performance -run=SchedulingProfile
@@ -214,6 +225,97 @@ func benchmarkScheduler(b *testing.B, pods []*corev1.Pod, opts ...scheduling.Opt
 	b.ReportMetric(float64(nodesInRound1), "nodes")
 }
 
+func benchmarkSchedulerSetup(b *testing.B, incremental bool) {
+	ctx = options.ToContext(injection.WithControllerName(context.Background(), "provisioner"), test.Options())
+	kubeClient := fakecr.NewClientBuilder().WithIndex(&corev1.Pod{}, "spec.nodeName", func(o client.Object) []string {
+		return []string{o.(*corev1.Pod).Spec.NodeName}
+	}).Build()
+	clock := &clock.RealClock{}
+	nodePool := test.NodePool()
+	cloudProvider = fake.NewCloudProvider()
+	instanceTypes := map[string][]*cloudprovider.InstanceType{nodePool.Name: fake.InstanceTypes(400)}
+	cluster = state.NewCluster(clock, kubeClient, cloudProvider)
+
+	// 50 existing nodes with 20 pods each, spread across the zones
+	var nodes []*corev1.Node
+	for i := range 50 {
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					v1.NodeInitializedLabelKey:     "true",
+					corev1.LabelInstanceTypeStable: instanceTypes[nodePool.Name][i%400].Name,
+					corev1.LabelTopologyZone:       fmt.Sprintf("test-zone-%d", i%3+1),
+				},
+			},
+			ProviderID:  fmt.Sprintf("fake:///node-%d", i),
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("64"), corev1.ResourceMemory: resource.MustParse("256Gi"), corev1.ResourcePods: resource.MustParse("110")},
+		})
+		// nodes are cluster scoped
+		node.Namespace = ""
+		lo.Must0(kubeClient.Create(ctx, node))
+		lo.Must0(cluster.UpdateNode(ctx, node))
+		nodes = append(nodes, node)
+		for range 20 {
+			bindBenchmarkPod(ctx, kubeClient, node)
+		}
+	}
+	daemonSetPods := test.Pods(5, test.PodOptions{
+		ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+		},
+	})
+	pods := makeDiversePods(100)
+	var cache *scheduling.Cache
+	if incremental {
+		cache = scheduling.NewCache()
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// churn a pod on a few nodes between batches
+		b.StopTimer()
+		for range 5 {
+			node := nodes[r.Intn(len(nodes))]
+			podList := &corev1.PodList{}
+			lo.Must0(kubeClient.List(ctx, podList, client.MatchingFields{"spec.nodeName": node.Name}))
+			lo.Must0(kubeClient.Delete(ctx, &podList.Items[0]))
+			cluster.DeletePod(client.ObjectKeyFromObject(&podList.Items[0]))
+			bindBenchmarkPod(ctx, kubeClient, node)
+		}
+		b.StartTimer()
+
+		opts := []scheduling.Options{scheduling.NumConcurrentReconciles(5)}
+		if incremental {
+			opts = append(opts, scheduling.WithCache(cache))
+		}
+		stateNodes := cluster.DeepCopyNodes().Active()
+		topology, err := scheduling.NewTopology(ctx, kubeClient, cluster, stateNodes, []*v1.NodePool{nodePool}, instanceTypes, pods, opts...)
+		if err != nil {
+			b.Fatalf("creating topology, %s", err)
+		}
+		scheduling.NewScheduler(ctx, kubeClient, []*v1.NodePool{nodePool}, cluster, stateNodes, topology, instanceTypes, daemonSetPods,
+			events.NewRecorder(&record.FakeRecorder{}), clock, nil, nil, opts...)
+	}
+}
+
+// bindBenchmarkPod creates a pod that is bound to the node and selected by the benchmark pods' topologies
+func bindBenchmarkPod(ctx context.Context, kubeClient client.Client, node *corev1.Node) {
+	pod := test.Pod(test.PodOptions{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: lo.Assign(randomLabels(), randomAffinityLabels(), map[string]string{"app": "nginx"}),
+			UID:    uuid.NewUUID(),
+		},
+		NodeName: node.Name,
+		Phase:    corev1.PodRunning,
+		ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+		},
+	})
+	lo.Must0(kubeClient.Create(ctx, pod))
+	lo.Must0(cluster.UpdatePod(ctx, pod))
+}
+
 func setupScheduler(ctx context.Context, pods []*corev1.Pod, opts ...scheduling.Options) (*scheduling.Scheduler, error) {
 	nodePool := test.NodePool(v1.NodePool{
 		Spec: v1.NodePoolSpec{
@@ -22,9 +22,11 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/awslabs/operatorpkg/status"
 	. "github.com/onsi/ginkgo/v2"
@@ -62,9 +64,11 @@ import (
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	pscheduling "sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/state/cost"
+	"sigs.k8s.io/karpenter/pkg/state/prediction"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
 	. "sigs.k8s.io/karpenter/pkg/utils/testing"
 )
 
@@ -102,7 +106,7 @@ var _ = BeforeSuite(func() {
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cloudProvider, cluster, clusterCost)
 	podStateController = informer.NewPodController(env.Client, cluster)
-	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, env.Clock, deviceallocation.NewController(env.Client), virtualpods.NewVirtualPodCache(env.Client))
+	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, env.Clock, deviceallocation.NewController(env.Client), virtualpods.NewVirtualPodCache(env.Client), prediction.NewStore())
 	podController = provisioning.NewPodController(env.Client, prov, cluster)
 })
 
@@ -2045,7 +2049,7 @@ var _ = Context("Scheduling", func() {
 						return []string{o.(*corev1.Pod).Spec.NodeName}
 					},
 				).Build()
-				provisioner := provisioning.NewProvisioner(kubeClient, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, env.Clock, deviceallocation.NewController(kubeClient), virtualpods.NewVirtualPodCache(kubeClient))
+				provisioner := provisioning.NewProvisioner(kubeClient, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, env.Clock, deviceallocation.NewController(kubeClient), virtualpods.NewVirtualPodCache(kubeClient), prediction.NewStore())
 				controller := informer.NewNodeController(kubeClient, cluster)
 				// We try to provision a node for an initial unschedulable pod that will create nodeClaim and node bindings
 				ExpectApplied(ctx, kubeClient, nodePool)
@@ -4973,6 +4977,40 @@ var _ = Context("Scheduling", func() {
 			Expect(node.Labels).To(HaveKeyWithValue(v1.CapacityTypeLabelKey, Not(Equal(v1.CapacityTypeReserved))))
 			Expect(node.Labels).To(HaveKeyWithValue(corev1.LabelInstanceTypeStable, targetInstanceType.Name))
 		})
+		It("should launch into the reservation that ends first", func() {
+			targetInstanceType := lo.Must(lo.Find(cloudProvider.InstanceTypes, func(it *cloudprovider.InstanceType) bool {
+				return it.Name == "small-instance-type"
+			}))
+			// The original reservation doesn't end and is listed first, so it would be launched into if every reservation was
+			// passed to the launch
+			targetInstanceType.Offerings = append(targetInstanceType.Offerings, &cloudprovider.Offering{
+				ReservationCapacity: 1,
+				ReservationEndTime:  metav1.NewTime(env.Clock.Now().Add(24 * time.Hour)),
+				Available:           true,
+				Requirements: pscheduling.NewLabelRequirements(map[string]string{
+					v1.CapacityTypeLabelKey:     v1.CapacityTypeReserved,
+					corev1.LabelTopologyZone:    "test-zone-1",
+					v1alpha1.LabelReservationID: "r-ending",
+				}),
+				Price: fake.PriceFromResources(targetInstanceType.Capacity) / 100_000.0,
+			})
+			ExpectApplied(ctx, env.Client, nodePool)
+
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeRequirements: []corev1.NodeSelectorRequirement{{
+					Key:      corev1.LabelInstanceTypeStable,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{targetInstanceType.Name},
+				}},
+			})
+			bindings := ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(len(bindings.Bindings)).To(Equal(1))
+			node := lo.Values(bindings.Bindings)[0].Node
+			Expect(node.Labels).To(HaveKeyWithValue(cloudprovider.ReservationIDLabel, "r-ending"))
+			Expect(node.Labels).To(HaveKeyWithValue(v1.CapacityTypeLabelKey, v1.CapacityTypeReserved))
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(pscheduling.NewNodeSelectorRequirementsWithMinValues(cloudProvider.CreateCalls[0].Spec.Requirements...).Get(cloudprovider.ReservationIDLabel).Values()).To(ConsistOf("r-ending"))
+		})
 		It("shouldn't fallback to a lower weight NodePool if a reserved offering is available", func() {
 			nodePool.Name = "np-primary"
 			nodePool.Spec.Weight = lo.ToPtr[int32](100)
@@ -5601,6 +5639,456 @@ var _ = Context("Scheduling", func() {
 			Expect(results.NewNodeClaims).To(HaveLen(0))
 		})
 	})
+	Context("Decision Traces", func() {
+		traceFor := func(results scheduling.Results, pod *corev1.Pod) *scheduling.DecisionTrace {
+			GinkgoHelper()
+			for p, trace := range results.DecisionTraces {
+				if p.UID == pod.UID {
+					return trace
+				}
+			}
+			Fail(fmt.Sprintf("expected a decision trace for pod %s", pod.Name))
+			return nil
+		}
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{SchedulingDecisionTrace: new(true)}))
+		})
+		AfterEach(func() {
+			ctx = options.ToContext(ctx, test.Options())
+		})
+		It("should not record decision traces by default", func() {
+			ctx = options.ToContext(ctx, test.Options())
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, nodePool, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.DecisionTraces).To(BeNil())
+		})
+		It("should record the nodepool and relaxed preferences for a scheduled pod", func() {
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodePreferences: []corev1.NodeSelectorRequirement{
+					{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"unknown"}},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			trace := traceFor(results, pod)
+			Expect(trace.Decision).To(Equal(fmt.Sprintf("a new nodeclaim for nodepool/%s", nodePool.Name)))
+			Expect(trace.Relaxations).To(HaveLen(1))
+			Expect(trace.Relaxations[0]).To(ContainSubstring("preferredDuringSchedulingIgnoredDuringExecution"))
+			Expect(trace.NodePools).To(BeEmpty())
+		})
+		It("should record why existing nodes and nodepools rejected a pod", func() {
+			node := test.Node(test.NodeOptions{
+				Taints: []corev1.Taint{{Key: "test-taint", Value: "true", Effect: corev1.TaintEffectNoSchedule}},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10"),
+					corev1.ResourceMemory: resource.MustParse("10Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+			})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectMakeNodesInitialized(ctx, env.Client, env.Clock, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+
+			pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1000")},
+			}})
+			ExpectApplied(ctx, env.Client, nodePool, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			trace := traceFor(results, pod)
+			Expect(trace.Decision).To(BeEmpty())
+			Expect(trace.ExistingNodes).To(HaveLen(1))
+			Expect(trace.ExistingNodes[0].Name).To(Equal(node.Name))
+			Expect(trace.ExistingNodes[0].Reason).To(ContainSubstring("did not tolerate taint"))
+			Expect(trace.NodePools).To(HaveLen(1))
+			Expect(trace.NodePools[0].Name).To(Equal(nodePool.Name))
+			Expect(trace.NodePools[0].FilteredInstanceTypes).To(HaveKeyWithValue("resources", len(cloudProvider.InstanceTypes)))
+			Expect(scheduling.PodSchedulingDecisionEvent(pod, trace).Message).To(ContainSubstring(fmt.Sprintf("rejected by nodepool/%s", nodePool.Name)))
+		})
+		It("should truncate decision trace event messages on a rune boundary", func() {
+			pod := test.UnschedulablePod()
+			trace := &scheduling.DecisionTrace{Relaxations: []string{strings.Repeat("é", 1024)}}
+			msg := scheduling.PodSchedulingDecisionEvent(pod, trace).Message
+			Expect(len(msg)).To(BeNumerically("<=", 1024))
+			Expect(utf8.ValidString(msg)).To(BeTrue())
+			Expect(msg).To(HaveSuffix("é..."))
+		})
+	})
+	Context("Preemption", func() {
+		var node *corev1.Node
+		var victim *corev1.Pod
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{SchedulingPreemption: new(options.SchedulingPreemptionSimulate)}))
+			node = test.Node(test.NodeOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"preemption": "true"}},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("10Gi"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+			})
+			victim = test.Pod(test.PodOptions{
+				ObjectMeta:           metav1.ObjectMeta{Labels: podLabels},
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")}},
+			})
+			victim.Spec.Priority = new(int32(0))
+		})
+		AfterEach(func() {
+			ctx = options.ToContext(ctx, test.Options())
+		})
+		expectNodeWithVictim := func() {
+			GinkgoHelper()
+			ExpectApplied(ctx, env.Client, nodePool, node, victim)
+			ExpectMakeNodesInitialized(ctx, env.Client, env.Clock, node)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+			ExpectManualBinding(ctx, env.Client, victim, node)
+			ExpectReconcileSucceeded(ctx, podStateController, client.ObjectKeyFromObject(victim))
+		}
+		preemptor := func(priority int32) *corev1.Pod {
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeSelector:         map[string]string{"preemption": "true"},
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}},
+			})
+			pod.Spec.Priority = new(priority)
+			return pod
+		}
+		It("should place a pod on an existing node by preempting lower priority pods", func() {
+			expectNodeWithVictim()
+			pod := preemptor(1000)
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.PodErrors).To(BeEmpty())
+			Expect(results.NewNodeClaims).To(BeEmpty())
+			Expect(results.Preemptions).To(HaveLen(1))
+			for p, preemption := range results.Preemptions {
+				Expect(p.UID).To(Equal(pod.UID))
+				Expect(preemption.Node.Name()).To(Equal(node.Name))
+				Expect(preemption.Victims).To(ConsistOf(HaveField("UID", victim.UID)))
+			}
+		})
+		It("should not preempt pods when preemption is disabled", func() {
+			ctx = options.ToContext(ctx, test.Options())
+			expectNodeWithVictim()
+			pod := preemptor(1000)
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.PodErrors).To(HaveLen(1))
+			Expect(results.Preemptions).To(BeEmpty())
+		})
+		It("should not preempt pods of equal or higher priority", func() {
+			expectNodeWithVictim()
+			pod := preemptor(0)
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.PodErrors).To(HaveLen(1))
+			Expect(results.Preemptions).To(BeEmpty())
+		})
+		It("should not preempt pods for a pod that never preempts", func() {
+			expectNodeWithVictim()
+			pod := preemptor(1000)
+			pod.Spec.PreemptionPolicy = lo.ToPtr(corev1.PreemptNever)
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.PodErrors).To(HaveLen(1))
+			Expect(results.Preemptions).To(BeEmpty())
+		})
+		It("should not preempt pods if doing so would violate a PDB", func() {
+			expectNodeWithVictim()
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels:         podLabels,
+				MaxUnavailable: &intstr.IntOrString{IntVal: 0},
+			})
+			ExpectApplied(ctx, env.Client, pdb)
+			pod := preemptor(1000)
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.PodErrors).To(HaveLen(1))
+			Expect(results.Preemptions).To(BeEmpty())
+		})
+		It("should not consume PDB disruptions twice when an incomplete pod group is solved again", func() {
+			expectNodeWithVictim()
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels:         podLabels,
+				MaxUnavailable: &intstr.IntOrString{IntVal: 1},
+				Status:         &policyv1.PodDisruptionBudgetStatus{ObservedGeneration: 1, DisruptionsAllowed: 1},
+			})
+			ExpectApplied(ctx, env.Client, pdb)
+			pod := preemptor(1000)
+			// The group can't schedule since one of its members doesn't fit on any instance type, so the pods are solved
+			// again on a new scheduler without the group's members
+			group := lo.Map([]string{"1", "1000"}, func(cpu string, _ int) *corev1.Pod {
+				return test.UnschedulablePod(test.PodOptions{
+					ObjectMeta:           metav1.ObjectMeta{Labels: map[string]string{v1.PodGroupLabelKey: "training"}},
+					ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+				})
+			})
+			ExpectApplied(ctx, env.Client, pod, group[0], group[1])
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.Preemptions).To(HaveLen(1))
+			for p, preemption := range results.Preemptions {
+				Expect(p.UID).To(Equal(pod.UID))
+				Expect(preemption.Victims).To(ConsistOf(HaveField("UID", victim.UID)))
+			}
+			Expect(results.PodErrors).To(HaveLen(2))
+			for p := range results.PodErrors {
+				Expect(p.Labels).To(HaveKeyWithValue(v1.PodGroupLabelKey, "training"))
+			}
+		})
+		It("should provision capacity for the preempted pods", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{SchedulingPreemption: new(options.SchedulingPreemptionSimulateAndProvision)}))
+			expectNodeWithVictim()
+			pod := preemptor(1000)
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.PodErrors).To(BeEmpty())
+			Expect(results.Preemptions).To(HaveLen(1))
+			Expect(results.NewNodeClaims).To(HaveLen(1))
+			Expect(results.NewNodeClaims[0].Pods).To(ConsistOf(HaveField("UID", victim.UID)))
+		})
+	})
+	Context("Pod Groups", func() {
+		groupPods := func(labelKey, group string, requests ...string) []*corev1.Pod {
+			return lo.Map(requests, func(cpu string, _ int) *corev1.Pod {
+				return test.UnschedulablePod(test.PodOptions{
+					ObjectMeta:           metav1.ObjectMeta{Labels: map[string]string{labelKey: group}},
+					ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+				})
+			})
+		}
+		It("should schedule a pod group when every member can schedule", func() {
+			pods := groupPods(v1.PodGroupLabelKey, "training", "1", "1", "1")
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			for _, pod := range pods {
+				ExpectScheduled(ctx, env.Client, pod)
+			}
+		})
+		It("should not launch capacity for a pod group when a member can't schedule", func() {
+			pods := groupPods(v1.PodGroupLabelKey, "training", "1", "1", "1000")
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			for _, pod := range pods {
+				ExpectNotScheduled(ctx, env.Client, pod)
+			}
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(BeEmpty())
+		})
+		It("should report the members that could schedule with a pod group error", func() {
+			pods := groupPods(v1.PodGroupLabelKey, "training", "1", "1000")
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectApplied(ctx, env.Client, pods[0], pods[1])
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.NewNodeClaims).To(BeEmpty())
+			Expect(results.PodErrors).To(HaveLen(2))
+			for p, err := range results.PodErrors {
+				Expect(scheduling.IsPodGroupError(err)).To(Equal(p.UID == pods[0].UID))
+			}
+		})
+		It("should not constrain a nodeclaim by the members of a pod group that failed to schedule", func() {
+			pods := groupPods(v1.PodGroupLabelKey, "training", "1", "1000")
+			pods[0].Spec.NodeSelector = map[string]string{corev1.LabelTopologyZone: "test-zone-1"}
+			pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			}})
+			ExpectApplied(ctx, env.Client, nodePool, pods[0], pods[1], pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.NewNodeClaims).To(HaveLen(1))
+			nodeClaim := results.NewNodeClaims[0]
+			Expect(nodeClaim.Pods).To(HaveLen(1))
+			Expect(nodeClaim.Pods[0].UID).To(Equal(pod.UID))
+			Expect(nodeClaim.Spec.Resources.Requests.Cpu().Equal(resource.MustParse("1"))).To(BeTrue())
+			Expect(nodeClaim.Requirements.Get(corev1.LabelTopologyZone).Has("test-zone-2")).To(BeTrue())
+			for p, err := range results.PodErrors {
+				Expect(scheduling.IsPodGroupError(err)).To(Equal(p.UID == pods[0].UID))
+			}
+		})
+		It("should not block pods outside of a pod group that failed to schedule", func() {
+			pods := groupPods(v1.PodGroupLabelKey, "training", "1", "1000")
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, append(pods, pod)...)
+			ExpectScheduled(ctx, env.Client, pod)
+			ExpectNotScheduled(ctx, env.Client, pods[0])
+			ExpectNotScheduled(ctx, env.Client, pods[1])
+		})
+		It("should scope pod groups to their namespace", func() {
+			pods := groupPods(v1.PodGroupLabelKey, "training", "1", "1000")
+			pods[0].Namespace = test.RandomName()
+			ExpectApplied(ctx, env.Client, nodePool, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: pods[0].Namespace}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			ExpectScheduled(ctx, env.Client, pods[0])
+			ExpectNotScheduled(ctx, env.Client, pods[1])
+		})
+		It("should support the coscheduling plugin's pod group label", func() {
+			pods := groupPods(podutils.CoschedulingPodGroupLabelKey, "training", "1", "1000")
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			ExpectNotScheduled(ctx, env.Client, pods[0])
+			ExpectNotScheduled(ctx, env.Client, pods[1])
+		})
+		It("should hold a pod group until its size is pending when members arrive across batches", func() {
+			pods := groupPods(v1.PodGroupLabelKey, "training", "1", "1", "1", "1")
+			for _, pod := range pods {
+				pod.Annotations = map[string]string{v1.PodGroupSizeAnnotationKey: "4"}
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+
+			// The first batch only has half of the group, so no capacity is launched for it
+			ExpectApplied(ctx, env.Client, pods[0], pods[1])
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.NewNodeClaims).To(BeEmpty())
+			Expect(results.PodErrors).To(HaveLen(2))
+			for _, err := range results.PodErrors {
+				Expect(scheduling.IsPodGroupError(err)).To(BeTrue())
+			}
+
+			// The rest of the group arrives in the second batch
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			for _, pod := range pods {
+				ExpectScheduled(ctx, env.Client, pod)
+			}
+		})
+		It("should count members that are bound to nodes towards a pod group's size", func() {
+			pods := groupPods(v1.PodGroupLabelKey, "training", "1", "1")
+			for _, pod := range pods {
+				pod.Annotations = map[string]string{v1.PodGroupSizeAnnotationKey: "3"}
+			}
+			bound := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.PodGroupLabelKey: "training"}},
+				NodeName:   "node",
+			})
+			ExpectApplied(ctx, env.Client, nodePool, bound)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			for _, pod := range pods {
+				ExpectScheduled(ctx, env.Client, pod)
+			}
+		})
+	})
+	Context("Pod Group Topology", func() {
+		const blockLabelKey = "example.com/block"
+		const spineLabelKey = "example.com/spine"
+		// Blocks b1 and b2 are in spine s1, and block b3 is in spine s2
+		spines := map[string]string{"b1": "s1", "b2": "s1", "b3": "s2"}
+		// Members require their group's spine and prefer their group's block, as described in designs/pod-group-topology.md
+		groupPods := func(count int) []*corev1.Pod {
+			selector := &metav1.LabelSelector{MatchLabels: map[string]string{v1.PodGroupLabelKey: "training"}}
+			return lo.Times(count, func(_ int) *corev1.Pod {
+				return test.UnschedulablePod(test.PodOptions{
+					ObjectMeta:      metav1.ObjectMeta{Labels: map[string]string{v1.PodGroupLabelKey: "training"}},
+					PodRequirements: []corev1.PodAffinityTerm{{LabelSelector: selector, TopologyKey: spineLabelKey}},
+					PodPreferences:  []corev1.WeightedPodAffinityTerm{{Weight: 100, PodAffinityTerm: corev1.PodAffinityTerm{LabelSelector: selector, TopologyKey: blockLabelKey}}},
+					// Only one member fits on each node
+					ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.5")}},
+				})
+			})
+		}
+		BeforeEach(func() {
+			var offerings []cloudprovider.Offering
+			for block, spine := range spines {
+				offerings = append(offerings, cloudprovider.Offering{
+					Available: true,
+					Requirements: pscheduling.NewLabelRequirements(map[string]string{
+						v1.CapacityTypeLabelKey:  v1.CapacityTypeOnDemand,
+						corev1.LabelTopologyZone: "test-zone-1",
+						blockLabelKey:            block,
+						spineLabelKey:            spine,
+					}),
+					Price: 1.00,
+				})
+			}
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType("accelerated",
+					fake.WithResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}),
+					fake.WithRequirements(
+						pscheduling.NewRequirement(blockLabelKey, corev1.NodeSelectorOpIn, lo.Keys(spines)...),
+						pscheduling.NewRequirement(spineLabelKey, corev1.NodeSelectorOpIn, lo.Uniq(lo.Values(spines))...),
+					),
+					fake.WithOfferings(offerings...),
+				),
+			}
+			nodePool.Spec.Template.Spec.Requirements = append(nodePool.Spec.Template.Spec.Requirements,
+				v1.NodeSelectorRequirementWithMinValues{Key: blockLabelKey, Operator: corev1.NodeSelectorOpExists},
+				v1.NodeSelectorRequirementWithMinValues{Key: spineLabelKey, Operator: corev1.NodeSelectorOpExists},
+			)
+		})
+		It("should schedule a pod group to a single block", func() {
+			pods := groupPods(4)
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			blocks := sets.New[string]()
+			for _, pod := range pods {
+				node := ExpectScheduled(ctx, env.Client, pod)
+				blocks.Insert(node.Labels[blockLabelKey])
+				Expect(node.Labels[spineLabelKey]).To(Equal(spines[node.Labels[blockLabelKey]]))
+			}
+			Expect(blocks).To(HaveLen(1))
+		})
+		It("should schedule members to the block of the members that have already scheduled", func() {
+			pods := groupPods(3)
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods[0])
+			block := ExpectScheduled(ctx, env.Client, pods[0]).Labels[blockLabelKey]
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods[1:]...)
+			for _, pod := range pods[1:] {
+				Expect(ExpectScheduled(ctx, env.Client, pod).Labels).To(HaveKeyWithValue(blockLabelKey, block))
+			}
+		})
+	})
+	Context("Incremental Scheduling", func() {
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{IncrementalScheduling: new(true)}}))
+		})
+		AfterEach(func() {
+			ctx = options.ToContext(ctx, test.Options())
+		})
+		It("should count pods that left the cluster between batches", func() {
+			topology := []corev1.TopologySpreadConstraint{{
+				TopologyKey:       corev1.LabelTopologyZone,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: podLabels},
+				MaxSkew:           1,
+			}}
+			pods := test.UnschedulablePods(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}, TopologySpreadConstraints: topology}, 3)
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			ExpectSkew(ctx, env.Client, "default", &topology[0]).To(ConsistOf(1, 1, 1))
+
+			zone := ExpectScheduled(ctx, env.Client, pods[0]).Labels[corev1.LabelTopologyZone]
+			ExpectDeleted(ctx, env.Client, pods[0])
+			ExpectReconcileSucceeded(ctx, podStateController, client.ObjectKeyFromObject(pods[0]))
+
+			pod := test.UnschedulablePod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}, TopologySpreadConstraints: topology})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, zone))
+		})
+		It("should rebuild the NodeClaimTemplate when the NodePool changes between batches", func() {
+			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+				{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-1"))
+
+			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+				{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod = test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{corev1.LabelTopologyZone: "test-zone-2"}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			Expect(ExpectScheduled(ctx, env.Client, pod).Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-2"))
+		})
+	})
 })
 
 // nolint:gocyclo
@@ -58,11 +58,18 @@ type Topology struct {
 	inverseTopologyGroups map[uint64]*TopologyGroup
 	// The universe of domains by topology key
 	domainGroups map[string]TopologyDomainGroup
+	// domainHierarchy tracks which domains of the pod affinity topology keys are offered together, so that the domains
+	// picked for one level of a hierarchical topology are consistent with the domains picked for the others
+	domainHierarchy TopologyDomainHierarchy
 	// excludedPods are the pod UIDs of pods that are excluded from counting.  This is used so we can simulate
 	// moving pods to prevent them from being double counted.
 	excludedPods sets.Set[string]
 	cluster      *state.Cluster
 	stateNodes   []*state.StateNode
+	// cache persists the pods that are counted by the topology groups across scheduling loops. It is nil unless the
+	// scheduler is incrementally updated, in which case nodes are the cluster state nodes that the pods are counted on.
+	cache *Cache
+	nodes map[string]clusterNode
 }
 
 func NewTopology(
@@ -75,15 +82,18 @@ func NewTopology(
 	pods []*corev1.Pod,
 	opts ...Options,
 ) (*Topology, error) {
+	cache := option.Resolve(opts...).cache
+	cache.startTopology()
 	t := &Topology{
 		kubeClient:            kubeClient,
 		preferencePolicy:      option.Resolve(opts...).preferencePolicy,
 		cluster:               cluster,
 		stateNodes:            stateNodes,
-		domainGroups:          buildDomainGroups(nodePools, instanceTypes),
+		domainGroups:          cache.topologyDomainGroups(nodePools, instanceTypes),
 		topologyGroups:        map[uint64]*TopologyGroup{},
 		inverseTopologyGroups: map[uint64]*TopologyGroup{},
 		excludedPods:          sets.New[string](),
+		cache:                 cache,
 	}
 
 	// these are the pods that we intend to schedule, so if they are currently in the cluster we shouldn't count them for
@@ -99,6 +109,7 @@ func NewTopology(
 	if errs != nil {
 		return nil, errs
 	}
+	t.domainHierarchy = NewTopologyDomainHierarchy(t.affinityKeys(), instanceTypes)
 	return t, nil
 }
 
@@ -234,6 +245,15 @@ func (t *Topology) AddRequirements(p *corev1.Pod, taints []corev1.Taint, podRequ
 		if nodeRequirements.Has(topology.Key) {
 			nodeDomains = nodeRequirements.Get(topology.Key)
 		}
+		// A pod with affinity to several levels of a hierarchical topology can only land in domains that are offered
+		// together with the domains that it has already been narrowed to, e.g. a block within its spine
+		if topology.Type == TopologyTypePodAffinity {
+			if offered, ok := t.domainHierarchy.Domains(topology.Key, requirements); ok {
+				offeredDomains := scheduling.NewRequirement(topology.Key, corev1.NodeSelectorOpIn, offered.UnsortedList()...)
+				podDomains = podDomains.Intersection(offeredDomains)
+				nodeDomains = nodeDomains.Intersection(offeredDomains)
+			}
+		}
 		domains, _ := topology.Get(p, podDomains, nodeDomains)
 		if domains.Len() == 0 {
 			return nil, topologyError{
@@ -359,18 +379,6 @@ func (t *Topology) updateInverseAntiAffinity(ctx context.Context, pod *corev1.Po
 //
 //nolint:gocyclo
 func (t *Topology) countDomains(ctx context.Context, tg *TopologyGroup) error {
-	podList := &corev1.PodList{}
-
-	// collect the pods from all the specified namespaces (don't see a way to query multiple namespaces
-	// simultaneously)
-	var pods []corev1.Pod
-	for _, ns := range tg.namespaces.UnsortedList() {
-		if err := t.kubeClient.List(ctx, podList, TopologyListOptions(ns, tg.rawSelector)); err != nil {
-			return fmt.Errorf("listing pods, %w", err)
-		}
-		pods = append(pods, podList.Items...)
-	}
-
 	// capture new domain values from existing nodes that may not have any pods selected by the topology group
 	// scheduled to them already
 	// Note: long term we should handle this when constructing the domain groups, but that would require domain groups
@@ -394,6 +402,21 @@ func (t *Topology) countDomains(ctx context.Context, tg *TopologyGroup) error {
 		}
 	}
 
+	if t.cache != nil {
+		return t.cache.countDomains(ctx, t, tg)
+	}
+
+	podList := &corev1.PodList{}
+	// collect the pods from all the specified namespaces (don't see a way to query multiple namespaces
+	// simultaneously)
+	var pods []corev1.Pod
+	for _, ns := range tg.namespaces.UnsortedList() {
+		if err := t.kubeClient.List(ctx, podList, TopologyListOptions(ns, tg.rawSelector)); err != nil {
+			return fmt.Errorf("listing pods, %w", err)
+		}
+		pods = append(pods, podList.Items...)
+	}
+
 	// sort our pods by the node they are scheduled to
 	sort.Slice(pods, func(i, j int) bool {
 		return pods[i].Spec.NodeName < pods[j].Spec.NodeName
@@ -573,6 +596,31 @@ func (t *Topology) getMatchingTopologies(p *corev1.Pod, taints []corev1.Taint, r
 	return matchingTopologies
 }
 
+// affinityKeys returns the topology keys of pod affinities that aren't well known labels. Well known labels, such as
+// zones and hostnames, aren't levels of a hierarchical network topology.
+func (t *Topology) affinityKeys() sets.Set[string] {
+	keys := sets.New[string]()
+	for _, tg := range t.topologyGroups {
+		if tg.Type == TopologyTypePodAffinity && !v1.WellKnownLabels.Has(tg.Key) {
+			keys.Insert(tg.Key)
+		}
+	}
+	return keys
+}
+
+// isIndependent returns true if the placement of pod p doesn't affect, and isn't affected by, any topology
+func (t *Topology) isIndependent(p *corev1.Pod, taints []corev1.Taint, requirements scheduling.Requirements, compatibilityOptions ...option.Function[scheduling.CompatibilityOptions]) bool {
+	if len(t.getMatchingTopologies(p, taints, requirements, compatibilityOptions...)) != 0 {
+		return false
+	}
+	for _, tg := range t.topologyGroups {
+		if tg.Counts(p, taints, requirements, compatibilityOptions...) {
+			return false
+		}
+	}
+	return true
+}
+
 func TopologyListOptions(namespace string, labelSelector *metav1.LabelSelector) *client.ListOptions {
 	selector := labels.Everything()
 	if labelSelector == nil {
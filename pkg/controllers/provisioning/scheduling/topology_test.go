@@ -30,6 +30,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
@@ -2144,6 +2145,38 @@ var _ = Describe("Topology", func() {
 			}
 			Expect(len(nodeNames)).To(Equal(1))
 		})
+		It("should respect self pod affinity (zone) when the pods don't fit on one node", func() {
+			// Each pod needs its own node, so every pod after the first has to find the zone that the first was placed
+			// in. The first pod is only counted in a zone if its node is narrowed to a single zone.
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType("small", fake.WithResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")})),
+			}
+			affLabels := map[string]string{"security": "s2"}
+			pods := test.UnschedulablePods(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: affLabels,
+				},
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.5")}},
+				PodRequirements: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: affLabels,
+					},
+					TopologyKey: corev1.LabelTopologyZone,
+				}},
+			}, 5)
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			zones := sets.New[string]()
+			nodeNames := sets.New[string]()
+			for _, p := range pods {
+				n := ExpectScheduled(ctx, env.Client, p)
+				zones.Insert(n.Labels[corev1.LabelTopologyZone])
+				nodeNames.Insert(n.Name)
+			}
+			Expect(nodeNames).To(HaveLen(5))
+			Expect(zones).To(HaveLen(1))
+		})
 		It("should respect self pod affinity (zone w/ constraint)", func() {
 			affLabels := map[string]string{"security": "s2"}
 			// the pod needs to provide it's own zonal affinity, but we further limit it to only being on test-zone-3
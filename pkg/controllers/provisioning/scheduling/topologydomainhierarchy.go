@@ -0,0 +1,111 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+)
+
+// TopologyDomainHierarchy tracks which domains of different topology keys are offered together. Cloud providers describe
+// hierarchical network topologies, such as blocks within a spine, through the requirements of their instance types and
+// offerings. The hierarchy is indexed by key and domain, and then by the other key, e.g. block -> b1 -> spine -> {s1}.
+type TopologyDomainHierarchy map[string]map[string]map[string]sets.Set[string]
+
+// NewTopologyDomainHierarchy builds the hierarchy of the provided keys from the instance types' offerings. An offering's
+// requirements are narrower than its instance type's, so they take precedence.
+func NewTopologyDomainHierarchy(keys sets.Set[string], instanceTypes map[string][]*cloudprovider.InstanceType) TopologyDomainHierarchy {
+	h := TopologyDomainHierarchy{}
+	if keys.Len() < 2 {
+		return h
+	}
+	for _, its := range instanceTypes {
+		for _, it := range its {
+			for _, o := range it.Offerings {
+				domains := map[string][]string{}
+				for key := range keys {
+					requirement := it.Requirements.Get(key)
+					if o.Requirements.Has(key) {
+						requirement = o.Requirements.Get(key)
+					}
+					if requirement.Operator() == corev1.NodeSelectorOpIn {
+						domains[key] = requirement.Values()
+					}
+				}
+				for key, values := range domains {
+					for _, value := range values {
+						for other, otherValues := range domains {
+							if other == key {
+								continue
+							}
+							if h[key] == nil {
+								h[key] = map[string]map[string]sets.Set[string]{}
+							}
+							if h[key][value] == nil {
+								h[key][value] = map[string]sets.Set[string]{}
+							}
+							if h[key][value][other] == nil {
+								h[key][value][other] = sets.New[string]()
+							}
+							h[key][value][other].Insert(otherValues...)
+						}
+					}
+				}
+			}
+		}
+	}
+	return h
+}
+
+// Domains returns the domains of the key that are offered together with the domains that the requirements have been
+// narrowed to for the other keys in the hierarchy. It returns false if the hierarchy doesn't constrain the key, e.g. if
+// the requirements allow a domain that isn't offered, such as the domain of an existing node whose instance type is no
+// longer offered.
+func (h TopologyDomainHierarchy) Domains(key string, requirements scheduling.Requirements) (sets.Set[string], bool) {
+	var result sets.Set[string]
+	for other, index := range h {
+		if other == key || !requirements.Has(other) {
+			continue
+		}
+		requirement := requirements.Get(other)
+		if requirement.Operator() != corev1.NodeSelectorOpIn {
+			continue
+		}
+		allowed := sets.New[string]()
+		known := true
+		for _, value := range requirement.Values() {
+			domains, ok := index[value][key]
+			if !ok {
+				known = false
+				break
+			}
+			allowed = allowed.Union(domains)
+		}
+		if !known {
+			continue
+		}
+		if result == nil {
+			result = allowed
+		} else {
+			result = result.Intersection(allowed)
+		}
+	}
+	return result, result != nil
+}
@@ -376,8 +376,12 @@ func (t *TopologyGroup) nextDomainAffinity(pod *corev1.Pod, podDomains *scheduli
 			}
 		}
 
-		// and if there are no node domains, just return the first random domain that is viable
+		// and if there are no node domains, just return the first random domain that is viable. Picking a second domain
+		// when the first was found would keep the pod from being counted in either, since the domain isn't known.
 		for domain := range t.domains {
+			if options.Len() != 0 {
+				break
+			}
 			if podDomains.Has(domain) {
 				options.Insert(domain)
 				break
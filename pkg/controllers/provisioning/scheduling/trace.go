@@ -0,0 +1,205 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// MaxTracedRejections is the maximum number of existing node and in-flight NodeClaim rejections that are kept for a
+// pod. Rejections past this limit are only counted, to bound the size of the trace in large clusters.
+var MaxTracedRejections = 10
+
+// Rejection records why a pod couldn't schedule to a candidate
+type Rejection struct {
+	Name   string
+	Reason string
+}
+
+// NodePoolDecision records why a pod couldn't schedule to a new NodeClaim for a NodePool
+type NodePoolDecision struct {
+	Name   string
+	Weight int32
+	Reason string
+	// FilteredInstanceTypes is the number of the NodePool's instance types that were filtered out by each requirement
+	// key. The "resources" and "offerings" keys count instance types without enough resources for the pod or without
+	// a compatible offering.
+	FilteredInstanceTypes map[string]int
+}
+
+// DecisionTrace records the decisions that the scheduler made for a pod in its last scheduling attempt. A nil trace
+// ignores all records, so callers don't need to check whether tracing is enabled.
+type DecisionTrace struct {
+	mu sync.Mutex
+
+	// Attempts is the number of times the scheduler attempted to schedule the pod
+	Attempts int
+	// ExistingNodes are the existing and in-flight nodes that rejected the pod
+	ExistingNodes         []Rejection
+	ExistingNodesRejected int
+	// NodeClaims are the NodeClaims being created in this simulation that rejected the pod
+	NodeClaims         []Rejection
+	NodeClaimsRejected int
+	// NodePools are the NodePools that rejected the pod, in the order they were tried
+	NodePools []NodePoolDecision
+	// Relaxations are the preferences that were relaxed for the pod, across all attempts
+	Relaxations []string
+	// Decision describes where the pod was scheduled, it's empty if the pod failed to schedule
+	Decision string
+}
+
+// startAttempt clears the records from the previous scheduling attempt
+func (t *DecisionTrace) startAttempt() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Attempts++
+	t.ExistingNodes, t.ExistingNodesRejected = nil, 0
+	t.NodeClaims, t.NodeClaimsRejected = nil, 0
+	t.NodePools = nil
+	t.Decision = ""
+}
+
+func (t *DecisionTrace) rejectExistingNode(name string, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ExistingNodesRejected++
+	if len(t.ExistingNodes) < MaxTracedRejections {
+		t.ExistingNodes = append(t.ExistingNodes, Rejection{Name: name, Reason: err.Error()})
+	}
+}
+
+func (t *DecisionTrace) rejectNodeClaim(name string, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.NodeClaimsRejected++
+	if len(t.NodeClaims) < MaxTracedRejections {
+		t.NodeClaims = append(t.NodeClaims, Rejection{Name: name, Reason: err.Error()})
+	}
+}
+
+func (t *DecisionTrace) rejectNodePool(template *NodeClaimTemplate, instanceTypes []*cloudprovider.InstanceType, err error) {
+	if t == nil {
+		return
+	}
+	decision := NodePoolDecision{Name: template.NodePoolName, Weight: template.NodePoolWeight, Reason: err.Error()}
+	if filterErr, ok := lo.ErrorsAs[InstanceTypeFilterError](err); ok {
+		decision.FilteredInstanceTypes = filterErr.filteredByRequirement(instanceTypes)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.NodePools = append(t.NodePools, decision)
+}
+
+func (t *DecisionTrace) relax(reason string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Relaxations = append(t.Relaxations, reason)
+}
+
+func (t *DecisionTrace) decide(decision string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Decision = decision
+}
+
+// String returns a single line description of the trace, ordered from the most to the least relevant decisions since
+// event messages may be truncated
+func (t *DecisionTrace) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parts := []string{fmt.Sprintf("attempts=%d", t.Attempts)}
+	if t.Decision != "" {
+		parts = append(parts, fmt.Sprintf("scheduled to %s", t.Decision))
+	}
+	if len(t.Relaxations) != 0 {
+		parts = append(parts, fmt.Sprintf("relaxed [%s]", strings.Join(t.Relaxations, "; ")))
+	}
+	for _, np := range t.NodePools {
+		msg := fmt.Sprintf("rejected by nodepool/%s (weight=%d): %s", np.Name, np.Weight, np.Reason)
+		if len(np.FilteredInstanceTypes) != 0 {
+			keys := lo.Keys(np.FilteredInstanceTypes)
+			sort.Strings(keys)
+			msg += fmt.Sprintf(" [instance types filtered by %s]", strings.Join(lo.Map(keys, func(k string, _ int) string {
+				return fmt.Sprintf("%s=%d", k, np.FilteredInstanceTypes[k])
+			}), ", "))
+		}
+		parts = append(parts, msg)
+	}
+	if t.NodeClaimsRejected != 0 {
+		parts = append(parts, fmt.Sprintf("rejected by %d new nodeclaim(s) [%s]", t.NodeClaimsRejected, rejectionsString(t.NodeClaims, t.NodeClaimsRejected)))
+	}
+	if t.ExistingNodesRejected != 0 {
+		parts = append(parts, fmt.Sprintf("rejected by %d existing node(s) [%s]", t.ExistingNodesRejected, rejectionsString(t.ExistingNodes, t.ExistingNodesRejected)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func rejectionsString(rejections []Rejection, total int) string {
+	msg := strings.Join(lo.Map(rejections, func(r Rejection, _ int) string {
+		return fmt.Sprintf("%s: %s", r.Name, r.Reason)
+	}), ", ")
+	if total > len(rejections) {
+		msg += fmt.Sprintf(" and %d other(s)", total-len(rejections))
+	}
+	return msg
+}
+
+// filteredByRequirement counts the instance types that are filtered out by each of the requirements which were used
+// when filtering, along with the instance types that don't have enough resources or a compatible offering. An instance
+// type may be counted against multiple keys.
+func (e InstanceTypeFilterError) filteredByRequirement(instanceTypes []*cloudprovider.InstanceType) map[string]int {
+	filtered := map[string]int{}
+	requests := resources.Merge(e.podRequests, e.minDaemonRequests)
+	for _, it := range instanceTypes {
+		for key, requirement := range e.requirements {
+			if scheduling.NewRequirements(requirement).Intersects(it.Requirements) != nil {
+				filtered[key]++
+			}
+		}
+		if !resources.Fits(requests, it.Allocatable()) {
+			filtered["resources"]++
+		}
+		if !it.Offerings.Available().HasCompatible(e.requirements) {
+			filtered["offerings"]++
+		}
+	}
+	return filtered
+}
@@ -30,6 +30,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -50,6 +51,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
+	"sigs.k8s.io/karpenter/pkg/state/prediction"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
@@ -65,6 +67,7 @@ var (
 	daemonsetController *informer.DaemonSetController
 	cloudProvider       *fake.CloudProvider
 	prov                *provisioning.Provisioner
+	predictionStore     *prediction.Store
 	env                 *test.Environment
 	instanceTypeMap     map[string]*cloudprovider.InstanceType
 )
@@ -81,7 +84,8 @@ var _ = BeforeSuite(func() {
 	cloudProvider = fake.NewCloudProvider()
 	cluster = state.NewCluster(env.Clock, env.Client, cloudProvider)
 	nodeController = informer.NewNodeController(env.Client, cluster)
-	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, env.Clock, deviceallocation.NewController(env.Client), virtualpods.NewVirtualPodCache(env.Client))
+	predictionStore = prediction.NewStore()
+	prov = provisioning.NewProvisioner(env.Client, events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, env.Clock, deviceallocation.NewController(env.Client), virtualpods.NewVirtualPodCache(env.Client), predictionStore)
 	daemonsetController = informer.NewDaemonSetController(env.Client, cluster)
 	instanceTypes, _ := cloudProvider.GetInstanceTypes(ctx, nil)
 	instanceTypeMap = map[string]*cloudprovider.InstanceType{}
@@ -111,6 +115,7 @@ var _ = AfterEach(func() {
 	ExpectCleanedUp(ctx, env.Client)
 	cloudProvider.Reset()
 	cluster.Reset()
+	predictionStore.Reset()
 	pscheduling.IgnoredPodCount.Set(0, nil)
 })
 
@@ -1524,6 +1529,73 @@ var _ = Describe("Provisioning", func() {
 			Expect(*allocatable.Memory()).To(Equal(resource.MustParse("4Gi")))
 		})
 	})
+	Context("Resource Prediction", func() {
+		var rs *appsv1.ReplicaSet
+		var pod *corev1.Pod
+		BeforeEach(func() {
+			rs = test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			pod = test.UnschedulablePod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "apps/v1",
+						Kind:       "ReplicaSet",
+						Name:       rs.Name,
+						UID:        rs.UID,
+						Controller: lo.ToPtr(true),
+					}},
+				},
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+			})
+		})
+		It("should size pods by their workload's prediction when the feature gate is enabled", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{ResourcePrediction: lo.ToPtr(true)}}))
+			predictionStore.Set(types.NamespacedName{Namespace: rs.Namespace, Name: "vpa"}, rs.UID, &prediction.Prediction{Containers: map[string]corev1.ResourceList{
+				pod.Spec.Containers[0].Name: {corev1.ResourceCPU: resource.MustParse("10000")},
+			}}, time.Now())
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should publish an event when a prediction changes a pod's requests", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{ResourcePrediction: lo.ToPtr(true)}}))
+			recorder := test.NewEventRecorder()
+			prov := provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, env.Clock, deviceallocation.NewController(env.Client), virtualpods.NewVirtualPodCache(env.Client), predictionStore)
+			predictionStore.Set(types.NamespacedName{Namespace: rs.Namespace, Name: "vpa"}, rs.UID, &prediction.Prediction{Containers: map[string]corev1.ResourceList{
+				pod.Spec.Containers[0].Name: {corev1.ResourceCPU: resource.MustParse("2")},
+			}}, time.Now())
+			prov.ApplyPredictions(ctx, []*corev1.Pod{pod})
+			prov.ApplyPredictions(ctx, []*corev1.Pod{pod})
+			Expect(recorder.Calls(events.PredictedRequests)).To(Equal(1))
+		})
+		It("should resolve predictions that target the ReplicaSet's controller", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{ResourcePrediction: lo.ToPtr(true)}}))
+			deployment := test.Deployment()
+			ExpectApplied(ctx, env.Client, deployment)
+			rs.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       deployment.Name,
+				UID:        deployment.UID,
+				Controller: lo.ToPtr(true),
+			}}
+			ExpectApplied(ctx, env.Client, rs)
+			predictionStore.Set(types.NamespacedName{Namespace: rs.Namespace, Name: "vpa"}, deployment.UID, &prediction.Prediction{Containers: map[string]corev1.ResourceList{
+				pod.Spec.Containers[0].Name: {corev1.ResourceCPU: resource.MustParse("10000")},
+			}}, time.Now())
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should ignore predictions when the feature gate is disabled", func() {
+			predictionStore.Set(types.NamespacedName{Namespace: rs.Namespace, Name: "vpa"}, rs.UID, &prediction.Prediction{Containers: map[string]corev1.ResourceList{
+				pod.Spec.Containers[0].Name: {corev1.ResourceCPU: resource.MustParse("10000")},
+			}}, time.Now())
+			ExpectApplied(ctx, env.Client, test.NodePool())
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+		})
+	})
 	Context("Annotations", func() {
 		It("should annotate nodes", func() {
 			nodePool := test.NodePool(v1.NodePool{
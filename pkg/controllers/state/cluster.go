@@ -652,6 +652,8 @@ func (c *Cluster) Reset() {
 	c.podAcks = sync.Map{}
 	c.podsSchedulingAttempted = sync.Map{}
 	c.podsSchedulableTimes = sync.Map{}
+	c.podHealthyNodePoolScheduledTime = sync.Map{}
+	c.podToNodeClaim = sync.Map{}
 	c.bufferPodCounts = map[string]int{}
 }
 
@@ -772,6 +774,10 @@ func (c *Cluster) newStateFromNode(ctx context.Context, node *corev1.Node, oldNo
 	); err != nil {
 		return nil, err
 	}
+	// The node's pods are listed again whenever the node changes, so its pod revision is only advanced if they changed
+	if sets.KeySet(n.podRequests).Equal(sets.KeySet(oldNode.podRequests)) {
+		n.podRevision = oldNode.podRevision
+	}
 	// Cleanup the old node with its old providerID if its providerID changes
 	// This can happen since nodes don't get created with providerIDs. Rather, CCM picks up the
 	// created node and injects the providerID into the spec.providerID
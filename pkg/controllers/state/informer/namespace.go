@@ -0,0 +1,92 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
+)
+
+// NamespaceController refreshes the cluster state of the pods in a namespace when the namespace's default
+// disruption cost changes, since the per-pod disruption costs tracked on state nodes are derived from it.
+type NamespaceController struct {
+	kubeClient client.Client
+	cluster    *state.Cluster
+}
+
+func NewNamespaceController(kubeClient client.Client, cluster *state.Cluster) *NamespaceController {
+	return &NamespaceController{
+		kubeClient: kubeClient,
+		cluster:    cluster,
+	}
+}
+
+func (c *NamespaceController) Name() string {
+	return "state.namespace"
+}
+
+func (c *NamespaceController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, c.Name())
+
+	pods := &corev1.PodList{}
+	if err := c.kubeClient.List(ctx, pods, client.InNamespace(req.Name)); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range pods.Items {
+		if err := c.cluster.UpdatePod(ctx, &pods.Items[i]); client.IgnoreNotFound(err) != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *NamespaceController) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named(c.Name()).
+		For(&corev1.Namespace{}).
+		// Pods are already tracked when they're created, so we only need to refresh them when the namespace's
+		// disruption cost annotation changes
+		WithEventFilter(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return false
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return e.ObjectOld.GetAnnotations()[v1.DisruptionCostAnnotationKey] != e.ObjectNew.GetAnnotations()[v1.DisruptionCostAnnotationKey]
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return false
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return false
+			},
+		}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: utilscontroller.LinearScaleReconciles(utilscontroller.CPUCount(ctx), minReconciles, maxReconciles)}).
+		Complete(c)
+}
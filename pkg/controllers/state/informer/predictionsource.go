@@ -0,0 +1,95 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/awslabs/operatorpkg/reconciler"
+	"github.com/awslabs/operatorpkg/singleton"
+	"k8s.io/apimachinery/pkg/types"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/state/prediction"
+)
+
+// PredictionSourceController periodically polls a prediction.Source and syncs its recommendations into the
+// prediction store. Recommendations that the source stops reporting are removed from the store. The store isn't
+// hydrated until every source controller has completed its first poll.
+type PredictionSourceController struct {
+	source prediction.Source
+	store  *prediction.Store
+	// lastSeen tracks the store keys written on the previous poll so that stale entries can be deleted.
+	lastSeen map[types.NamespacedName]struct{}
+}
+
+func NewPredictionSourceController(source prediction.Source, store *prediction.Store) *PredictionSourceController {
+	c := &PredictionSourceController{
+		source:   source,
+		store:    store,
+		lastSeen: map[types.NamespacedName]struct{}{},
+	}
+	store.RegisterSource(c.Name())
+	return c
+}
+
+func (c *PredictionSourceController) Reconcile(ctx context.Context) (reconciler.Result, error) {
+	ctx = injection.WithControllerName(ctx, c.Name())
+
+	recommendations, err := c.source.List(ctx)
+	if err != nil {
+		return reconciler.Result{}, fmt.Errorf("listing recommendations, %w", err)
+	}
+	seen := make(map[types.NamespacedName]struct{}, len(recommendations))
+	for _, r := range recommendations {
+		if r.Prediction == nil {
+			continue
+		}
+		key := c.key(r.Source)
+		c.store.Set(key, r.Target, r.Prediction, r.CreatedAt)
+		seen[key] = struct{}{}
+	}
+	for key := range c.lastSeen {
+		if _, ok := seen[key]; !ok {
+			c.store.Delete(key)
+		}
+	}
+	c.lastSeen = seen
+	c.store.MarkHydrated(c.Name())
+	return reconciler.Result{RequeueAfter: 30 * time.Second}, nil
+}
+
+// key scopes the source object's name by the source so that sources can't overwrite each other's entries
+// in the store, e.g. when a VPA and a ConfigMap share a namespace and name.
+func (c *PredictionSourceController) key(source types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{Namespace: source.Namespace, Name: fmt.Sprintf("%s/%s", c.source.Name(), source.Name)}
+}
+
+func (c *PredictionSourceController) Name() string {
+	return fmt.Sprintf("%s.prediction", c.source.Name())
+}
+
+func (c *PredictionSourceController) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named(c.Name()).
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(c))
+}
@@ -0,0 +1,122 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/state/prediction"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+type fakePredictionSource struct {
+	recommendations []prediction.Recommendation
+	err             error
+}
+
+func (s *fakePredictionSource) Name() string {
+	return "fake"
+}
+
+func (s *fakePredictionSource) List(_ context.Context) ([]prediction.Recommendation, error) {
+	return s.recommendations, s.err
+}
+
+var _ = Describe("Prediction Source Controller", func() {
+	var source *fakePredictionSource
+	var sourceController *informer.PredictionSourceController
+
+	BeforeEach(func() {
+		source = &fakePredictionSource{}
+		sourceController = informer.NewPredictionSourceController(source, store)
+	})
+
+	recommendation := func(name string, target types.UID, cpu string, createdAt time.Time) prediction.Recommendation {
+		return prediction.Recommendation{
+			Source: types.NamespacedName{Namespace: "default", Name: name},
+			Target: target,
+			Prediction: &prediction.Prediction{Containers: map[string]corev1.ResourceList{
+				"app": {corev1.ResourceCPU: resource.MustParse(cpu)},
+			}},
+			CreatedAt: createdAt,
+		}
+	}
+
+	It("should store recommendations from the source", func() {
+		source.recommendations = []prediction.Recommendation{recommendation("app", targetUID, "500m", time.Now())}
+		ExpectSingletonReconciled(ctx, sourceController)
+
+		pred, ok := store.Get(targetUID)
+		Expect(ok).To(BeTrue())
+		Expect(pred.Containers["app"]).To(HaveKeyWithValue(corev1.ResourceCPU, resource.MustParse("500m")))
+	})
+	It("should remove recommendations the source no longer reports", func() {
+		source.recommendations = []prediction.Recommendation{recommendation("app", targetUID, "500m", time.Now())}
+		ExpectSingletonReconciled(ctx, sourceController)
+		source.recommendations = nil
+		ExpectSingletonReconciled(ctx, sourceController)
+
+		_, ok := store.Get(targetUID)
+		Expect(ok).To(BeFalse())
+	})
+	It("should retain recommendations when the source fails to list", func() {
+		source.recommendations = []prediction.Recommendation{recommendation("app", targetUID, "500m", time.Now())}
+		ExpectSingletonReconciled(ctx, sourceController)
+		source.err = fmt.Errorf("unavailable")
+		_, err := sourceController.Reconcile(ctx)
+		Expect(err).To(HaveOccurred())
+
+		_, ok := store.Get(targetUID)
+		Expect(ok).To(BeTrue())
+	})
+	It("should hydrate the store once the source and the VPA controller have synced", func() {
+		checkCtx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
+		source.err = fmt.Errorf("unavailable")
+		_, err := sourceController.Reconcile(ctx)
+		Expect(err).To(HaveOccurred())
+		ExpectSingletonReconciled(ctx, controller)
+		Expect(store.Hydrated(checkCtx)).To(BeFalse())
+
+		source.err = nil
+		ExpectSingletonReconciled(ctx, sourceController)
+		Expect(store.Hydrated(ctx)).To(BeTrue())
+	})
+	It("should not overwrite a VPA recommendation with the same namespace and name", func() {
+		vpaKey := types.NamespacedName{Namespace: "default", Name: "app"}
+		store.Set(vpaKey, targetUID, &prediction.Prediction{Containers: map[string]corev1.ResourceList{
+			"app": {corev1.ResourceCPU: resource.MustParse("1")},
+		}}, time.Now().Add(-time.Hour))
+		source.recommendations = []prediction.Recommendation{recommendation("app", targetUID, "500m", time.Now())}
+		ExpectSingletonReconciled(ctx, sourceController)
+		source.recommendations = nil
+		ExpectSingletonReconciled(ctx, sourceController)
+
+		pred, ok := store.Get(targetUID)
+		Expect(ok).To(BeTrue())
+		Expect(pred.Containers["app"]).To(HaveKeyWithValue(corev1.ResourceCPU, resource.MustParse("1")))
+	})
+})
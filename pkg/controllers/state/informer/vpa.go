@@ -56,12 +56,14 @@ type VPAController struct {
 
 func NewVPAController(kubeClient client.Client, apiReader client.Reader, store *prediction.Store) *VPAController {
 	utilruntime.Must(vpav1.AddToScheme(scheme.Scheme))
-	return &VPAController{
+	c := &VPAController{
 		kubeClient: kubeClient,
 		apiReader:  apiReader,
 		store:      store,
 		lastSeen:   make(map[types.NamespacedName]string),
 	}
+	store.RegisterSource(c.Name())
+	return c
 }
 
 func (c *VPAController) Reconcile(ctx context.Context) (reconciler.Result, error) {
@@ -70,9 +72,12 @@ func (c *VPAController) Reconcile(ctx context.Context) (reconciler.Result, error
 	var vpaList vpav1.VerticalPodAutoscalerList
 	if err := c.kubeClient.List(ctx, &vpaList); err != nil {
 		if meta.IsNoMatchError(err) {
-			c.store.Reset()
+			// Only remove the entries written by this controller, other prediction sources share the store
+			for key := range c.lastSeen {
+				c.store.Delete(key)
+			}
 			c.lastSeen = make(map[types.NamespacedName]string)
-			c.store.MarkHydrated()
+			c.store.MarkHydrated(c.Name())
 			return reconciler.Result{RequeueAfter: 1 * time.Minute}, nil
 		}
 		return reconciler.Result{}, err
@@ -102,7 +107,7 @@ func (c *VPAController) Reconcile(ctx context.Context) (reconciler.Result, error
 	}
 
 	if allResolved {
-		c.store.MarkHydrated()
+		c.store.MarkHydrated(c.Name())
 	}
 
 	return reconciler.Result{RequeueAfter: 30 * time.Second}, nil
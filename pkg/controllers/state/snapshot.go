@@ -0,0 +1,313 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+// SnapshotVersion is the version of the Snapshot format that Export writes and Import reads. It must be bumped on any
+// change to the format that older versions of Import can't read correctly.
+const SnapshotVersion = "v1"
+
+// Snapshot is a serializable copy of the Cluster's state. Resource usage, host ports, volume usage, and disruption costs
+// aren't stored, they're recomputed from the pods when the snapshot is imported.
+type Snapshot struct {
+	Version string `json:"version"`
+	// Time is the cluster clock's time when the snapshot was exported. Timestamps in the snapshot, such as nominations,
+	// are only meaningful relative to it, so Import shifts them by the time between it and the importing cluster's clock.
+	Time  time.Time      `json:"time"`
+	Nodes []SnapshotNode `json:"nodes,omitempty"`
+	// UnlaunchedNodeClaims are the NodeClaims that the cluster is tracking which don't have a provider ID yet
+	UnlaunchedNodeClaims []*v1.NodeClaim `json:"unlaunchedNodeClaims,omitempty"`
+	// Pods are the pods that are bound to the nodes
+	Pods []*corev1.Pod `json:"pods,omitempty"`
+	// DaemonSetPods are the newest pod for each DaemonSet, keyed by the DaemonSet's namespace and name
+	DaemonSetPods map[string]*corev1.Pod `json:"daemonSetPods,omitempty"`
+	// ConsolidationState is the last time that the cluster changed in a way that might make consolidation possible
+	ConsolidationState time.Time `json:"consolidationState"`
+	// BufferPodCounts is the number of CapacityBuffer pods placed on each node, keyed by provider ID
+	BufferPodCounts map[string]int `json:"bufferPodCounts,omitempty"`
+	// Pod scheduling timestamps and mappings, keyed by the pod's namespace and name
+	PodAcks                          map[string]time.Time `json:"podAcks,omitempty"`
+	PodsSchedulingAttempted          map[string]time.Time `json:"podsSchedulingAttempted,omitempty"`
+	PodsSchedulableTimes             map[string]time.Time `json:"podsSchedulableTimes,omitempty"`
+	PodHealthyNodePoolScheduledTimes map[string]time.Time `json:"podHealthyNodePoolScheduledTimes,omitempty"`
+	PodToNodeClaim                   map[string]string    `json:"podToNodeClaim,omitempty"`
+}
+
+// SnapshotNode is a serializable copy of a StateNode
+type SnapshotNode struct {
+	Node              *corev1.Node  `json:"node,omitempty"`
+	NodeClaim         *v1.NodeClaim `json:"nodeClaim,omitempty"`
+	MarkedForDeletion bool          `json:"markedForDeletion,omitempty"`
+	NominatedUntil    time.Time     `json:"nominatedUntil,omitzero"`
+}
+
+// Export copies the cluster's state into a Snapshot. Only pod bindings are tracked in the cluster's state, so the bound
+// pods and unlaunched NodeClaims are read through the kube client. Objects that have been deleted since they were
+// tracked are left out. The cluster is locked for the whole export so that the snapshot is consistent.
+func (c *Cluster) Export(ctx context.Context) (*Snapshot, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	snapshot := &Snapshot{
+		Version:                          SnapshotVersion,
+		Time:                             c.clock.Now(),
+		DaemonSetPods:                    map[string]*corev1.Pod{},
+		PodAcks:                          exportTimes(&c.podAcks),
+		PodsSchedulingAttempted:          exportTimes(&c.podsSchedulingAttempted),
+		PodsSchedulableTimes:             exportTimes(&c.podsSchedulableTimes),
+		PodHealthyNodePoolScheduledTimes: exportTimes(&c.podHealthyNodePoolScheduledTime),
+		PodToNodeClaim:                   map[string]string{},
+	}
+	for _, n := range c.nodes {
+		snapshot.Nodes = append(snapshot.Nodes, SnapshotNode{
+			Node:              n.Node.DeepCopy(),
+			NodeClaim:         n.NodeClaim.DeepCopy(),
+			MarkedForDeletion: n.markedForDeletion,
+			NominatedUntil:    n.nominatedUntil.Time,
+		})
+	}
+	podKeys := lo.Keys(c.bindings)
+	unlaunched := lo.Keys(lo.PickByValues(c.nodeClaimNameToProviderID, []string{""}))
+
+	// Sort the snapshot so that exports of the same state are identical
+	sort.Slice(snapshot.Nodes, func(i, j int) bool {
+		return snapshot.Nodes[i].providerID() < snapshot.Nodes[j].providerID()
+	})
+	sort.Slice(podKeys, func(i, j int) bool { return podKeys[i].String() < podKeys[j].String() })
+	sort.Strings(unlaunched)
+
+	for _, key := range podKeys {
+		pod := &corev1.Pod{}
+		if err := c.kubeClient.Get(ctx, key, pod); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("getting pod, %w", err)
+		}
+		snapshot.Pods = append(snapshot.Pods, pod)
+	}
+	for _, name := range unlaunched {
+		nodeClaim := &v1.NodeClaim{}
+		if err := c.kubeClient.Get(ctx, types.NamespacedName{Name: name}, nodeClaim); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("getting nodeclaim, %w", err)
+		}
+		snapshot.UnlaunchedNodeClaims = append(snapshot.UnlaunchedNodeClaims, nodeClaim)
+	}
+	c.daemonSetPods.Range(func(k, v any) bool {
+		snapshot.DaemonSetPods[k.(types.NamespacedName).String()] = v.(*corev1.Pod).DeepCopy()
+		return true
+	})
+	c.podToNodeClaim.Range(func(k, v any) bool {
+		snapshot.PodToNodeClaim[k.(types.NamespacedName).String()] = v.(string)
+		return true
+	})
+	c.clusterStateMu.RLock()
+	snapshot.ConsolidationState = c.clusterState
+	c.clusterStateMu.RUnlock()
+	c.bufferPodCountsMu.RLock()
+	snapshot.BufferPodCounts = lo.Assign(c.bufferPodCounts)
+	c.bufferPodCountsMu.RUnlock()
+	return snapshot, nil
+}
+
+// Import replaces the cluster's state with the state in the snapshot. Volume usage and disruption costs are computed
+// from the objects that the cluster's kube client can read, so the client should contain the snapshot's objects along
+// with the PersistentVolumeClaims and Namespaces that they reference. The snapshot's timestamps are shifted by the time
+// between the snapshot's Time and the cluster's clock, so that nominations and pod timestamps are as old as they were
+// when the snapshot was exported.
+func (c *Cluster) Import(ctx context.Context, snapshot *Snapshot) error {
+	if snapshot.Version != SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %q, expected %q", snapshot.Version, SnapshotVersion)
+	}
+	var offset time.Duration
+	if !snapshot.Time.IsZero() {
+		offset = c.clock.Now().Sub(snapshot.Time)
+	}
+	c.Reset()
+	for _, n := range snapshot.Nodes {
+		if n.NodeClaim != nil {
+			c.UpdateNodeClaim(n.NodeClaim.DeepCopy())
+		}
+		if n.Node != nil {
+			if err := c.UpdateNode(ctx, n.Node.DeepCopy()); err != nil {
+				return fmt.Errorf("importing node, %w", err)
+			}
+		}
+	}
+	for _, nodeClaim := range snapshot.UnlaunchedNodeClaims {
+		c.UpdateNodeClaim(nodeClaim.DeepCopy())
+	}
+	for _, pod := range snapshot.Pods {
+		if err := c.UpdatePod(ctx, pod.DeepCopy()); err != nil {
+			return fmt.Errorf("importing pod, %w", err)
+		}
+	}
+	c.MarkForDeletion(lo.FilterMap(snapshot.Nodes, func(n SnapshotNode, _ int) (string, bool) {
+		return n.providerID(), n.MarkedForDeletion
+	})...)
+	c.mu.Lock()
+	for _, n := range snapshot.Nodes {
+		if sn, ok := c.nodes[n.providerID()]; ok {
+			sn.nominatedUntil.Time = rebase(n.NominatedUntil, offset)
+		}
+	}
+	c.mu.Unlock()
+
+	for key, pod := range snapshot.DaemonSetPods {
+		c.daemonSetPods.Store(parseNamespacedName(key), pod.DeepCopy())
+	}
+	importTimes(&c.podAcks, snapshot.PodAcks, offset)
+	importTimes(&c.podsSchedulingAttempted, snapshot.PodsSchedulingAttempted, offset)
+	importTimes(&c.podsSchedulableTimes, snapshot.PodsSchedulableTimes, offset)
+	importTimes(&c.podHealthyNodePoolScheduledTime, snapshot.PodHealthyNodePoolScheduledTimes, offset)
+	for key, nodeClaimName := range snapshot.PodToNodeClaim {
+		c.podToNodeClaim.Store(parseNamespacedName(key), nodeClaimName)
+	}
+	c.UpdateBufferPodCounts(lo.Assign(snapshot.BufferPodCounts))
+	c.clusterStateMu.Lock()
+	c.clusterState = rebase(snapshot.ConsolidationState, offset)
+	c.clusterStateMu.Unlock()
+	return nil
+}
+
+// NewDebugHandler returns a handler that serves an export of the cluster's state as JSON. Pod environment variable
+// values and last-applied-configuration annotations are redacted, since they can contain secrets.
+func NewDebugHandler(cluster *Cluster) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snapshot, err := cluster.Export(r.Context())
+		if err != nil {
+			log.FromContext(r.Context()).Error(err, "failed exporting cluster state")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		snapshot.redact()
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+			log.FromContext(r.Context()).Error(err, "failed writing cluster state")
+		}
+	})
+}
+
+// redact removes the fields of the snapshot's objects that can contain secrets
+func (s *Snapshot) redact() {
+	var objects []metav1.Object
+	for _, pod := range append(s.Pods, lo.Values(s.DaemonSetPods)...) {
+		for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+			for i := range containers {
+				redactEnv(containers[i].Env)
+			}
+		}
+		for i := range pod.Spec.EphemeralContainers {
+			redactEnv(pod.Spec.EphemeralContainers[i].Env)
+		}
+		objects = append(objects, pod)
+	}
+	for _, n := range s.Nodes {
+		if n.Node != nil {
+			objects = append(objects, n.Node)
+		}
+		if n.NodeClaim != nil {
+			objects = append(objects, n.NodeClaim)
+		}
+	}
+	for _, nodeClaim := range s.UnlaunchedNodeClaims {
+		objects = append(objects, nodeClaim)
+	}
+	for _, o := range objects {
+		if _, ok := o.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; ok {
+			annotations := lo.Assign(o.GetAnnotations())
+			annotations[corev1.LastAppliedConfigAnnotation] = redacted
+			o.SetAnnotations(annotations)
+		}
+	}
+}
+
+const redacted = "REDACTED"
+
+func redactEnv(env []corev1.EnvVar) {
+	for i := range env {
+		if env[i].Value != "" {
+			env[i].Value = redacted
+		}
+	}
+}
+
+func (n SnapshotNode) providerID() string {
+	if n.NodeClaim != nil && n.NodeClaim.Status.ProviderID != "" {
+		return n.NodeClaim.Status.ProviderID
+	}
+	if n.Node != nil {
+		// Unmanaged nodes without a provider ID are tracked by their name
+		return lo.Ternary(n.Node.Spec.ProviderID != "", n.Node.Spec.ProviderID, n.Node.Name)
+	}
+	return ""
+}
+
+func exportTimes(m *sync.Map) map[string]time.Time {
+	times := map[string]time.Time{}
+	m.Range(func(k, v any) bool {
+		times[k.(types.NamespacedName).String()] = v.(time.Time)
+		return true
+	})
+	return times
+}
+
+func importTimes(m *sync.Map, times map[string]time.Time, offset time.Duration) {
+	for key, t := range times {
+		m.Store(parseNamespacedName(key), rebase(t, offset))
+	}
+}
+
+// rebase shifts a timestamp from the snapshot by the offset between the snapshot's time and the cluster's clock,
+// leaving unset timestamps unset
+func rebase(t time.Time, offset time.Duration) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.Add(offset)
+}
+
+// parseNamespacedName parses the string form of a types.NamespacedName
+func parseNamespacedName(key string) types.NamespacedName {
+	namespace, name, ok := strings.Cut(key, string(types.Separator))
+	if !ok {
+		return types.NamespacedName{Name: key}
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	stderrors "errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/awslabs/operatorpkg/serrors"
@@ -50,6 +51,10 @@ import (
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
+// podRevisions is the sequence of pod revisions across all nodes, so that a node's revision never repeats even when its
+// state is rebuilt
+var podRevisions atomic.Uint64
+
 type PodBlockEvictionError struct {
 	error
 }
@@ -138,6 +143,8 @@ type StateNode struct {
 
 	hostPortUsage *scheduling.HostPortUsage
 	volumeUsage   *scheduling.VolumeUsage
+	// podRevision changes whenever the pods that are bound to the node change, see PodRevision
+	podRevision uint64
 
 	// TODO remove this when v1alpha5 APIs are deprecated. With v1 APIs Karpenter relies on the existence
 	// of the karpenter.sh/disruption taint to know when a node is marked for deletion.
@@ -168,6 +175,7 @@ func (in *StateNode) ShallowCopy() *StateNode {
 		podDisruptionCosts: in.podDisruptionCosts,
 		hostPortUsage:      in.hostPortUsage,
 		volumeUsage:        in.volumeUsage,
+		podRevision:        in.podRevision,
 		markedForDeletion:  in.markedForDeletion,
 		nominatedUntil:     in.nominatedUntil,
 	}
@@ -196,6 +204,12 @@ func (in *StateNode) ProviderID() string {
 	return in.Node.Spec.ProviderID
 }
 
+// PodRevision returns a revision of the pods that are bound to the node, which changes whenever a pod is bound to the
+// node, is updated, or leaves it. This allows callers to cache data that is derived from the node's pods.
+func (in *StateNode) PodRevision() uint64 {
+	return in.podRevision
+}
+
 // Pods gets the pods assigned to the Node based on the kubernetes api-server bindings
 func (in *StateNode) Pods(ctx context.Context, kubeClient client.Client) ([]*corev1.Pod, error) {
 	if in.Node == nil {
@@ -424,6 +438,11 @@ func (in *StateNode) PodLimits() corev1.ResourceList {
 	return resources.Merge(lo.Values(in.podLimits)...)
 }
 
+// PodCount returns the number of non-DaemonSet pods bound to the node
+func (in *StateNode) PodCount() int {
+	return len(in.podRequests) - len(in.daemonSetRequests)
+}
+
 // DisruptionCost returns the exact disruption cost for this node:
 // PerNodeBaseDisruptionCost (1.0) + sum of positive per-pod eviction costs.
 // This is maintained incrementally as pods are added/removed.
@@ -480,7 +499,11 @@ func (in *StateNode) updateForPod(ctx context.Context, kubeClient client.Client,
 		if in.podDisruptionCosts == nil {
 			in.podDisruptionCosts = map[types.NamespacedName]float64{}
 		}
-		if evictionCost := disruptionutils.EvictionCost(ctx, pod); evictionCost > 0 {
+		namespaceCosts, err := disruptionutils.GetNamespaceCosts(ctx, kubeClient, pod)
+		if err != nil {
+			return fmt.Errorf("tracking disruption cost, %w", err)
+		}
+		if evictionCost := disruptionutils.EvictionCost(ctx, pod, namespaceCosts); evictionCost > 0 {
 			in.podDisruptionCosts[podKey] = evictionCost
 		} else {
 			delete(in.podDisruptionCosts, podKey)
@@ -488,10 +511,12 @@ func (in *StateNode) updateForPod(ctx context.Context, kubeClient client.Client,
 	}
 	in.hostPortUsage.Add(pod, hostPorts)
 	in.volumeUsage.Add(pod, volumes)
+	in.podRevision = podRevisions.Add(1)
 	return nil
 }
 
 func (in *StateNode) cleanupForPod(podKey types.NamespacedName) {
+	in.podRevision = podRevisions.Add(1)
 	in.hostPortUsage.DeletePod(podKey)
 	in.volumeUsage.DeletePod(podKey)
 	delete(in.podRequests, podKey)
@@ -18,9 +18,12 @@ package state_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
@@ -60,6 +63,7 @@ var clusterCost *cost.ClusterCost
 var nodeClaimController *informer.NodeClaimController
 var nodeController *informer.NodeController
 var podController *informer.PodController
+var namespaceController *informer.NamespaceController
 var nodePoolController *informer.NodePoolController
 var daemonsetController *informer.DaemonSetController
 var nodeOverlayStore *nodeoverlay.InstanceTypeStore
@@ -87,6 +91,7 @@ var _ = BeforeSuite(func() {
 	nodeClaimController = informer.NewNodeClaimController(env.Client, cloudProvider, cluster, clusterCost)
 	nodeController = informer.NewNodeController(env.Client, cluster)
 	podController = informer.NewPodController(env.Client, cluster)
+	namespaceController = informer.NewNamespaceController(env.Client, cluster)
 	nodePoolController = informer.NewNodePoolController(env.Client, cloudProvider, cluster, clusterCost)
 	nodeOverlayStore = nodeoverlay.NewInstanceTypeStore()
 	nodeOverlayController = nodeoverlay.NewController(env.Clock, env.Client, cloudProvider, nodeOverlayStore, cluster)
@@ -466,6 +471,30 @@ var _ = Describe("Node Deletion", func() {
 	})
 })
 
+var _ = Describe("Namespace Disruption Cost", func() {
+	It("should refresh pod disruption costs when the namespace disruption cost changes", func() {
+		namespace := test.Namespace()
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name}})
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1.NodePoolLabelKey:            nodePool.Name,
+				corev1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, namespace, pod, node)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+		Expect(ExpectStateNodeExists(cluster, node).DisruptionCost()).To(BeNumerically("==", 2))
+
+		namespace.Annotations = map[string]string{v1.DisruptionCostAnnotationKey: "5"}
+		ExpectApplied(ctx, env.Client, namespace)
+		ExpectReconcileSucceeded(ctx, namespaceController, client.ObjectKeyFromObject(namespace))
+		Expect(ExpectStateNodeExists(cluster, node).DisruptionCost()).To(BeNumerically("==", 6))
+	})
+})
+
 var _ = Describe("Node Resource Level", func() {
 	It("should not count pods not bound to nodes", func() {
 		pod1 := test.UnschedulablePod(test.PodOptions{
@@ -3041,3 +3070,123 @@ var _ = Describe("Buffer Pod Counts", func() {
 		Expect(cluster.HasBufferPods("provider-b")).To(BeTrue())
 	})
 })
+
+var _ = Describe("Snapshot", func() {
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+	var pod *corev1.Pod
+
+	BeforeEach(func() {
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+				},
+			},
+			Status: v1.NodeClaimStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			},
+		})
+		pod = test.Pod(test.PodOptions{
+			NodeName:             node.Name,
+			ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim, node, pod)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod))
+	})
+
+	It("should import the state that was exported", func() {
+		cluster.NominateNodeForPod(ctx, node.Spec.ProviderID)
+		cluster.MarkForDeletion(node.Spec.ProviderID)
+		cluster.AckPods(pod)
+		cluster.UpdateBufferPodCounts(map[string]int{node.Spec.ProviderID: 2})
+		consolidationState := cluster.MarkUnconsolidated()
+
+		snapshot, err := cluster.Export(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshot.Version).To(Equal(state.SnapshotVersion))
+		Expect(snapshot.Nodes).To(HaveLen(1))
+		Expect(snapshot.Pods).To(HaveLen(1))
+
+		// Round trip the snapshot through its serialized format
+		raw, err := json.Marshal(snapshot)
+		Expect(err).ToNot(HaveOccurred())
+		snapshot = &state.Snapshot{}
+		Expect(json.Unmarshal(raw, snapshot)).To(Succeed())
+
+		ExpectCleanedUp(ctx, env.Client)
+		cluster.Reset()
+		ExpectStateNodeCount("==", 0)
+
+		ExpectStateImported(ctx, env.Client, cluster, snapshot)
+		stateNode := ExpectStateNodeExists(cluster, node)
+		Expect(stateNode.NodeClaim.Name).To(Equal(nodeClaim.Name))
+		Expect(stateNode.PodRequests()).To(HaveKeyWithValue(corev1.ResourceCPU, resource.MustParse("1")))
+		Expect(stateNode.MarkedForDeletion()).To(BeTrue())
+		Expect(cluster.IsNodeNominated(node.Spec.ProviderID)).To(BeTrue())
+		Expect(cluster.PodAckTime(client.ObjectKeyFromObject(pod))).To(BeTemporally("==", env.Clock.Now()))
+		Expect(cluster.BufferPodCount(node.Spec.ProviderID)).To(Equal(2))
+		Expect(cluster.ConsolidationState()).To(BeTemporally("==", consolidationState))
+		ExpectStateNodePoolCount(cluster, nodePool.Name, 0, 1, 0)
+	})
+	It("should shift the snapshot's timestamps to the importing cluster's clock", func() {
+		cluster.NominateNodeForPod(ctx, node.Spec.ProviderID)
+		cluster.AckPods(pod)
+		ackTime := env.Clock.Now()
+
+		snapshot, err := cluster.Export(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		cluster.Reset()
+		// Import the snapshot an hour after it was exported, which would have expired the nomination if the timestamps
+		// weren't shifted
+		env.Clock.Step(time.Hour)
+		ExpectStateImported(ctx, env.Client, cluster, snapshot)
+		Expect(cluster.IsNodeNominated(node.Spec.ProviderID)).To(BeTrue())
+		Expect(cluster.PodAckTime(client.ObjectKeyFromObject(pod))).To(BeTemporally("==", ackTime.Add(time.Hour)))
+	})
+	It("should not import a snapshot with an unsupported version", func() {
+		snapshot, err := cluster.Export(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		snapshot.Version = "v0"
+		Expect(cluster.Import(ctx, snapshot)).ToNot(Succeed())
+		ExpectStateNodeExists(cluster, node)
+	})
+	It("should leave out pods that have been deleted since they were tracked", func() {
+		ExpectDeleted(ctx, env.Client, pod)
+		snapshot, err := cluster.Export(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshot.Pods).To(BeEmpty())
+	})
+	It("should serve the exported state from the debug handler", func() {
+		recorder := httptest.NewRecorder()
+		state.NewDebugHandler(cluster).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/state", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		snapshot := &state.Snapshot{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), snapshot)).To(Succeed())
+		Expect(snapshot.Version).To(Equal(state.SnapshotVersion))
+		Expect(snapshot.Nodes).To(HaveLen(1))
+		Expect(snapshot.Nodes[0].Node.Name).To(Equal(node.Name))
+	})
+	It("should redact secrets from the debug handler", func() {
+		secretPod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: `{"password":"hunter2"}`}},
+			NodeName:   node.Name,
+		})
+		secretPod.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "PASSWORD", Value: "hunter2"}}
+		ExpectApplied(ctx, env.Client, secretPod)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(secretPod))
+
+		recorder := httptest.NewRecorder()
+		state.NewDebugHandler(cluster).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/state", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).ToNot(ContainSubstring("hunter2"))
+		snapshot := &state.Snapshot{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), snapshot)).To(Succeed())
+		exported, ok := lo.Find(snapshot.Pods, func(p *corev1.Pod) bool { return p.Name == secretPod.Name })
+		Expect(ok).To(BeTrue())
+		Expect(exported.Spec.Containers[0].Env).To(ConsistOf(corev1.EnvVar{Name: "PASSWORD", Value: "REDACTED"}))
+	})
+})
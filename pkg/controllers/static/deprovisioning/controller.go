@@ -271,6 +271,7 @@ func (c *Controller) resolvedDeprovisioningCandidates(ctx context.Context, nodes
 		node            *state.StateNode
 		pods            []*corev1.Pod
 		hasDoNotDisrupt bool
+		disruptionCost  float64
 	}
 
 	emptyNodesSet := sets.New(emptyNodes...)
@@ -284,11 +285,17 @@ func (c *Controller) resolvedDeprovisioningCandidates(ctx context.Context, nodes
 			log.FromContext(ctx).WithValues("node", node.Name()).Error(err, "unable to list pods, skipping node")
 			return NonEmptyNode{}, false
 		}
+		namespaceCosts, err := disruptionutils.GetNamespaceCosts(ctx, c.kubeClient, pods...)
+		if err != nil {
+			log.FromContext(ctx).WithValues("node", node.Name()).Error(err, "unable to get namespace disruption costs, skipping node")
+			return NonEmptyNode{}, false
+		}
 
 		return NonEmptyNode{
 			node:            node,
 			pods:            pods,
 			hasDoNotDisrupt: lo.SomeBy(pods, func(p *corev1.Pod) bool { return pod.IsDoNotDisruptActive(p, c.clock, c.recorder) }),
+			disruptionCost:  disruptionutils.ReschedulingCost(ctx, pods, namespaceCosts) * disruptionutils.LifetimeRemaining(c.clock, np, node.NodeClaim),
 		}, true
 	})
 
@@ -298,10 +305,7 @@ func (c *Controller) resolvedDeprovisioningCandidates(ctx context.Context, nodes
 			return lo.Ternary(i.hasDoNotDisrupt, 1, -1)
 		}
 		// If neither has do-not-disrupt pods, compare their costs
-		return cmp.Compare(
-			disruptionutils.ReschedulingCost(ctx, i.pods)*disruptionutils.LifetimeRemaining(c.clock, np, i.node.NodeClaim),
-			disruptionutils.ReschedulingCost(ctx, j.pods)*disruptionutils.LifetimeRemaining(c.clock, np, j.node.NodeClaim),
-		)
+		return cmp.Compare(i.disruptionCost, j.disruptionCost)
 	})
 
 	// Take the remaining needed nodes with lowest cost
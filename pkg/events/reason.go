@@ -23,6 +23,7 @@ const (
 	DisruptionLaunching        = "DisruptionLaunching"
 	DisruptionTerminating      = "DisruptionTerminating"
 	DisruptionWaitingReadiness = "DisruptionWaitingReadiness"
+	DisruptionDryRun           = "DisruptionDryRun"
 	Unconsolidatable           = "Unconsolidatable"
 	ConsolidationCandidate     = "ConsolidationCandidate"
 	ConsolidationRejected      = "ConsolidationRejected"
@@ -32,6 +33,9 @@ const (
 	FailedScheduling          = "FailedScheduling"
 	NoCompatibleInstanceTypes = "NoCompatibleInstanceTypes"
 	Nominated                 = "Nominated"
+	Preempting                = "Preempting"
+	SchedulingDecision        = "SchedulingDecision"
+	PredictedRequests         = "PredictedRequests"
 
 	// node/health
 	NodeRepairBlocked = "NodeRepairBlocked"
@@ -39,10 +43,14 @@ const (
 	// node/termination/terminator
 	Disrupted                      = "Disrupted"
 	Evicted                        = "Evicted"
+	EvictionBlocked                = "EvictionBlocked"
 	FailedDraining                 = "FailedDraining"
 	TerminationGracePeriodExpiring = "TerminationGracePeriodExpiring"
 	TerminationFailed              = "FailedTermination"
 
+	// nodeclaim/interruption
+	Interrupted = "Interrupted"
+
 	// nodeclaim/consistency
 	FailedConsistencyCheck = "FailedConsistencyCheck"
 
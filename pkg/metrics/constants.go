@@ -39,6 +39,7 @@ const (
 	ProvisionedReason = "provisioned"
 	ExpiredReason     = "expired"
 	UnhealthyReason   = "unhealthy"
+	InterruptedReason = "interrupted"
 
 	// termination_mode label values. Graceful and Eventual are also the canonical
 	// values for the disruption Graceful/Eventual classes in the disruption controller.
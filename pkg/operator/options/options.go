@@ -44,9 +44,42 @@ const (
 	MinValuesPolicyBestEffort MinValuesPolicy = "BestEffort"
 )
 
+type DriftOrdering string
+
+const (
+	// DriftOrderingOldest disrupts the candidates that drifted first
+	DriftOrderingOldest DriftOrdering = "Oldest"
+	// DriftOrderingDisruptionCost disrupts the candidates that are cheapest to disrupt first
+	DriftOrderingDisruptionCost DriftOrdering = "DisruptionCost"
+)
+
+type SchedulingSolver string
+
+const (
+	// SchedulingSolverGreedy places each pod on the first NodeClaim that fits it
+	SchedulingSolverGreedy SchedulingSolver = "Greedy"
+	// SchedulingSolverBestFit repacks the greedy result with a best-fit decreasing heuristic to lower the launch price of the batch
+	SchedulingSolverBestFit SchedulingSolver = "BestFit"
+)
+
+type SchedulingPreemption string
+
+const (
+	// SchedulingPreemptionDisabled fails to schedule pods that don't fit on existing nodes or new capacity
+	SchedulingPreemptionDisabled SchedulingPreemption = "Disabled"
+	// SchedulingPreemptionSimulate places pods that don't fit on existing nodes or new capacity on the existing nodes
+	// where the kube-scheduler can preempt lower priority pods for them
+	SchedulingPreemptionSimulate SchedulingPreemption = "Simulate"
+	// SchedulingPreemptionSimulateAndProvision simulates preemption and provisions capacity for the preempted pods
+	SchedulingPreemptionSimulateAndProvision SchedulingPreemption = "SimulateAndProvision"
+)
+
 var (
 	validLogLevels          = []string{"", "debug", "info", "error"}
 	validPreferencePolicies = []PreferencePolicy{PreferencePolicyIgnore, PreferencePolicyRespect}
+	validDriftOrderings     = []DriftOrdering{DriftOrderingOldest, DriftOrderingDisruptionCost}
+	validSchedulingSolvers  = []SchedulingSolver{SchedulingSolverGreedy, SchedulingSolverBestFit}
+	validPreemptions        = []SchedulingPreemption{SchedulingPreemptionDisabled, SchedulingPreemptionSimulate, SchedulingPreemptionSimulateAndProvision}
 
 	Injectables = []Injectable{&Options{}}
 )
@@ -62,6 +95,9 @@ type FeatureGates struct {
 	NodeOverlay             bool
 	StaticCapacity          bool
 	CapacityBuffer          bool
+	ResourcePrediction      bool
+	DisruptionPlan          bool
+	IncrementalScheduling   bool
 }
 
 // Options contains all CLI flags / env vars for karpenter-core. It adheres to the options.Injectable interface.
@@ -88,6 +124,17 @@ type Options struct {
 	PreferencePolicy                 PreferencePolicy
 	minValuesPolicyRaw               string
 	MinValuesPolicy                  MinValuesPolicy
+	DisruptionDryRun                 bool
+	driftOrderingRaw                 string
+	DriftOrdering                    DriftOrdering
+	DriftCheaperReplacements         bool
+	schedulingSolverRaw              string
+	SchedulingSolver                 SchedulingSolver
+	SchedulingSolverBudget           time.Duration
+	SchedulingDecisionTrace          bool
+	schedulingPreemptionRaw          string
+	SchedulingPreemption             SchedulingPreemption
+	ReservationExpiryLeadTime        time.Duration
 	IgnoreDRARequests                bool // NOTE: This flag will be removed once formal DRA support is GA in Karpenter.
 	FeatureGates                     FeatureGates
 }
@@ -115,7 +162,7 @@ func (o *Options) AddFlags(fs *FlagSet) {
 	fs.IntVar(&o.HealthProbePort, "health-probe-port", env.WithDefaultInt("HEALTH_PROBE_PORT", 8081), "The port the health probe endpoint binds to for reporting controller health")
 	fs.IntVar(&o.KubeClientQPS, "kube-client-qps", env.WithDefaultInt("KUBE_CLIENT_QPS", 200), "The smoothed rate of qps to kube-apiserver")
 	fs.IntVar(&o.KubeClientBurst, "kube-client-burst", env.WithDefaultInt("KUBE_CLIENT_BURST", 300), "The maximum allowed burst of queries to the kube-apiserver")
-	fs.BoolVarWithEnv(&o.EnableProfiling, "enable-profiling", "ENABLE_PROFILING", false, "Enable the profiling on the metric endpoint")
+	fs.BoolVarWithEnv(&o.EnableProfiling, "enable-profiling", "ENABLE_PROFILING", false, "Enable the profiling and cluster state debug endpoints on the metric endpoint")
 	fs.BoolVarWithEnv(&o.DisableControllerWarmup, "disable-controller-warmup", "DISABLE_CONTROLLER_WARMUP", true, "Disable controller warmup which starts controller sources before leader election is won. Controller warmup pre-populates caches and improves leader failover time.")
 	fs.BoolVarWithEnv(&o.DisableLeaderElection, "disable-leader-election", "DISABLE_LEADER_ELECTION", false, "Disable the leader election client before executing the main loop. Disable when running replicated components for high availability is not desired.")
 	fs.BoolVarWithEnv(&o.DisableClusterStateObservability, "disable-cluster-state-observability", "DISABLE_CLUSTER_STATE_OBSERVABILITY", false, "Disable cluster state metrics and events")
@@ -130,8 +177,16 @@ func (o *Options) AddFlags(fs *FlagSet) {
 	fs.DurationVar(&o.BatchIdleDuration, "batch-idle-duration", env.WithDefaultDuration("BATCH_IDLE_DURATION", time.Second), "The maximum amount of time with no new pending pods that if exceeded ends the current batching window. If pods arrive faster than this time, the batching window will be extended up to the maxDuration. If they arrive slower, the pods will be batched separately.")
 	fs.StringVar(&o.preferencePolicyRaw, "preference-policy", env.WithDefaultString("PREFERENCE_POLICY", string(PreferencePolicyRespect)), "How the Karpenter scheduler should treat preferences. Preferences include preferredDuringSchedulingIgnoreDuringExecution node and pod affinities/anti-affinities and ScheduleAnyways topologySpreadConstraints. Can be one of 'Ignore' and 'Respect'")
 	fs.StringVar(&o.minValuesPolicyRaw, "min-values-policy", env.WithDefaultString("MIN_VALUES_POLICY", string(MinValuesPolicyStrict)), "Min values policy for scheduling. Options include 'Strict' for existing behavior where min values are strictly enforced or 'BestEffort' where Karpenter relaxes min values when it isn't satisfied.")
+	fs.BoolVarWithEnv(&o.DisruptionDryRun, "disruption-dry-run", "DISRUPTION_DRY_RUN", false, "When set, Karpenter computes and validates disruption decisions but only reports them through events and logs instead of disrupting nodes. Individual NodePools can be put in dry-run mode with the karpenter.sh/disruption-dry-run annotation.")
+	fs.StringVar(&o.driftOrderingRaw, "drift-ordering", env.WithDefaultString("DRIFT_ORDERING", string(DriftOrderingOldest)), "The order in which drifted nodes are disrupted. Can be one of 'Oldest' to disrupt the nodes that drifted first or 'DisruptionCost' to disrupt the nodes that are cheapest to disrupt first. Empty nodes are always disrupted first.")
+	fs.BoolVarWithEnv(&o.DriftCheaperReplacements, "drift-cheaper-replacements", "DRIFT_CHEAPER_REPLACEMENTS", false, "When set, Karpenter restricts the replacement for a drifted node to instance types that are cheaper than the drifted node if enough of them are compatible with its pods.")
+	fs.StringVar(&o.schedulingSolverRaw, "scheduling-solver", env.WithDefaultString("SCHEDULING_SOLVER", string(SchedulingSolverGreedy)), "The solver used to pack pending pods onto new nodes. Can be one of 'Greedy' to place each pod on the first node that fits it or 'BestFit' to repack the greedy result with a best-fit decreasing heuristic for a lower launch price within the scheduling solver budget.")
+	fs.DurationVar(&o.SchedulingSolverBudget, "scheduling-solver-budget", env.WithDefaultDuration("SCHEDULING_SOLVER_BUDGET", time.Second), "The maximum amount of time the 'BestFit' scheduling solver spends on a batch of pods before falling back to the greedy result.")
+	fs.BoolVarWithEnv(&o.SchedulingDecisionTrace, "scheduling-decision-trace", "SCHEDULING_DECISION_TRACE", false, "When set, Karpenter records the existing nodes, NodePools, and instance types it considered for each pod along with the preferences it relaxed, and publishes them as a SchedulingDecision event on the pod.")
+	fs.StringVar(&o.schedulingPreemptionRaw, "scheduling-preemption", env.WithDefaultString("SCHEDULING_PREEMPTION", string(SchedulingPreemptionDisabled)), "How the Karpenter scheduler treats pods that don't fit on existing nodes or new capacity, e.g. because NodePool limits are reached. Can be one of 'Disabled' to fail to schedule them, 'Simulate' to place them on existing nodes where the kube-scheduler can preempt lower priority pods without violating PDBs, or 'SimulateAndProvision' to also provision capacity for the preempted pods.")
+	fs.DurationVar(&o.ReservationExpiryLeadTime, "reservation-expiry-lead-time", env.WithDefaultDuration("RESERVATION_EXPIRY_LEAD_TIME", time.Hour), "How long before a capacity reservation ends that Karpenter stops launching nodes into it and replaces the nodes that are running in it.")
 	fs.BoolVarWithEnv(&o.IgnoreDRARequests, "ignore-dra-requests", "IGNORE_DRA_REQUESTS", true, "When set, Karpenter will ignore pods' DRA requests during scheduling simulations. NOTE: This flag will be removed once formal DRA support is GA in Karpenter.")
-	fs.StringVar(&o.FeatureGates.inputStr, "feature-gates", env.WithDefaultString("FEATURE_GATES", "NodeRepair=false,ReservedCapacity=true,SpotToSpotConsolidation=false,NodeOverlay=false,StaticCapacity=false,CapacityBuffer=false"), "Optional features can be enabled / disabled using feature gates. Current options are: NodeRepair, ReservedCapacity, SpotToSpotConsolidation, NodeOverlay, StaticCapacity, and CapacityBuffer.")
+	fs.StringVar(&o.FeatureGates.inputStr, "feature-gates", env.WithDefaultString("FEATURE_GATES", "NodeRepair=false,ReservedCapacity=true,SpotToSpotConsolidation=false,NodeOverlay=false,StaticCapacity=false,CapacityBuffer=false,ResourcePrediction=false,DisruptionPlan=false,IncrementalScheduling=false"), "Optional features can be enabled / disabled using feature gates. Current options are: NodeRepair, ReservedCapacity, SpotToSpotConsolidation, NodeOverlay, StaticCapacity, CapacityBuffer, ResourcePrediction, DisruptionPlan, and IncrementalScheduling.")
 }
 
 func (o *Options) Parse(fs *FlagSet, args ...string) error {
@@ -150,6 +205,15 @@ func (o *Options) Parse(fs *FlagSet, args ...string) error {
 	if !lo.Contains([]MinValuesPolicy{MinValuesPolicyStrict, MinValuesPolicyBestEffort}, MinValuesPolicy(o.minValuesPolicyRaw)) {
 		return fmt.Errorf("validating cli flags / env vars, invalid MIN_VALUES_POLICY %q", o.minValuesPolicyRaw)
 	}
+	if !lo.Contains(validDriftOrderings, DriftOrdering(o.driftOrderingRaw)) {
+		return fmt.Errorf("validating cli flags / env vars, invalid DRIFT_ORDERING %q", o.driftOrderingRaw)
+	}
+	if !lo.Contains(validSchedulingSolvers, SchedulingSolver(o.schedulingSolverRaw)) {
+		return fmt.Errorf("validating cli flags / env vars, invalid SCHEDULING_SOLVER %q", o.schedulingSolverRaw)
+	}
+	if !lo.Contains(validPreemptions, SchedulingPreemption(o.schedulingPreemptionRaw)) {
+		return fmt.Errorf("validating cli flags / env vars, invalid SCHEDULING_PREEMPTION %q", o.schedulingPreemptionRaw)
+	}
 	if o.CPURequests <= 0 {
 		o.CPURequests = 1000
 	}
@@ -160,6 +224,9 @@ func (o *Options) Parse(fs *FlagSet, args ...string) error {
 	o.FeatureGates = gates
 	o.PreferencePolicy = PreferencePolicy(o.preferencePolicyRaw)
 	o.MinValuesPolicy = MinValuesPolicy(o.minValuesPolicyRaw)
+	o.DriftOrdering = DriftOrdering(o.driftOrderingRaw)
+	o.SchedulingSolver = SchedulingSolver(o.schedulingSolverRaw)
+	o.SchedulingPreemption = SchedulingPreemption(o.schedulingPreemptionRaw)
 	return nil
 }
 
@@ -175,6 +242,9 @@ func DefaultFeatureGates() FeatureGates {
 		NodeOverlay:             false,
 		StaticCapacity:          false,
 		CapacityBuffer:          false,
+		ResourcePrediction:      false,
+		DisruptionPlan:          false,
+		IncrementalScheduling:   false,
 	}
 }
 
@@ -205,6 +275,15 @@ func ParseFeatureGates(gateStr string) (FeatureGates, error) {
 	if val, ok := gateMap["CapacityBuffer"]; ok {
 		gates.CapacityBuffer = val
 	}
+	if val, ok := gateMap["ResourcePrediction"]; ok {
+		gates.ResourcePrediction = val
+	}
+	if val, ok := gateMap["DisruptionPlan"]; ok {
+		gates.DisruptionPlan = val
+	}
+	if val, ok := gateMap["IncrementalScheduling"]; ok {
+		gates.IncrementalScheduling = val
+	}
 
 	return gates, nil
 }
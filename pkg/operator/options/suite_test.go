@@ -64,6 +64,14 @@ var _ = Describe("Options", func() {
 		"BATCH_IDLE_DURATION",
 		"PREFERENCE_POLICY",
 		"MIN_VALUES_POLICY",
+		"DISRUPTION_DRY_RUN",
+		"DRIFT_ORDERING",
+		"DRIFT_CHEAPER_REPLACEMENTS",
+		"SCHEDULING_SOLVER",
+		"SCHEDULING_SOLVER_BUDGET",
+		"SCHEDULING_DECISION_TRACE",
+		"SCHEDULING_PREEMPTION",
+		"RESERVATION_EXPIRY_LEAD_TIME",
 		"FEATURE_GATES",
 	}
 
@@ -120,6 +128,14 @@ var _ = Describe("Options", func() {
 				BatchIdleDuration:                lo.ToPtr(time.Second),
 				PreferencePolicy:                 lo.ToPtr(options.PreferencePolicyRespect),
 				MinValuesPolicy:                  lo.ToPtr(options.MinValuesPolicyStrict),
+				DisruptionDryRun:                 new(false),
+				DriftOrdering:                    lo.ToPtr(options.DriftOrderingOldest),
+				DriftCheaperReplacements:         new(false),
+				SchedulingSolver:                 lo.ToPtr(options.SchedulingSolverGreedy),
+				SchedulingSolverBudget:           lo.ToPtr(time.Second),
+				SchedulingDecisionTrace:          new(false),
+				SchedulingPreemption:             lo.ToPtr(options.SchedulingPreemptionDisabled),
+				ReservationExpiryLeadTime:        lo.ToPtr(time.Hour),
 				FeatureGates: test.FeatureGates{
 					ReservedCapacity:        new(true),
 					NodeRepair:              new(false),
@@ -127,6 +143,9 @@ var _ = Describe("Options", func() {
 					NodeOverlay:             new(false),
 					StaticCapacity:          new(false),
 					CapacityBuffer:          new(false),
+					ResourcePrediction:      new(false),
+					DisruptionPlan:          new(false),
+					IncrementalScheduling:   new(false),
 				},
 				IgnoreDRARequests: new(true),
 			}))
@@ -156,7 +175,15 @@ var _ = Describe("Options", func() {
 				"--batch-idle-duration", "5s",
 				"--preference-policy", "Ignore",
 				"--min-values-policy", "BestEffort",
-				"--feature-gates", "ReservedCapacity=false,SpotToSpotConsolidation=true,NodeRepair=true,NodeOverlay=true,StaticCapacity=true,CapacityBuffer=true",
+				"--disruption-dry-run=true",
+				"--drift-ordering", "DisruptionCost",
+				"--drift-cheaper-replacements=true",
+				"--scheduling-solver", "BestFit",
+				"--scheduling-solver-budget", "5s",
+				"--scheduling-decision-trace=true",
+				"--scheduling-preemption", "SimulateAndProvision",
+				"--reservation-expiry-lead-time", "30m",
+				"--feature-gates", "ReservedCapacity=false,SpotToSpotConsolidation=true,NodeRepair=true,NodeOverlay=true,StaticCapacity=true,CapacityBuffer=true,ResourcePrediction=true,DisruptionPlan=true,IncrementalScheduling=true",
 			)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
@@ -179,6 +206,14 @@ var _ = Describe("Options", func() {
 				BatchIdleDuration:                lo.ToPtr(5 * time.Second),
 				PreferencePolicy:                 lo.ToPtr(options.PreferencePolicyIgnore),
 				MinValuesPolicy:                  lo.ToPtr(options.MinValuesPolicyBestEffort),
+				DisruptionDryRun:                 new(true),
+				DriftOrdering:                    lo.ToPtr(options.DriftOrderingDisruptionCost),
+				DriftCheaperReplacements:         new(true),
+				SchedulingSolver:                 lo.ToPtr(options.SchedulingSolverBestFit),
+				SchedulingSolverBudget:           lo.ToPtr(5 * time.Second),
+				SchedulingDecisionTrace:          new(true),
+				SchedulingPreemption:             lo.ToPtr(options.SchedulingPreemptionSimulateAndProvision),
+				ReservationExpiryLeadTime:        lo.ToPtr(30 * time.Minute),
 				FeatureGates: test.FeatureGates{
 					ReservedCapacity:        new(false),
 					NodeRepair:              new(true),
@@ -186,6 +221,9 @@ var _ = Describe("Options", func() {
 					NodeOverlay:             new(true),
 					StaticCapacity:          new(true),
 					CapacityBuffer:          new(true),
+					ResourcePrediction:      new(true),
+					DisruptionPlan:          new(true),
+					IncrementalScheduling:   new(true),
 				},
 				IgnoreDRARequests: new(true),
 			}))
@@ -211,7 +249,15 @@ var _ = Describe("Options", func() {
 			os.Setenv("BATCH_IDLE_DURATION", "5s")
 			os.Setenv("PREFERENCE_POLICY", "Ignore")
 			os.Setenv("MIN_VALUES_POLICY", "BestEffort")
-			os.Setenv("FEATURE_GATES", "ReservedCapacity=false,SpotToSpotConsolidation=true,NodeRepair=true,NodeOverlay=true,StaticCapacity=true,CapacityBuffer=true")
+			os.Setenv("DISRUPTION_DRY_RUN", "true")
+			os.Setenv("DRIFT_ORDERING", "DisruptionCost")
+			os.Setenv("DRIFT_CHEAPER_REPLACEMENTS", "true")
+			os.Setenv("SCHEDULING_SOLVER", "BestFit")
+			os.Setenv("SCHEDULING_SOLVER_BUDGET", "5s")
+			os.Setenv("SCHEDULING_DECISION_TRACE", "true")
+			os.Setenv("SCHEDULING_PREEMPTION", "SimulateAndProvision")
+			os.Setenv("RESERVATION_EXPIRY_LEAD_TIME", "30m")
+			os.Setenv("FEATURE_GATES", "ReservedCapacity=false,SpotToSpotConsolidation=true,NodeRepair=true,NodeOverlay=true,StaticCapacity=true,CapacityBuffer=true,ResourcePrediction=true,DisruptionPlan=true,IncrementalScheduling=true")
 			fs = &options.FlagSet{
 				FlagSet: flag.NewFlagSet("karpenter", flag.ContinueOnError),
 			}
@@ -238,6 +284,14 @@ var _ = Describe("Options", func() {
 				BatchIdleDuration:                lo.ToPtr(5 * time.Second),
 				PreferencePolicy:                 lo.ToPtr(options.PreferencePolicyIgnore),
 				MinValuesPolicy:                  lo.ToPtr(options.MinValuesPolicyBestEffort),
+				DisruptionDryRun:                 new(true),
+				DriftOrdering:                    lo.ToPtr(options.DriftOrderingDisruptionCost),
+				DriftCheaperReplacements:         new(true),
+				SchedulingSolver:                 lo.ToPtr(options.SchedulingSolverBestFit),
+				SchedulingSolverBudget:           lo.ToPtr(5 * time.Second),
+				SchedulingDecisionTrace:          new(true),
+				SchedulingPreemption:             lo.ToPtr(options.SchedulingPreemptionSimulateAndProvision),
+				ReservationExpiryLeadTime:        lo.ToPtr(30 * time.Minute),
 				FeatureGates: test.FeatureGates{
 					ReservedCapacity:        new(false),
 					NodeRepair:              new(true),
@@ -245,6 +299,9 @@ var _ = Describe("Options", func() {
 					NodeOverlay:             new(true),
 					StaticCapacity:          new(true),
 					CapacityBuffer:          new(true),
+					ResourcePrediction:      new(true),
+					DisruptionPlan:          new(true),
+					IncrementalScheduling:   new(true),
 				},
 				IgnoreDRARequests: new(true),
 			}))
@@ -265,7 +322,15 @@ var _ = Describe("Options", func() {
 			os.Setenv("BATCH_IDLE_DURATION", "5s")
 			os.Setenv("PREFERENCE_POLICY", "Ignore")
 			os.Setenv("MIN_VALUES_POLICY", "BestEffort")
-			os.Setenv("FEATURE_GATES", "ReservedCapacity=false,SpotToSpotConsolidation=true,NodeRepair=true,NodeOverlay=true,StaticCapacity=true,CapacityBuffer=true")
+			os.Setenv("DISRUPTION_DRY_RUN", "true")
+			os.Setenv("DRIFT_ORDERING", "DisruptionCost")
+			os.Setenv("DRIFT_CHEAPER_REPLACEMENTS", "true")
+			os.Setenv("SCHEDULING_SOLVER", "BestFit")
+			os.Setenv("SCHEDULING_SOLVER_BUDGET", "5s")
+			os.Setenv("SCHEDULING_DECISION_TRACE", "true")
+			os.Setenv("SCHEDULING_PREEMPTION", "SimulateAndProvision")
+			os.Setenv("RESERVATION_EXPIRY_LEAD_TIME", "30m")
+			os.Setenv("FEATURE_GATES", "ReservedCapacity=false,SpotToSpotConsolidation=true,NodeRepair=true,NodeOverlay=true,StaticCapacity=true,CapacityBuffer=true,ResourcePrediction=true,DisruptionPlan=true,IncrementalScheduling=true")
 			fs = &options.FlagSet{
 				FlagSet: flag.NewFlagSet("karpenter", flag.ContinueOnError),
 			}
@@ -299,6 +364,14 @@ var _ = Describe("Options", func() {
 				BatchIdleDuration:                lo.ToPtr(5 * time.Second),
 				PreferencePolicy:                 lo.ToPtr(options.PreferencePolicyRespect),
 				MinValuesPolicy:                  lo.ToPtr(options.MinValuesPolicyStrict),
+				DisruptionDryRun:                 new(true),
+				DriftOrdering:                    lo.ToPtr(options.DriftOrderingDisruptionCost),
+				DriftCheaperReplacements:         new(true),
+				SchedulingSolver:                 lo.ToPtr(options.SchedulingSolverBestFit),
+				SchedulingSolverBudget:           lo.ToPtr(5 * time.Second),
+				SchedulingDecisionTrace:          new(true),
+				SchedulingPreemption:             lo.ToPtr(options.SchedulingPreemptionSimulateAndProvision),
+				ReservationExpiryLeadTime:        lo.ToPtr(30 * time.Minute),
 				FeatureGates: test.FeatureGates{
 					ReservedCapacity:        new(false),
 					NodeRepair:              new(true),
@@ -306,6 +379,9 @@ var _ = Describe("Options", func() {
 					NodeOverlay:             new(true),
 					StaticCapacity:          new(true),
 					CapacityBuffer:          new(true),
+					ResourcePrediction:      new(true),
+					DisruptionPlan:          new(true),
+					IncrementalScheduling:   new(true),
 				},
 				IgnoreDRARequests: new(true),
 			}))
@@ -340,6 +416,9 @@ var _ = Describe("Options", func() {
 			Entry("when NodeOverlay is overridden", "NodeOverlay"),
 			Entry("when StaticCapacity is overridden", "StaticCapacity"),
 			Entry("when CapacityBuffer is overridden", "CapacityBuffer"),
+			Entry("when ResourcePrediction is overridden", "ResourcePrediction"),
+			Entry("when DisruptionPlan is overridden", "DisruptionPlan"),
+			Entry("when IncrementalScheduling is overridden", "IncrementalScheduling"),
 		)
 	})
 
@@ -368,6 +447,18 @@ var _ = Describe("Options", func() {
 			err := opts.Parse(fs, "--log-level", "hello")
 			Expect(err).ToNot(BeNil())
 		})
+		It("should error with an invalid drift ordering", func() {
+			err := opts.Parse(fs, "--drift-ordering", "Newest")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an invalid scheduling solver", func() {
+			err := opts.Parse(fs, "--scheduling-solver", "ILP")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an invalid scheduling preemption", func() {
+			err := opts.Parse(fs, "--scheduling-preemption", "Always")
+			Expect(err).ToNot(BeNil())
+		})
 		DescribeTable(
 			"should fallback to the default if a non-positive value is provided for CPU_REQUESTS",
 			func(value string) {
@@ -410,6 +501,17 @@ func expectOptionsMatch(optsA, optsB *options.Options) {
 	Expect(optsA.FeatureGates.NodeOverlay).To(Equal(optsB.FeatureGates.NodeOverlay))
 	Expect(optsA.FeatureGates.StaticCapacity).To(Equal(optsB.FeatureGates.StaticCapacity))
 	Expect(optsA.FeatureGates.CapacityBuffer).To(Equal(optsB.FeatureGates.CapacityBuffer))
+	Expect(optsA.FeatureGates.ResourcePrediction).To(Equal(optsB.FeatureGates.ResourcePrediction))
+	Expect(optsA.FeatureGates.DisruptionPlan).To(Equal(optsB.FeatureGates.DisruptionPlan))
+	Expect(optsA.FeatureGates.IncrementalScheduling).To(Equal(optsB.FeatureGates.IncrementalScheduling))
 	Expect(optsA.FeatureGates.SpotToSpotConsolidation).To(Equal(optsB.FeatureGates.SpotToSpotConsolidation))
+	Expect(optsA.DisruptionDryRun).To(Equal(optsB.DisruptionDryRun))
+	Expect(optsA.DriftOrdering).To(Equal(optsB.DriftOrdering))
+	Expect(optsA.DriftCheaperReplacements).To(Equal(optsB.DriftCheaperReplacements))
+	Expect(optsA.SchedulingSolver).To(Equal(optsB.SchedulingSolver))
+	Expect(optsA.SchedulingSolverBudget).To(Equal(optsB.SchedulingSolverBudget))
+	Expect(optsA.SchedulingDecisionTrace).To(Equal(optsB.SchedulingDecisionTrace))
+	Expect(optsA.SchedulingPreemption).To(Equal(optsB.SchedulingPreemption))
+	Expect(optsA.ReservationExpiryLeadTime).To(Equal(optsB.ReservationExpiryLeadTime))
 	Expect(optsA.IgnoreDRARequests).To(Equal(optsB.IgnoreDRARequests))
 }
@@ -0,0 +1,258 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulator runs Karpenter's provisioning and disruption decisions against a static snapshot of a cluster.
+// Nothing is launched or disrupted, so capacity planning and policy changes can be evaluated against real cluster
+// shapes without touching the cluster.
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/awslabs/operatorpkg/object"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
+	"sigs.k8s.io/karpenter/pkg/controllers/dynamicresources/deviceallocation"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	scheduler "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/state/cost"
+	"sigs.k8s.io/karpenter/pkg/state/prediction"
+	"sigs.k8s.io/karpenter/pkg/state/virtualpods"
+)
+
+// Simulator holds the cluster state built from a snapshot along with the provisioner and disruption methods that
+// make decisions against it
+type Simulator struct {
+	provisioner *provisioning.Provisioner
+	disruption  *disruption.Controller
+	methods     []disruption.Method
+}
+
+// Result is the outcome of a simulation
+type Result struct {
+	// NodeClaims are the NodeClaims that provisioning would create for the pending pods
+	NodeClaims []NodeClaim `json:"nodeClaims,omitempty"`
+	// ExistingNodes are the pending pods that provisioning would schedule to existing nodes, keyed by node name
+	ExistingNodes map[string][]string `json:"existingNodes,omitempty"`
+	// PodErrors are the reasons that pending pods couldn't be scheduled, keyed by pod
+	PodErrors map[string]string `json:"podErrors,omitempty"`
+	// Disruptions are the commands that each disruption method would issue. Each method is evaluated independently
+	// against the snapshot, so the same candidate can be disrupted by more than one method.
+	Disruptions []v1alpha1.DisruptionPlanSpec `json:"disruptions,omitempty"`
+}
+
+// NodeClaim is a NodeClaim that provisioning would create
+type NodeClaim struct {
+	NodePool string `json:"nodePool"`
+	// InstanceTypes are the instance types that the NodeClaim could launch as, cheapest first
+	InstanceTypes []string                                  `json:"instanceTypes"`
+	Requirements  []v1.NodeSelectorRequirementWithMinValues `json:"requirements,omitempty"`
+	Requests      corev1.ResourceList                       `json:"requests,omitempty"`
+	Pods          []string                                  `json:"pods"`
+}
+
+// New builds the cluster state for the snapshot's objects. The clock should be a fake clock set to the time the
+// snapshot was taken, so that time-based decisions such as disruption budget schedules and maintenance windows are made
+// as they would have been in the cluster. NodeClaim conditions such as Drifted and Consolidatable are taken from the
+// snapshot as-is. The cloud provider is constructed with a fake client that serves the snapshot's objects. NodePools and
+// NodeClaims are rewritten to reference the cloud provider's NodeClass, since the snapshot's NodeClasses aren't
+// simulated. The objects are modified in place.
+func New(ctx context.Context, clk clock.Clock, newCloudProvider func(client.Client) cloudprovider.CloudProvider, objects ...client.Object) (*Simulator, error) {
+	for _, o := range objects {
+		// State and the scheduler track objects by UID, which hand-written snapshots may not set
+		if o.GetUID() == "" {
+			o.SetUID(types.UID(uuid.NewString()))
+		}
+	}
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objects...).
+		WithIndex(&corev1.Pod{}, "spec.nodeName", func(o client.Object) []string {
+			return []string{o.(*corev1.Pod).Spec.NodeName}
+		}).
+		WithIndex(&corev1.Node{}, "spec.providerID", func(o client.Object) []string {
+			return []string{o.(*corev1.Node).Spec.ProviderID}
+		}).
+		WithIndex(&v1.NodeClaim{}, "status.providerID", func(o client.Object) []string {
+			return []string{o.(*v1.NodeClaim).Status.ProviderID}
+		}).
+		WithIndex(&storagev1.VolumeAttachment{}, "spec.nodeName", func(o client.Object) []string {
+			return []string{o.(*storagev1.VolumeAttachment).Spec.NodeName}
+		}).
+		Build()
+	cloudProvider := newCloudProvider(kubeClient)
+	nodeClasses := cloudProvider.GetSupportedNodeClasses()
+	if len(nodeClasses) == 0 {
+		return nil, fmt.Errorf("cloudprovider %s doesn't support any nodeclasses", cloudProvider.Name())
+	}
+	nodeClass := object.GVK(nodeClasses[0]).GroupKind()
+	for _, o := range objects {
+		switch o := o.(type) {
+		case *v1.NodePool:
+			o.Spec.Template.Spec.NodeClassRef = &v1.NodeClassReference{Group: nodeClass.Group, Kind: nodeClass.Kind, Name: lo.FromPtr(o.Spec.Template.Spec.NodeClassRef).Name}
+		case *v1.NodeClaim:
+			o.Spec.NodeClassRef = &v1.NodeClassReference{Group: nodeClass.Group, Kind: nodeClass.Kind, Name: lo.FromPtr(o.Spec.NodeClassRef).Name}
+		default:
+			continue
+		}
+		if err := kubeClient.Update(ctx, o); err != nil {
+			return nil, fmt.Errorf("updating nodeclass reference of %s, %w", klog.KObj(o), err)
+		}
+	}
+	recorder := events.NewRecorder(&record.FakeRecorder{})
+	cluster := state.NewCluster(clk, kubeClient, cloudProvider)
+	clusterCost := cost.NewClusterCost(ctx, cloudProvider, kubeClient)
+	if err := populate(ctx, cluster, clusterCost, cloudProvider, objects); err != nil {
+		return nil, err
+	}
+	if !cluster.Synced(ctx) {
+		return nil, fmt.Errorf("snapshot contains nodeclaims that haven't launched")
+	}
+
+	provisioner := provisioning.NewProvisioner(kubeClient, recorder, cloudProvider, cluster, clk, deviceallocation.NewController(kubeClient), virtualpods.NewVirtualPodCache(kubeClient), prediction.NewStore())
+	queue := disruption.NewQueue(kubeClient, recorder, cluster, clk, provisioner)
+	c := disruption.MakeConsolidation(clk, cluster, kubeClient, provisioner, cloudProvider, recorder, queue)
+	methods := []disruption.Method{
+		disruption.NewEmptiness(c, disruption.WithValidator(snapshotValidator{})),
+		disruption.NewDrift(kubeClient, cluster, provisioner, recorder, clk),
+		disruption.NewMultiNodeConsolidation(c, disruption.WithValidator(snapshotValidator{})),
+		disruption.NewSingleNodeConsolidation(c, disruption.WithValidator(snapshotValidator{})),
+	}
+	return &Simulator{
+		provisioner: provisioner,
+		disruption:  disruption.NewController(clk, kubeClient, provisioner, cloudProvider, recorder, cluster, queue, clusterCost, disruption.WithMethods(methods...)),
+		methods:     methods,
+	}, nil
+}
+
+// populate informs cluster state about the snapshot's objects in the order that the state informers would have
+// observed them, so that pods are bound to known nodes
+func populate(ctx context.Context, cluster *state.Cluster, clusterCost *cost.ClusterCost, cloudProvider cloudprovider.CloudProvider, objects []client.Object) error {
+	for _, np := range filter[*v1.NodePool](objects) {
+		instanceTypes, err := cloudProvider.GetInstanceTypes(ctx, np)
+		if err != nil {
+			return fmt.Errorf("getting instance types for nodepool %q, %w", np.Name, err)
+		}
+		clusterCost.UpdateOfferings(ctx, np, instanceTypes)
+	}
+	for _, nc := range filter[*v1.NodeClaim](objects) {
+		cluster.UpdateNodeClaim(nc)
+		if err := clusterCost.UpdateNodeClaim(ctx, nc); err != nil {
+			log.FromContext(ctx).Error(err, "failed tracking nodeclaim cost", "NodeClaim", klog.KObj(nc))
+		}
+	}
+	for _, n := range filter[*corev1.Node](objects) {
+		if err := cluster.UpdateNode(ctx, n); err != nil {
+			return fmt.Errorf("updating node %q, %w", n.Name, err)
+		}
+	}
+	for _, ds := range filter[*appsv1.DaemonSet](objects) {
+		if err := cluster.UpdateDaemonSet(ctx, ds); err != nil {
+			return fmt.Errorf("updating daemonset %q, %w", client.ObjectKeyFromObject(ds), err)
+		}
+	}
+	for _, p := range filter[*corev1.Pod](objects) {
+		if err := cluster.UpdatePod(ctx, p); err != nil {
+			return fmt.Errorf("updating pod %q, %w", client.ObjectKeyFromObject(p), err)
+		}
+	}
+	cluster.MarkUnconsolidated()
+	return nil
+}
+
+func filter[T client.Object](objects []client.Object) []T {
+	return lo.FilterMap(objects, func(o client.Object, _ int) (T, bool) {
+		t, ok := o.(T)
+		return t, ok
+	})
+}
+
+// Run schedules the snapshot's pending pods and computes the commands that each disruption method would issue
+func (s *Simulator) Run(ctx context.Context) (Result, error) {
+	results, err := s.provisioner.Schedule(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("scheduling pending pods, %w", err)
+	}
+	result := Result{
+		NodeClaims: lo.Map(results.NewNodeClaims, func(n *scheduler.NodeClaim, _ int) NodeClaim {
+			nodeClaim := n.ToNodeClaim()
+			return NodeClaim{
+				NodePool: n.NodePoolName,
+				InstanceTypes: lo.Map(n.InstanceTypeOptions.OrderByPrice(n.Requirements), func(it *cloudprovider.InstanceType, _ int) string {
+					return it.Name
+				}),
+				Requirements: nodeClaim.Spec.Requirements,
+				Requests:     nodeClaim.Spec.Resources.Requests,
+				Pods:         podNames(n.Pods),
+			}
+		}),
+		ExistingNodes: lo.SliceToMap(lo.Filter(results.ExistingNodes, func(n *scheduler.ExistingNode, _ int) bool {
+			return len(n.Pods) != 0
+		}), func(n *scheduler.ExistingNode) (string, []string) {
+			return n.Name(), podNames(n.Pods)
+		}),
+		PodErrors: lo.MapEntries(results.PodErrors, func(p *corev1.Pod, err error) (string, string) {
+			return podName(p), err.Error()
+		}),
+	}
+	for _, m := range s.methods {
+		cmds, err := s.disruption.ComputeCommands(ctx, m)
+		if err != nil {
+			return Result{}, fmt.Errorf("computing %s disruption commands, %w", m.Reason(), err)
+		}
+		for i := range cmds {
+			cmds[i].Method = m
+			result.Disruptions = append(result.Disruptions, disruption.NewDisruptionPlan(&cmds[i]).Spec)
+		}
+	}
+	return result, nil
+}
+
+func podNames(pods []*corev1.Pod) []string {
+	return lo.Map(pods, func(p *corev1.Pod, _ int) string { return podName(p) })
+}
+
+func podName(p *corev1.Pod) string {
+	return types.NamespacedName{Namespace: p.Namespace, Name: p.Name}.String()
+}
+
+// snapshotValidator accepts every command. Validation waits for the cluster to settle and checks that the command is
+// still valid against the latest cluster state, but a snapshot never changes.
+type snapshotValidator struct{}
+
+func (snapshotValidator) Validate(_ context.Context, cmd disruption.Command, _ time.Duration) (disruption.Command, error) {
+	return cmd, nil
+}
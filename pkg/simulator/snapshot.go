@@ -0,0 +1,90 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Decode decodes the objects in a cluster snapshot. A snapshot is a stream of YAML or JSON objects, where each object
+// can also be a list of objects such as the output of `kubectl get -o yaml`. Objects with kinds that aren't registered
+// with the scheme are skipped since they can't affect the simulation.
+func Decode(ctx context.Context, r io.Reader) ([]client.Object, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	var objects []client.Object
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("decoding snapshot, %w", err)
+		}
+		// Empty documents are decoded as empty objects
+		if len(u.Object) == 0 {
+			continue
+		}
+		items := []unstructured.Unstructured{*u}
+		if u.IsList() {
+			list, err := u.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("decoding snapshot list, %w", err)
+			}
+			items = list.Items
+		}
+		for i := range items {
+			obj, err := convert(&items[i])
+			if err != nil {
+				return nil, err
+			}
+			if obj == nil {
+				log.FromContext(ctx).V(1).WithValues("kind", items[i].GroupVersionKind().String(), "name", items[i].GetName()).Info("skipping unknown kind in snapshot")
+				continue
+			}
+			objects = append(objects, obj)
+		}
+	}
+}
+
+// convert returns the typed object for an unstructured object, or nil if its kind isn't registered with the scheme
+func convert(u *unstructured.Unstructured) (client.Object, error) {
+	obj, err := scheme.Scheme.New(u.GroupVersionKind())
+	if err != nil {
+		if runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("creating %s, %w", u.GroupVersionKind(), err)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		return nil, fmt.Errorf("converting %s %q, %w", u.GroupVersionKind().Kind, u.GetName(), err)
+	}
+	o, ok := obj.(client.Object)
+	if !ok {
+		return nil, nil
+	}
+	return o, nil
+}
@@ -0,0 +1,273 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/simulator"
+	"sigs.k8s.io/karpenter/pkg/test"
+)
+
+var ctx context.Context
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+
+func TestSimulator(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Simulator")
+}
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(context.Background(), test.Options())
+	fakeClock = clock.NewFakeClock(time.Now())
+	cloudProvider = fake.NewCloudProvider()
+})
+
+func newCloudProvider(client.Client) cloudprovider.CloudProvider {
+	return cloudProvider
+}
+
+var _ = Describe("Decode", func() {
+	It("should decode lists and streams of objects", func() {
+		objects, err := simulator.Decode(ctx, strings.NewReader(`
+apiVersion: v1
+kind: List
+items:
+- apiVersion: karpenter.sh/v1
+  kind: NodePool
+  metadata:
+    name: default
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: pod
+    namespace: default
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: node
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objects).To(HaveLen(3))
+		Expect(objects[0]).To(BeAssignableToTypeOf(&v1.NodePool{}))
+		Expect(objects[1]).To(BeAssignableToTypeOf(&corev1.Pod{}))
+		Expect(objects[2]).To(BeAssignableToTypeOf(&corev1.Node{}))
+		Expect(client.ObjectKeyFromObject(objects[1])).To(Equal(types.NamespacedName{Namespace: "default", Name: "pod"}))
+	})
+	It("should skip unknown kinds", func() {
+		objects, err := simulator.Decode(ctx, strings.NewReader(`
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: node
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objects).To(HaveLen(1))
+		Expect(objects[0].GetName()).To(Equal("node"))
+	})
+	It("should fail on invalid objects", func() {
+		_, err := simulator.Decode(ctx, strings.NewReader(`
+apiVersion: v1
+kind: Node
+metadata:
+  name: [node]
+`))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Simulator", func() {
+	var nodePool *v1.NodePool
+	var instanceType *cloudprovider.InstanceType
+
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Template: v1.NodeClaimTemplate{
+					Spec: v1.NodeClaimTemplateSpec{
+						// Snapshots reference the NodeClasses of the cloud provider that they were taken from
+						NodeClassRef: &v1.NodeClassReference{Group: "karpenter.example.com", Kind: "ExampleNodeClass", Name: "default"},
+					},
+				},
+				Disruption: v1.Disruption{
+					ConsolidateAfter:    v1.MustParseNillableDuration("0s"),
+					ConsolidationPolicy: v1.ConsolidationPolicyWhenEmpty,
+					Budgets:             []v1.Budget{{Nodes: "100%"}},
+				},
+			},
+		})
+		its, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		instanceType = its[0]
+	})
+	nodeClaimAndNode := func() (*v1.NodeClaim, *corev1.Node) {
+		offering := instanceType.Offerings[0]
+		nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: instanceType.Name,
+					v1.CapacityTypeLabelKey:        offering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       offering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					v1.NodeRegisteredLabelKey:      "true",
+					v1.NodeInitializedLabelKey:     "true",
+				},
+			},
+			Spec: v1.NodeClaimSpec{
+				NodeClassRef: nodePool.Spec.Template.Spec.NodeClassRef,
+			},
+			Status: v1.NodeClaimStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:  resource.MustParse("32"),
+					corev1.ResourcePods: resource.MustParse("100"),
+				},
+			},
+		})
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeRegistered)
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+		node.Spec.Taints = nil
+		node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+		return nodeClaim, node
+	}
+
+	It("should create nodeclaims for pending pods", func() {
+		pod := test.UnschedulablePod()
+		sim, err := simulator.New(ctx, fakeClock, newCloudProvider, nodePool, pod)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := sim.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.NodeClaims).To(HaveLen(1))
+		Expect(result.NodeClaims[0].NodePool).To(Equal(nodePool.Name))
+		Expect(result.NodeClaims[0].InstanceTypes).ToNot(BeEmpty())
+		Expect(result.NodeClaims[0].Pods).To(ConsistOf(client.ObjectKeyFromObject(pod).String()))
+		Expect(result.PodErrors).To(BeEmpty())
+		Expect(result.Disruptions).To(BeEmpty())
+	})
+	It("should schedule pending pods to existing nodes", func() {
+		nodeClaim, node := nodeClaimAndNode()
+		pod := test.UnschedulablePod()
+		sim, err := simulator.New(ctx, fakeClock, newCloudProvider, nodePool, nodeClaim, node, pod)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := sim.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.NodeClaims).To(BeEmpty())
+		Expect(result.ExistingNodes).To(HaveKeyWithValue(node.Name, ConsistOf(client.ObjectKeyFromObject(pod).String())))
+	})
+	It("should report pods that can't schedule", func() {
+		pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10000")},
+		}})
+		sim, err := simulator.New(ctx, fakeClock, newCloudProvider, nodePool, pod)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := sim.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.NodeClaims).To(BeEmpty())
+		Expect(result.PodErrors).To(HaveKey(client.ObjectKeyFromObject(pod).String()))
+	})
+	It("should delete empty nodes", func() {
+		nodeClaim, node := nodeClaimAndNode()
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
+		sim, err := simulator.New(ctx, fakeClock, newCloudProvider, nodePool, nodeClaim, node)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := sim.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Disruptions).ToNot(BeEmpty())
+		Expect(result.Disruptions[0].Reason).To(Equal(string(v1.DisruptionReasonEmpty)))
+		Expect(result.Disruptions[0].Candidates).To(HaveLen(1))
+		Expect(result.Disruptions[0].Candidates[0].NodeClaim).To(Equal(nodeClaim.Name))
+		Expect(result.Disruptions[0].Replacements).To(BeEmpty())
+	})
+	It("should replace drifted nodes", func() {
+		nodeClaim, node := nodeClaimAndNode()
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		pod := test.Pod(test.PodOptions{
+			NodeName: node.Name,
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", UID: "rs", Controller: new(true)}},
+			},
+		})
+		sim, err := simulator.New(ctx, fakeClock, newCloudProvider, nodePool, nodeClaim, node, pod)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := sim.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Disruptions).To(HaveLen(1))
+		Expect(result.Disruptions[0].Reason).To(Equal(string(v1.DisruptionReasonDrifted)))
+		Expect(result.Disruptions[0].Candidates[0].NodeClaim).To(Equal(nodeClaim.Name))
+		Expect(result.Disruptions[0].Replacements).To(HaveLen(1))
+	})
+	It("should evaluate disruption budget schedules at the simulation time", func() {
+		nodePool.Spec.Disruption.Budgets = append(nodePool.Spec.Disruption.Budgets, v1.Budget{
+			Nodes:    "0",
+			Schedule: new("@daily"),
+			Duration: &metav1.Duration{Duration: time.Hour},
+		})
+		fakeClock.SetTime(time.Date(2026, time.January, 1, 0, 30, 0, 0, time.UTC))
+		nodeClaim, node := nodeClaimAndNode()
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
+		sim, err := simulator.New(ctx, fakeClock, newCloudProvider, nodePool, nodeClaim, node)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := sim.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Disruptions).To(BeEmpty())
+	})
+	It("should construct the cloud provider with a client that serves the snapshot", func() {
+		nodeClaim, node := nodeClaimAndNode()
+		var kubeClient client.Client
+		_, err := simulator.New(ctx, fakeClock, func(c client.Client) cloudprovider.CloudProvider {
+			kubeClient = c
+			return cloudProvider
+		}, nodePool, nodeClaim, node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(kubeClient).ToNot(BeNil())
+		Expect(kubeClient.Get(ctx, client.ObjectKeyFromObject(node), &corev1.Node{})).To(Succeed())
+		stored := &v1.NodeClaim{}
+		Expect(kubeClient.Get(ctx, client.ObjectKeyFromObject(nodeClaim), stored)).To(Succeed())
+		Expect(stored.Spec.NodeClassRef).To(Equal(nodeClaim.Spec.NodeClassRef))
+	})
+	It("should fail when the snapshot has nodeclaims that haven't launched", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}},
+		})
+		nodeClaim.Status.ProviderID = ""
+		_, err := simulator.New(ctx, fakeClock, newCloudProvider, nodePool, nodeClaim)
+		Expect(err).To(HaveOccurred())
+	})
+})
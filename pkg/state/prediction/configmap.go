@@ -0,0 +1,102 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prediction
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+const (
+	// ConfigMapTargetRefKey is the ConfigMap data key holding the workload the prediction applies to, using the
+	// same format as a VPA's spec.targetRef (e.g. {"apiVersion": "apps/v1", "kind": "Deployment", "name": "app"}).
+	ConfigMapTargetRefKey = "targetRef"
+	// ConfigMapContainersKey is the ConfigMap data key holding the predicted requests, keyed by container name
+	// (e.g. {"app": {"cpu": "500m", "memory": "1Gi"}}).
+	ConfigMapContainersKey = "containers"
+)
+
+// ConfigMapSource reads predictions from ConfigMaps labeled with v1.ResourcePredictionLabelKey. This lets
+// in-house recommenders publish predictions without depending on the VPA CRD. The target workload must live
+// in the same namespace as the ConfigMap.
+//
+// The source is registered when the ResourcePrediction feature gate is enabled. ConfigMaps and target workloads are
+// read through the cached client, and targets are limited to the workload kinds that Karpenter already watches.
+type ConfigMapSource struct {
+	kubeClient client.Client
+}
+
+func NewConfigMapSource(kubeClient client.Client) *ConfigMapSource {
+	return &ConfigMapSource{kubeClient: kubeClient}
+}
+
+func (s *ConfigMapSource) Name() string {
+	return "configmap"
+}
+
+func (s *ConfigMapSource) List(ctx context.Context) ([]Recommendation, error) {
+	cms := &corev1.ConfigMapList{}
+	if err := s.kubeClient.List(ctx, cms, client.HasLabels{v1.ResourcePredictionLabelKey}); err != nil {
+		return nil, fmt.Errorf("listing configmaps, %w", err)
+	}
+	var recommendations []Recommendation
+	for i := range cms.Items {
+		cm := &cms.Items[i]
+		r, err := s.recommendationFor(ctx, cm)
+		if err != nil {
+			log.FromContext(ctx).WithValues("ConfigMap", client.ObjectKeyFromObject(cm)).Error(err, "ignoring resource prediction")
+			continue
+		}
+		recommendations = append(recommendations, r)
+	}
+	return recommendations, nil
+}
+
+func (s *ConfigMapSource) recommendationFor(ctx context.Context, cm *corev1.ConfigMap) (Recommendation, error) {
+	ref := autoscalingv1.CrossVersionObjectReference{}
+	if err := yaml.Unmarshal([]byte(cm.Data[ConfigMapTargetRefKey]), &ref); err != nil {
+		return Recommendation{}, fmt.Errorf("parsing %s, %w", ConfigMapTargetRefKey, err)
+	}
+	if ref.Kind == "" || ref.Name == "" {
+		return Recommendation{}, fmt.Errorf("%s must specify a kind and name", ConfigMapTargetRefKey)
+	}
+	containers := map[string]corev1.ResourceList{}
+	if err := yaml.Unmarshal([]byte(cm.Data[ConfigMapContainersKey]), &containers); err != nil {
+		return Recommendation{}, fmt.Errorf("parsing %s, %w", ConfigMapContainersKey, err)
+	}
+	if len(containers) == 0 {
+		return Recommendation{}, fmt.Errorf("%s must specify at least one container", ConfigMapContainersKey)
+	}
+	target, err := resolveTarget(ctx, s.kubeClient, cm.Namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return Recommendation{}, err
+	}
+	return Recommendation{
+		Source:     client.ObjectKeyFromObject(cm),
+		Target:     target,
+		Prediction: &Prediction{Containers: containers},
+		CreatedAt:  cm.CreationTimestamp.Time,
+	}, nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prediction
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakecr "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+var _ = Describe("ConfigMapSource", func() {
+	var ctx context.Context
+	var deployment *appsv1.Deployment
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		deployment = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", UID: "uid-app"}}
+	})
+
+	configMap := func(name string, labeled bool, data map[string]string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}, Data: data}
+		if labeled {
+			cm.Labels = map[string]string{v1.ResourcePredictionLabelKey: "true"}
+		}
+		return cm
+	}
+	newSource := func(objs ...client.Object) *ConfigMapSource {
+		return NewConfigMapSource(fakecr.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build())
+	}
+
+	It("should return a recommendation for a labeled configmap", func() {
+		source := newSource(deployment, configMap("app-prediction", true, map[string]string{
+			ConfigMapTargetRefKey:  "apiVersion: apps/v1\nkind: Deployment\nname: app",
+			ConfigMapContainersKey: "app:\n  cpu: 500m\n  memory: 1Gi",
+		}))
+		recommendations, err := source.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recommendations).To(HaveLen(1))
+		Expect(recommendations[0].Source).To(Equal(types.NamespacedName{Namespace: "default", Name: "app-prediction"}))
+		Expect(recommendations[0].Target).To(Equal(types.UID("uid-app")))
+		Expect(recommendations[0].Prediction.Containers).To(HaveKey("app"))
+		Expect(recommendations[0].Prediction.Containers["app"]).To(HaveKeyWithValue(corev1.ResourceCPU, resource.MustParse("500m")))
+		Expect(recommendations[0].Prediction.Containers["app"]).To(HaveKeyWithValue(corev1.ResourceMemory, resource.MustParse("1Gi")))
+	})
+	It("should ignore configmaps without the prediction label", func() {
+		source := newSource(deployment, configMap("app-prediction", false, map[string]string{
+			ConfigMapTargetRefKey:  "apiVersion: apps/v1\nkind: Deployment\nname: app",
+			ConfigMapContainersKey: "app:\n  cpu: 500m",
+		}))
+		recommendations, err := source.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recommendations).To(BeEmpty())
+	})
+	It("should skip invalid configmaps without failing the list", func() {
+		source := newSource(deployment,
+			configMap("missing-target", true, map[string]string{
+				ConfigMapContainersKey: "app:\n  cpu: 500m",
+			}),
+			configMap("missing-containers", true, map[string]string{
+				ConfigMapTargetRefKey: "apiVersion: apps/v1\nkind: Deployment\nname: app",
+			}),
+			configMap("unsupported-kind", true, map[string]string{
+				ConfigMapTargetRefKey:  "apiVersion: v1\nkind: Secret\nname: app",
+				ConfigMapContainersKey: "app:\n  cpu: 500m",
+			}),
+			configMap("unknown-target", true, map[string]string{
+				ConfigMapTargetRefKey:  "apiVersion: apps/v1\nkind: Deployment\nname: other",
+				ConfigMapContainersKey: "app:\n  cpu: 500m",
+			}),
+			configMap("app-prediction", true, map[string]string{
+				ConfigMapTargetRefKey:  "apiVersion: apps/v1\nkind: Deployment\nname: app",
+				ConfigMapContainersKey: "app:\n  cpu: 500m",
+			}),
+		)
+		recommendations, err := source.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recommendations).To(HaveLen(1))
+		Expect(recommendations[0].Source.Name).To(Equal("app-prediction"))
+	})
+})
@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prediction
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+)
+
+// ResourcePredictionSource reads predictions from ResourcePrediction objects. Unlike ConfigMaps, ResourcePredictions
+// are validated by the API server, so recommenders get feedback on malformed predictions when they write them. The
+// target workload must live in the same namespace as the ResourcePrediction.
+type ResourcePredictionSource struct {
+	kubeClient client.Client
+}
+
+func NewResourcePredictionSource(kubeClient client.Client) *ResourcePredictionSource {
+	return &ResourcePredictionSource{kubeClient: kubeClient}
+}
+
+func (s *ResourcePredictionSource) Name() string {
+	return "resourceprediction"
+}
+
+func (s *ResourcePredictionSource) List(ctx context.Context) ([]Recommendation, error) {
+	rps := &v1alpha1.ResourcePredictionList{}
+	if err := s.kubeClient.List(ctx, rps); err != nil {
+		return nil, fmt.Errorf("listing resourcepredictions, %w", err)
+	}
+	var recommendations []Recommendation
+	for i := range rps.Items {
+		rp := &rps.Items[i]
+		r, err := s.recommendationFor(ctx, rp)
+		if err != nil {
+			log.FromContext(ctx).WithValues("ResourcePrediction", client.ObjectKeyFromObject(rp)).Error(err, "ignoring resource prediction")
+			continue
+		}
+		recommendations = append(recommendations, r)
+	}
+	return recommendations, nil
+}
+
+func (s *ResourcePredictionSource) recommendationFor(ctx context.Context, rp *v1alpha1.ResourcePrediction) (Recommendation, error) {
+	ref := rp.Spec.TargetRef
+	target, err := resolveTarget(ctx, s.kubeClient, rp.Namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return Recommendation{}, err
+	}
+	containers := make(map[string]corev1.ResourceList, len(rp.Spec.Containers))
+	for _, c := range rp.Spec.Containers {
+		containers[c.Name] = c.Requests.DeepCopy()
+	}
+	return Recommendation{
+		Source:     client.ObjectKeyFromObject(rp),
+		Target:     target,
+		Prediction: &Prediction{Containers: containers},
+		CreatedAt:  rp.CreationTimestamp.Time,
+	}, nil
+}
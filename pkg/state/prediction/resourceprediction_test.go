@@ -0,0 +1,84 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prediction
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakecr "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("ResourcePredictionSource", func() {
+	var ctx context.Context
+	var statefulSet *appsv1.StatefulSet
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		statefulSet = &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db", UID: "uid-db"}}
+	})
+
+	resourcePrediction := func(name string, ref v1alpha1.ResourcePredictionTargetReference) *v1alpha1.ResourcePrediction {
+		return &v1alpha1.ResourcePrediction{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: v1alpha1.ResourcePredictionSpec{
+				TargetRef: ref,
+				Containers: []v1alpha1.ContainerResourcePrediction{{
+					Name:     "db",
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+				}},
+			},
+		}
+	}
+	newSource := func(objs ...client.Object) *ResourcePredictionSource {
+		return NewResourcePredictionSource(fakecr.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build())
+	}
+
+	It("should return a recommendation for a resource prediction", func() {
+		source := newSource(statefulSet, resourcePrediction("db-prediction", v1alpha1.ResourcePredictionTargetReference{
+			APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db",
+		}))
+		recommendations, err := source.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recommendations).To(HaveLen(1))
+		Expect(recommendations[0].Source).To(Equal(types.NamespacedName{Namespace: "default", Name: "db-prediction"}))
+		Expect(recommendations[0].Target).To(Equal(types.UID("uid-db")))
+		Expect(recommendations[0].Prediction.Containers["db"]).To(HaveKeyWithValue(corev1.ResourceMemory, resource.MustParse("4Gi")))
+	})
+	It("should skip resource predictions whose target can't be resolved", func() {
+		source := newSource(statefulSet,
+			resourcePrediction("unsupported-kind", v1alpha1.ResourcePredictionTargetReference{APIVersion: "batch/v1", Kind: "Job", Name: "db"}),
+			resourcePrediction("wrong-group", v1alpha1.ResourcePredictionTargetReference{APIVersion: "v1", Kind: "StatefulSet", Name: "db"}),
+			resourcePrediction("unknown-target", v1alpha1.ResourcePredictionTargetReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "other"}),
+			resourcePrediction("db-prediction", v1alpha1.ResourcePredictionTargetReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db"}),
+		)
+		recommendations, err := source.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recommendations).To(HaveLen(1))
+		Expect(recommendations[0].Source.Name).To(Equal("db-prediction"))
+	})
+})
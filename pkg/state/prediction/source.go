@@ -0,0 +1,83 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prediction
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Recommendation is a prediction reported by a Source for a single target workload.
+type Recommendation struct {
+	// Source identifies the object the recommendation was read from (e.g. a VPA or a ConfigMap). When
+	// multiple recommendations target the same workload, the store breaks ties using the creation time
+	// and then the name of the source.
+	Source types.NamespacedName
+	// Target is the UID of the workload that controls the pods the prediction applies to.
+	Target     types.UID
+	Prediction *Prediction
+	CreatedAt  time.Time
+}
+
+// Source provides predictions from a recommender other than VPA. Sources are polled periodically and
+// the recommendations they return replace everything the source reported on the previous poll, so a
+// recommendation that is no longer listed is removed from the store.
+type Source interface {
+	// Name uniquely identifies the source. It is used to name the controller polling the source and to
+	// keep recommendations from different sources with the same namespace and name apart in the store.
+	Name() string
+	// List returns the current set of recommendations. If an error is returned, the predictions from the
+	// previous poll are retained.
+	List(context.Context) ([]Recommendation, error)
+}
+
+// targetKinds are the workloads that a Source may resolve a prediction's target to. The set is limited to the
+// workloads the controller is already allowed to read so that a prediction can't make Karpenter start an informer
+// on, or fail its RBAC for, an arbitrary kind.
+var targetKinds = map[schema.GroupKind]func() client.Object{
+	{Group: appsv1.GroupName, Kind: "Deployment"}:  func() client.Object { return &appsv1.Deployment{} },
+	{Group: appsv1.GroupName, Kind: "ReplicaSet"}:  func() client.Object { return &appsv1.ReplicaSet{} },
+	{Group: appsv1.GroupName, Kind: "StatefulSet"}: func() client.Object { return &appsv1.StatefulSet{} },
+	{Group: appsv1.GroupName, Kind: "DaemonSet"}:   func() client.Object { return &appsv1.DaemonSet{} },
+	{Group: corev1.GroupName, Kind: "ReplicationController"}: func() client.Object {
+		return &corev1.ReplicationController{}
+	},
+}
+
+// resolveTarget returns the UID of the named workload in the given namespace
+func resolveTarget(ctx context.Context, kubeClient client.Client, namespace, apiVersion, kind, name string) (types.UID, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return "", fmt.Errorf("parsing apiVersion, %w", err)
+	}
+	newObject, ok := targetKinds[schema.GroupKind{Group: gv.Group, Kind: kind}]
+	if !ok {
+		return "", fmt.Errorf("unsupported target kind %s", schema.GroupKind{Group: gv.Group, Kind: kind})
+	}
+	target := newObject()
+	if err := kubeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, target); err != nil {
+		return "", fmt.Errorf("resolving target, %w", err)
+	}
+	return target.GetUID(), nil
+}
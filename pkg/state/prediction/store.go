@@ -24,6 +24,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // Prediction holds the predicted resource requests for all containers of a workload.
@@ -32,6 +33,45 @@ type Prediction struct {
 	Containers map[string]corev1.ResourceList
 }
 
+// Apply returns a copy of the pod whose container requests are overridden by the prediction. Resources
+// that aren't present in the prediction keep their current requests. Allocated resources reported in the
+// status of predicted containers are dropped since they describe the running pod rather than its replacement.
+// The returned bool reports whether any container's requests were changed.
+func (p *Prediction) Apply(pod *corev1.Pod) (*corev1.Pod, bool) {
+	changed := false
+	for _, c := range pod.Spec.Containers {
+		for name, qty := range p.Containers[c.Name] {
+			if current, ok := c.Resources.Requests[name]; !ok || !current.Equal(qty) {
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return pod, false
+	}
+	out := pod.DeepCopy()
+	for i := range out.Spec.Containers {
+		c := &out.Spec.Containers[i]
+		predicted, ok := p.Containers[c.Name]
+		if !ok {
+			continue
+		}
+		if c.Resources.Requests == nil {
+			c.Resources.Requests = corev1.ResourceList{}
+		}
+		for name, qty := range predicted {
+			c.Resources.Requests[name] = qty.DeepCopy()
+		}
+		for j := range out.Status.ContainerStatuses {
+			if out.Status.ContainerStatuses[j].Name == c.Name {
+				out.Status.ContainerStatuses[j].AllocatedResources = nil
+				out.Status.ContainerStatuses[j].Resources = nil
+			}
+		}
+	}
+	return out, true
+}
+
 // targetEntry pairs a prediction with metadata about its source for tie-breaking.
 type targetEntry struct {
 	prediction *Prediction
@@ -45,8 +85,9 @@ type targetEntry struct {
 // then lexicographically smallest name) to determine which prediction is active.
 type Store struct {
 	sync.RWMutex
-	hydrationCh   chan struct{}
-	hydrationOnce sync.Once
+	// hydrationCh is closed once every registered source has completed a sync
+	hydrationCh chan struct{}
+	unhydrated  sets.Set[string]
 	// byTarget indexes all contending predictions by the workload they apply to.
 	// Entries are sorted by strength (strongest first).
 	byTarget map[types.UID][]targetEntry
@@ -57,18 +98,47 @@ type Store struct {
 func NewStore() *Store {
 	return &Store{
 		hydrationCh: make(chan struct{}),
+		unhydrated:  sets.New[string](),
 		byTarget:    make(map[types.UID][]targetEntry),
 		bySource:    make(map[types.NamespacedName]types.UID),
 	}
 }
 
-func (s *Store) MarkHydrated() {
-	s.hydrationOnce.Do(func() { close(s.hydrationCh) })
+// RegisterSource records that the named source writes to the store, so that the store isn't considered hydrated
+// until the source has called MarkHydrated.
+func (s *Store) RegisterSource(name string) {
+	s.Lock()
+	defer s.Unlock()
+	if s.unhydrated.Len() == 0 {
+		s.hydrationCh = make(chan struct{})
+	}
+	s.unhydrated.Insert(name)
+}
+
+// MarkHydrated records that the named source has completed a sync. The store is hydrated once every registered
+// source has been marked.
+func (s *Store) MarkHydrated(name string) {
+	s.Lock()
+	defer s.Unlock()
+	if !s.unhydrated.Has(name) {
+		return
+	}
+	s.unhydrated.Delete(name)
+	if s.unhydrated.Len() == 0 {
+		close(s.hydrationCh)
+	}
 }
 
+// Hydrated blocks until every registered source has completed a sync, returning false if the context is done first
 func (s *Store) Hydrated(ctx context.Context) bool {
+	s.RLock()
+	ch, hydrated := s.hydrationCh, s.unhydrated.Len() == 0
+	s.RUnlock()
+	if hydrated {
+		return true
+	}
 	select {
-	case <-s.hydrationCh:
+	case <-ch:
 		return true
 	case <-ctx.Done():
 		return false
@@ -17,6 +17,7 @@ limitations under the License.
 package prediction
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -63,6 +64,23 @@ var _ = Describe("Store", func() {
 		Expect(retrieved).To(Equal(pred2))
 	})
 
+	It("should be hydrated once every registered source has synced", func() {
+		store.RegisterSource("vpa")
+		store.RegisterSource("configmap")
+		store.MarkHydrated("vpa")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		Expect(store.Hydrated(ctx)).To(BeFalse())
+
+		store.MarkHydrated("configmap")
+		Expect(store.Hydrated(context.Background())).To(BeTrue())
+		// Marking a source again is a no-op
+		store.MarkHydrated("configmap")
+		Expect(store.Hydrated(context.Background())).To(BeTrue())
+	})
+	It("should be hydrated when no sources are registered", func() {
+		Expect(store.Hydrated(context.Background())).To(BeTrue())
+	})
 	It("should delete previous target when source is retargeted", func() {
 		source := types.NamespacedName{Namespace: "default", Name: "vpa-1"}
 		target1 := types.UID("uid-app1")
@@ -196,4 +214,67 @@ var _ = Describe("Store", func() {
 			Expect(ok).To(BeFalse())
 		})
 	})
+
+	Context("Apply", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			pod = &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("100m"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						}}},
+						{Name: "sidecar", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("50m"),
+						}}},
+					},
+				},
+			}
+		})
+
+		It("should override predicted resources without mutating the original pod", func() {
+			pred := &Prediction{Containers: map[string]corev1.ResourceList{
+				"app": {corev1.ResourceCPU: resource.MustParse("1")},
+			}}
+			out, changed := pred.Apply(pod)
+			Expect(changed).To(BeTrue())
+			Expect(out).ToNot(BeIdenticalTo(pod))
+			Expect(out.Spec.Containers[0].Resources.Requests.Cpu().String()).To(Equal("1"))
+			Expect(out.Spec.Containers[0].Resources.Requests.Memory().String()).To(Equal("128Mi"))
+			Expect(out.Spec.Containers[1].Resources.Requests.Cpu().String()).To(Equal("50m"))
+			Expect(pod.Spec.Containers[0].Resources.Requests.Cpu().String()).To(Equal("100m"))
+		})
+		It("should return the original pod when the prediction matches current requests", func() {
+			pred := &Prediction{Containers: map[string]corev1.ResourceList{
+				"app": {corev1.ResourceCPU: resource.MustParse("100m")},
+			}}
+			out, changed := pred.Apply(pod)
+			Expect(changed).To(BeFalse())
+			Expect(out).To(BeIdenticalTo(pod))
+		})
+		It("should ignore containers that aren't part of the pod", func() {
+			pred := &Prediction{Containers: map[string]corev1.ResourceList{
+				"missing": {corev1.ResourceCPU: resource.MustParse("4")},
+			}}
+			out, changed := pred.Apply(pod)
+			Expect(changed).To(BeFalse())
+			Expect(out).To(BeIdenticalTo(pod))
+		})
+		It("should drop allocated resources for predicted containers", func() {
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{Name: "app", AllocatedResources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}},
+				{Name: "sidecar", AllocatedResources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")}},
+			}
+			pred := &Prediction{Containers: map[string]corev1.ResourceList{
+				"app": {corev1.ResourceCPU: resource.MustParse("1")},
+			}}
+			out, changed := pred.Apply(pod)
+			Expect(changed).To(BeTrue())
+			Expect(out.Status.ContainerStatuses[0].AllocatedResources).To(BeNil())
+			Expect(out.Status.ContainerStatuses[1].AllocatedResources).ToNot(BeNil())
+			Expect(pod.Status.ContainerStatuses[0].AllocatedResources).ToNot(BeNil())
+		})
+	})
 })
@@ -265,6 +265,7 @@ func ExpectCleanedUp(ctx context.Context, c client.Client) {
 		&testv1alpha1.TestNodeClass{},
 		&v1.NodeClaim{},
 		&v1alpha1.NodeOverlay{},
+		&v1alpha1.DisruptionPlan{},
 		&resourcev1.ResourceClaim{},
 		&resourcev1.ResourceClaimTemplate{},
 	} {
@@ -1074,3 +1075,31 @@ func ExpectStateNodePoolCount(cluster *state.Cluster, npName string, r, d, pd in
 	Expect(deleting).To(Equal(d))
 	Expect(pendingdisruption).To(Equal(pd))
 }
+
+// ExpectStateImported creates the objects in a cluster state snapshot and imports the snapshot into the cluster state.
+// Objects are created from copies with their resource versions cleared, since snapshots are usually exported from
+// another cluster.
+func ExpectStateImported(ctx context.Context, c client.Client, cluster *state.Cluster, snapshot *state.Snapshot) {
+	GinkgoHelper()
+	var objects []client.Object
+	for _, n := range snapshot.Nodes {
+		if n.NodeClaim != nil {
+			objects = append(objects, n.NodeClaim.DeepCopy())
+		}
+		if n.Node != nil {
+			objects = append(objects, n.Node.DeepCopy())
+		}
+	}
+	for _, nodeClaim := range snapshot.UnlaunchedNodeClaims {
+		objects = append(objects, nodeClaim.DeepCopy())
+	}
+	for _, pod := range snapshot.Pods {
+		objects = append(objects, pod.DeepCopy())
+	}
+	for _, object := range objects {
+		object.SetResourceVersion("")
+		object.SetUID("")
+	}
+	ExpectApplied(ctx, c, objects...)
+	Expect(cluster.Import(ctx, snapshot)).To(Succeed())
+}
@@ -48,6 +48,14 @@ type OptionsFields struct {
 	MinValuesPolicy                  *options.MinValuesPolicy
 	BatchMaxDuration                 *time.Duration
 	BatchIdleDuration                *time.Duration
+	DisruptionDryRun                 *bool
+	DriftOrdering                    *options.DriftOrdering
+	DriftCheaperReplacements         *bool
+	SchedulingSolver                 *options.SchedulingSolver
+	SchedulingSolverBudget           *time.Duration
+	SchedulingDecisionTrace          *bool
+	SchedulingPreemption             *options.SchedulingPreemption
+	ReservationExpiryLeadTime        *time.Duration
 	IgnoreDRARequests                *bool
 	FeatureGates                     FeatureGates
 }
@@ -59,6 +67,9 @@ type FeatureGates struct {
 	NodeOverlay             *bool
 	StaticCapacity          *bool
 	CapacityBuffer          *bool
+	ResourcePrediction      *bool
+	DisruptionPlan          *bool
+	IncrementalScheduling   *bool
 }
 
 func Options(overrides ...OptionsFields) *options.Options {
@@ -88,6 +99,14 @@ func Options(overrides ...OptionsFields) *options.Options {
 		BatchIdleDuration:                lo.FromPtrOr(opts.BatchIdleDuration, time.Second),
 		PreferencePolicy:                 lo.FromPtrOr(opts.PreferencePolicy, options.PreferencePolicyRespect),
 		MinValuesPolicy:                  lo.FromPtrOr(opts.MinValuesPolicy, options.MinValuesPolicyStrict),
+		DisruptionDryRun:                 lo.FromPtrOr(opts.DisruptionDryRun, false),
+		DriftOrdering:                    lo.FromPtrOr(opts.DriftOrdering, options.DriftOrderingOldest),
+		DriftCheaperReplacements:         lo.FromPtrOr(opts.DriftCheaperReplacements, false),
+		SchedulingSolver:                 lo.FromPtrOr(opts.SchedulingSolver, options.SchedulingSolverGreedy),
+		SchedulingSolverBudget:           lo.FromPtrOr(opts.SchedulingSolverBudget, time.Second),
+		SchedulingDecisionTrace:          lo.FromPtrOr(opts.SchedulingDecisionTrace, false),
+		SchedulingPreemption:             lo.FromPtrOr(opts.SchedulingPreemption, options.SchedulingPreemptionDisabled),
+		ReservationExpiryLeadTime:        lo.FromPtrOr(opts.ReservationExpiryLeadTime, time.Hour),
 		IgnoreDRARequests:                lo.FromPtrOr(opts.IgnoreDRARequests, true),
 		FeatureGates: options.FeatureGates{
 			NodeRepair:              lo.FromPtrOr(opts.FeatureGates.NodeRepair, false),
@@ -96,6 +115,9 @@ func Options(overrides ...OptionsFields) *options.Options {
 			NodeOverlay:             lo.FromPtrOr(opts.FeatureGates.NodeOverlay, false),
 			StaticCapacity:          lo.FromPtrOr(opts.FeatureGates.StaticCapacity, false),
 			CapacityBuffer:          lo.FromPtrOr(opts.FeatureGates.CapacityBuffer, false),
+			ResourcePrediction:      lo.FromPtrOr(opts.FeatureGates.ResourcePrediction, false),
+			DisruptionPlan:          lo.FromPtrOr(opts.FeatureGates.DisruptionPlan, false),
+			IncrementalScheduling:   lo.FromPtrOr(opts.FeatureGates.IncrementalScheduling, false),
 		},
 	}
 }
@@ -18,11 +18,16 @@ package disruption
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -44,8 +49,38 @@ func LifetimeRemaining(clock clock.Clock, nodePool *v1.NodePool, nodeClaim *v1.N
 	return remaining
 }
 
-// EvictionCost returns the disruption cost computed for evicting the given pod.
-func EvictionCost(ctx context.Context, p *corev1.Pod) float64 {
+// NamespaceCosts maps namespace names to the default disruption cost of the pods in the namespace, which is set
+// with the karpenter.sh/disruption-cost annotation on the namespace.
+type NamespaceCosts map[string]float64
+
+// GetNamespaceCosts returns the default disruption costs of the namespaces of the given pods.
+func GetNamespaceCosts(ctx context.Context, kubeClient client.Client, pods ...*corev1.Pod) (NamespaceCosts, error) {
+	costs := NamespaceCosts{}
+	for _, name := range lo.Uniq(lo.Map(pods, func(p *corev1.Pod, _ int) string { return p.Namespace })) {
+		namespace := &corev1.Namespace{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("getting namespace, %w", err)
+		}
+		if cost, ok := disruptionCost(ctx, namespace); ok {
+			costs[name] = cost
+		}
+	}
+	return costs, nil
+}
+
+// EvictionCost returns the disruption cost computed for evicting the given pod. The karpenter.sh/disruption-cost
+// annotation on the pod, or otherwise its namespace's default, overrides the cost derived from the pod's priority
+// and deletion cost.
+func EvictionCost(ctx context.Context, p *corev1.Pod, namespaceCosts NamespaceCosts) float64 {
+	if cost, ok := disruptionCost(ctx, p); ok {
+		return lo.Clamp(cost, -10.0, 10.0)
+	}
+	if cost, ok := namespaceCosts[p.Namespace]; ok {
+		return lo.Clamp(cost, -10.0, 10.0)
+	}
 	cost := 1.0
 	podDeletionCostStr, ok := p.Annotations[corev1.PodDeletionCost]
 	if ok {
@@ -68,10 +103,28 @@ func EvictionCost(ctx context.Context, p *corev1.Pod) float64 {
 	return lo.Clamp(cost, -10.0, 10.0)
 }
 
-func ReschedulingCost(ctx context.Context, pods []*corev1.Pod) float64 {
+// disruptionCost parses the karpenter.sh/disruption-cost annotation of a pod or namespace
+func disruptionCost(ctx context.Context, obj client.Object) (float64, bool) {
+	value, ok := obj.GetAnnotations()[v1.DisruptionCostAnnotationKey]
+	if !ok {
+		return 0, false
+	}
+	cost, err := strconv.ParseFloat(value, 64)
+	if err == nil && (math.IsNaN(cost) || math.IsInf(cost, 0)) {
+		err = fmt.Errorf("disruption cost must be finite")
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed parsing disruption cost",
+			"annotation", v1.DisruptionCostAnnotationKey, "value", value, "object", client.ObjectKeyFromObject(obj))
+		return 0, false
+	}
+	return cost, true
+}
+
+func ReschedulingCost(ctx context.Context, pods []*corev1.Pod, namespaceCosts NamespaceCosts) float64 {
 	cost := 0.0
 	for _, p := range pods {
-		cost += EvictionCost(ctx, p)
+		cost += EvictionCost(ctx, p, namespaceCosts)
 	}
 	return cost
 }
@@ -91,3 +144,138 @@ func IsUnderConsolidateAfter(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim, c c
 	// Consider a node under the effect of consolidateAfter by looking at the lastPodEvent status field on the nodeclaim.
 	return c.Since(timeToCheck) < lo.FromPtr(nodePool.Spec.Disruption.ConsolidateAfter.Duration)
 }
+
+// ForcefulBudgetPollPeriod is how often a NodeClaim that is waiting for a forceful disruption budget checks it again
+const ForcefulBudgetPollPeriod = time.Minute
+
+// ForcefulBudgets reserves the forceful disruption budgets of NodePools for NodeClaims that are being disrupted. The
+// controllers that forcefully disrupt NodeClaims check budgets against the informer cache, which doesn't reflect
+// deletions that are still in flight, so concurrent reconciles could otherwise disrupt more NodeClaims than a budget
+// allows. A single ForcefulBudgets should be shared by all controllers that forcefully disrupt NodeClaims.
+type ForcefulBudgets struct {
+	mu sync.Mutex
+	// reservations maps the names of NodeClaims that were allowed to be disrupted to their NodePool, until the
+	// informer cache reflects their deletion
+	reservations map[string]string
+}
+
+func NewForcefulBudgets() *ForcefulBudgets {
+	return &ForcefulBudgets{reservations: map[string]string{}}
+}
+
+// Reserve returns how long a NodeClaim that became eligible for a forceful disruption at eligibleSince should wait for
+// its NodePool's budget before it's disrupted. This returns zero if the NodeClaim can be disrupted now, either because
+// the budget allows it or because the NodeClaim has waited for the NodePool's forcefulBudgetMaxWait. Only budgets that
+// explicitly list the reason apply, so NodeClaims are disrupted immediately by default.
+// When this returns zero, the NodeClaim counts towards its NodePool's budgets until the informer cache reflects its
+// deletion. Callers must Release the NodeClaim if they don't delete it.
+func (b *ForcefulBudgets) Reserve(ctx context.Context, kubeClient client.Client, clk clock.Clock, nodeClaim *v1.NodeClaim, reason v1.DisruptionReason, eligibleSince time.Time) (time.Duration, error) {
+	nodePoolName, ok := nodeClaim.Labels[v1.NodePoolLabelKey]
+	if !ok || !nodeClaim.DeletionTimestamp.IsZero() {
+		return 0, nil
+	}
+	nodePool := &v1.NodePool{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Name: nodePoolName}, nodePool); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	// NodeClaims are only reserved for NodePools with forceful budgets, since the reservations are only read by them
+	if !lo.ContainsBy(nodePool.Spec.Disruption.Budgets, func(budget v1.Budget) bool {
+		return lo.ContainsBy(budget.Reasons, func(r v1.DisruptionReason) bool { return r.IsForceful() })
+	}) {
+		return 0, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	nodeClaims := &v1.NodeClaimList{}
+	if err := kubeClient.List(ctx, nodeClaims, client.MatchingLabels{v1.NodePoolLabelKey: nodePoolName}); err != nil {
+		return 0, fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	// Reservations are released once the informer cache reflects the NodeClaim's deletion
+	existing := sets.New[string]()
+	for i := range nodeClaims.Items {
+		if nodeClaims.Items[i].DeletionTimestamp.IsZero() {
+			existing.Insert(nodeClaims.Items[i].Name)
+		}
+	}
+	for name, np := range b.reservations {
+		if np == nodePoolName && !existing.Has(name) {
+			delete(b.reservations, name)
+		}
+	}
+	if _, ok := b.reservations[nodeClaim.Name]; ok {
+		return 0, nil
+	}
+	if !lo.ContainsBy(nodePool.Spec.Disruption.Budgets, func(budget v1.Budget) bool { return lo.Contains(budget.Reasons, reason) }) {
+		b.reservations[nodeClaim.Name] = nodePoolName
+		return 0, nil
+	}
+	delay := ForcefulBudgetPollPeriod
+	if maxWait := nodePool.Spec.Disruption.ForcefulBudgetMaxWait; maxWait != nil {
+		remaining := maxWait.Duration - clk.Since(eligibleSince)
+		if remaining <= 0 {
+			b.reservations[nodeClaim.Name] = nodePoolName
+			return 0, nil
+		}
+		delay = min(delay, remaining)
+	}
+	numNodes, disrupting := 0, 0
+	for i := range nodeClaims.Items {
+		// Similar to the disruption controller, only initialized NodeClaims that aren't already terminating count towards the budget
+		if !nodeClaims.Items[i].StatusConditions().IsTrue(v1.ConditionTypeInitialized) ||
+			nodeClaims.Items[i].StatusConditions().IsTrue(v1.ConditionTypeInstanceTerminating) {
+			continue
+		}
+		numNodes++
+		if _, reserved := b.reservations[nodeClaims.Items[i].Name]; reserved || !nodeClaims.Items[i].DeletionTimestamp.IsZero() {
+			disrupting++
+		}
+	}
+	if disrupting < nodePool.MustGetAllowedDisruptions(clk, numNodes, reason) {
+		b.reservations[nodeClaim.Name] = nodePoolName
+		return 0, nil
+	}
+	return delay, nil
+}
+
+// Release releases the budget reserved for a NodeClaim that wasn't deleted
+func (b *ForcefulBudgets) Release(nodeClaim *v1.NodeClaim) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.reservations, nodeClaim.Name)
+}
+
+// MaintenanceWindowDelay returns how long a NodeClaim should wait for its NodePool's maintenance window before it's
+// forcefully disrupted. This returns zero if the NodePool doesn't have maintenance windows, or if a window is open.
+// Forceful disruption doesn't launch replacements, so, like delete commands of the disruption controller, it only
+// needs the window to be open when it starts.
+func MaintenanceWindowDelay(ctx context.Context, kubeClient client.Client, clk clock.Clock, nodeClaim *v1.NodeClaim) (time.Duration, error) {
+	nodePoolName, ok := nodeClaim.Labels[v1.NodePoolLabelKey]
+	if !ok {
+		return 0, nil
+	}
+	nodePool := &v1.NodePool{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Name: nodePoolName}, nodePool); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	windows := nodePool.Spec.Disruption.MaintenanceWindows
+	if windows == nil {
+		return 0, nil
+	}
+	remaining, err := nodePool.MaintenanceWindowRemaining(clk)
+	if err != nil {
+		return 0, fmt.Errorf("evaluating maintenance windows, %w", err)
+	}
+	if remaining > 0 {
+		return 0, nil
+	}
+	next, ok, err := windows.NextOpen(clk.Now())
+	if err != nil {
+		return 0, fmt.Errorf("evaluating maintenance windows, %w", err)
+	}
+	// If no window opens within the lookahead, check again later in case the NodePool is updated
+	if !ok {
+		return time.Hour, nil
+	}
+	return min(next.Sub(clk.Now()), time.Hour), nil
+}
@@ -92,9 +92,7 @@ func (l Limits) isEvictable(pod *v1.Pod, clk clock.Clock, recorder events.Record
 		return []client.ObjectKey{}, true
 	}
 
-	matchingPDBs := lo.Filter(l, func(pdb *pdbItem, _ int) bool {
-		return pdb.key.Namespace == pod.Namespace && pdb.selector.Matches(labels.Set(pod.Labels))
-	})
+	matchingPDBs := l.matching(pod)
 
 	// Regardless of whether the PDBs allow disruptions, Kubernetes doesn't support multiple PDBs on a single pod:
 	// https://github.com/kubernetes/kubernetes/blob/84cacae7046df93c1f6f8ea97c912d948e1ad06a/pkg/registry/core/pod/storage/eviction.go#L226
@@ -129,6 +127,65 @@ func (l Limits) isEvictable(pod *v1.Pod, clk clock.Clock, recorder events.Record
 	return []client.ObjectKey{}, true
 }
 
+// CanPreemptPods returns true if the pods can be preempted together without exceeding the disruptions allowed by the
+// PDBs that control them. Unlike CanEvictPods, each pod counts against its PDB since the pods are deleted at once, and
+// pods that Karpenter doesn't evict, such as pods with the karpenter.sh/do-not-disrupt annotation, aren't exempt since
+// the kube-scheduler preempts them regardless.
+func (l Limits) CanPreemptPods(pods []*v1.Pod) ([]client.ObjectKey, bool) {
+	disruptions := map[*pdbItem]int32{}
+	for _, pod := range pods {
+		matchingPDBs := l.matching(pod)
+		if len(matchingPDBs) > 1 {
+			return lo.Map(matchingPDBs, func(pdb *pdbItem, _ int) client.ObjectKey {
+				return pdb.key
+			}), false
+		}
+		for _, pdb := range matchingPDBs {
+			if pdb.canAlwaysEvictUnhealthyPods && isUnhealthy(pod) {
+				continue
+			}
+			disruptions[pdb]++
+			if disruptions[pdb] > pdb.disruptionsAllowed {
+				return []client.ObjectKey{pdb.key}, false
+			}
+		}
+	}
+	return []client.ObjectKey{}, true
+}
+
+// Consume records the disruption of the pods against the PDBs that control them, so that subsequent evaluations of the
+// limits account for the pods' disruption
+func (l Limits) Consume(pods []*v1.Pod) {
+	for _, pod := range pods {
+		for _, pdb := range l.matching(pod) {
+			if pdb.canAlwaysEvictUnhealthyPods && isUnhealthy(pod) {
+				continue
+			}
+			pdb.disruptionsAllowed = max(pdb.disruptionsAllowed-1, 0)
+		}
+	}
+}
+
+// DeepCopy returns a copy of the limits whose disruptions can be consumed without affecting the original limits
+func (l Limits) DeepCopy() Limits {
+	return lo.Map(l, func(pdb *pdbItem, _ int) *pdbItem {
+		copied := *pdb
+		return &copied
+	})
+}
+
+func (l Limits) matching(pod *v1.Pod) []*pdbItem {
+	return lo.Filter(l, func(pdb *pdbItem, _ int) bool {
+		return pdb.key.Namespace == pod.Namespace && pdb.selector.Matches(labels.Set(pod.Labels))
+	})
+}
+
+func isUnhealthy(pod *v1.Pod) bool {
+	return lo.ContainsBy(pod.Status.Conditions, func(c v1.PodCondition) bool {
+		return c.Type == v1.PodReady && c.Status == v1.ConditionFalse
+	})
+}
+
 // IsCurrentlyReschedulable checks if a Karpenter should consider this pod when re-scheduling to new capacity by ensuring that the pod:
 // - Is reschedulable as per the checks in IsReschedulable(...)
 // - Does not have an active "karpenter.sh/do-not-disrupt" annotation (https://karpenter.sh/docs/concepts/disruption/#pod-level-controls)
@@ -333,3 +333,58 @@ var _ = Describe("IsCurrentlyReschedulable", func() {
 		Expect(limits.IsCurrentlyReschedulable(pod, env.Clock, nil)).To(BeFalse())
 	})
 })
+
+var _ = Describe("CanPreemptPods", func() {
+	var podDisruptionBudget *policyv1.PodDisruptionBudget
+	var pods []*v1.Pod
+	BeforeEach(func() {
+		podDisruptionBudget = test.PodDisruptionBudget(test.PDBOptions{
+			Labels:         podLabels,
+			MaxUnavailable: new(intstr.FromInt32(1)),
+			Status:         &policyv1.PodDisruptionBudgetStatus{ObservedGeneration: 1, DisruptionsAllowed: 1},
+		})
+		pods = test.Pods(2, test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: podLabels,
+			},
+		})
+		ExpectApplied(ctx, env.Client, podDisruptionBudget)
+		for _, p := range pods {
+			ExpectApplied(ctx, env.Client, p)
+		}
+	})
+	It("can preempt pods within the disruptions allowed by their PDB", func() {
+		limits, err := pdb.NewLimits(ctx, env.Client)
+		Expect(err).NotTo(HaveOccurred())
+
+		violatingPDBs, canPreempt := limits.CanPreemptPods(pods[:1])
+		Expect(violatingPDBs).To(HaveLen(0))
+		Expect(canPreempt).To(BeTrue())
+	})
+	It("can't preempt more pods together than their PDB allows", func() {
+		limits, err := pdb.NewLimits(ctx, env.Client)
+		Expect(err).NotTo(HaveOccurred())
+
+		violatingPDBs, canPreempt := limits.CanPreemptPods(pods)
+		Expect(violatingPDBs).To(ConsistOf(client.ObjectKeyFromObject(podDisruptionBudget)))
+		Expect(canPreempt).To(BeFalse())
+	})
+	It("can't preempt pods after their PDB's disruptions have been consumed", func() {
+		limits, err := pdb.NewLimits(ctx, env.Client)
+		Expect(err).NotTo(HaveOccurred())
+
+		limits.Consume(pods[:1])
+		violatingPDBs, canPreempt := limits.CanPreemptPods(pods[1:])
+		Expect(violatingPDBs).To(ConsistOf(client.ObjectKeyFromObject(podDisruptionBudget)))
+		Expect(canPreempt).To(BeFalse())
+	})
+	It("doesn't exempt pods with the do-not-disrupt annotation", func() {
+		pods[1].Annotations = map[string]string{karpenterv1.DoNotDisruptAnnotationKey: "true"}
+		ExpectApplied(ctx, env.Client, pods[1])
+		limits, err := pdb.NewLimits(ctx, env.Client)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, canPreempt := limits.CanPreemptPods(pods)
+		Expect(canPreempt).To(BeFalse())
+	})
+})
@@ -18,10 +18,13 @@ package pod
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/clock"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
@@ -29,6 +32,9 @@ import (
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 )
 
+// CoschedulingPodGroupLabelKey is the label that the coscheduling scheduler plugin uses to assign a pod to a PodGroup
+const CoschedulingPodGroupLabelKey = "scheduling.x-k8s.io/pod-group"
+
 // IsActive checks if Karpenter should consider this pod as running by ensuring that the pod:
 // - Isn't a terminal pod (Failed or Succeeded)
 // - Isn't actively terminating
@@ -288,3 +294,48 @@ func HasDRARequirements(pod *corev1.Pod) bool {
 	}
 	return false
 }
+
+// PodGroup returns the group of pods that the pod must schedule together with, identified by the karpenter.sh/pod-group
+// label or the coscheduling plugin's PodGroup label. It returns false if the pod isn't part of a group.
+func PodGroup(pod *corev1.Pod) (types.NamespacedName, bool) {
+	key, ok := podGroupLabelKey(pod)
+	if !ok {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: pod.Namespace, Name: pod.Labels[key]}, true
+}
+
+// PodGroupSize returns the number of members in the pod's group from the karpenter.sh/pod-group-size annotation. It
+// returns false if the annotation isn't set to a positive number.
+func PodGroupSize(pod *corev1.Pod) (int, bool) {
+	size, err := strconv.Atoi(pod.Annotations[v1.PodGroupSizeAnnotationKey])
+	if err != nil || size <= 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+// IsCoschedulingPodGroup returns true if the pod's group is identified by the coscheduling plugin's PodGroup label
+func IsCoschedulingPodGroup(pod *corev1.Pod) bool {
+	key, ok := podGroupLabelKey(pod)
+	return ok && key == CoschedulingPodGroupLabelKey
+}
+
+// PodGroupSelector returns a label selector that matches the members of the pod's group in its namespace. It returns
+// false if the pod isn't part of a group.
+func PodGroupSelector(pod *corev1.Pod) (*metav1.LabelSelector, bool) {
+	key, ok := podGroupLabelKey(pod)
+	if !ok {
+		return nil, false
+	}
+	return &metav1.LabelSelector{MatchLabels: map[string]string{key: pod.Labels[key]}}, true
+}
+
+func podGroupLabelKey(pod *corev1.Pod) (string, bool) {
+	for _, key := range []string{v1.PodGroupLabelKey, CoschedulingPodGroupLabelKey} {
+		if pod.Labels[key] != "" {
+			return key, true
+		}
+	}
+	return "", false
+}